@@ -68,13 +68,25 @@ func main() {
 
 		flags := cmd.Flags()
 		flags.StringVar(&who, "who", "", "Who are we addressing?")
+		completeWho := func(_ *cobra.Command, _ []string, _ string) ([]string, manager.CompletionDirective) {
+			return []string{"World", "Moon"}, manager.CompletionDirectiveNoFileComp
+		}
+		if err := plugin.RegisterFlagCompletionFunc(cmd, "who", completeWho); err != nil {
+			panic(err)
+		}
 
 		cmd.AddCommand(goodbye, apiversion, exitStatus2)
+
+		plugin.RegisterHookFunc(func(event manager.HookEvent) error {
+			fmt.Fprintf(dockerCli.Out(), "Hello from the %s hook: %q exited with status %d\n", event.Hook, event.Command, event.ExitCode)
+			return nil
+		})
 		return cmd
 	},
 		manager.Metadata{
 			SchemaVersion: "0.1.0",
 			Vendor:        "Docker Inc.",
 			Version:       "testing",
+			Hooks:         []string{"login"},
 		})
 }
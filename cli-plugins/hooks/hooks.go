@@ -0,0 +1,7 @@
+// Package hooks holds the definitions shared by the builtin commands and the
+// CLI plugins hooking them, without depending on the plugin manager.
+package hooks
+
+// FlagAnnotationSensitive is the annotation of the flags of builtin commands
+// whose value is never passed to plugin hooks, such as passwords.
+const FlagAnnotationSensitive = "sensitive"
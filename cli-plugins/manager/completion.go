@@ -0,0 +1,134 @@
+package manager
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/docker/cli/cli/command"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// CompletionSubcommandName is the name of the hidden subcommand which
+// is supported by every plugin and returns the shell completions for
+// the plugin's command line. It is invoked as
+//
+//	docker-NAME __complete NAME [ARG...] TOCOMPLETE
+//
+// where ARG are the arguments already on the command line and
+// TOCOMPLETE is the (possibly empty) word being completed. The main CLI
+// exposes a hidden command of the same name, which the shell completion
+// scripts call and which delegates to the plugin.
+const CompletionSubcommandName = "__complete"
+
+// CompletionDirective is a bit map of hints telling the shell how to handle
+// the completions returned by a plugin.
+type CompletionDirective int
+
+const (
+	// CompletionDirectiveDefault lets the shell apply its default
+	// behaviour, which includes completing file names when there are
+	// no completions.
+	CompletionDirectiveDefault CompletionDirective = 0
+	// CompletionDirectiveError indicates that an error occurred and
+	// that the completions must be ignored.
+	CompletionDirectiveError CompletionDirective = 1
+	// CompletionDirectiveNoSpace tells the shell not to add a space
+	// after the completion, for example to complete a flag value
+	// after `--flag=`.
+	CompletionDirectiveNoSpace CompletionDirective = 2
+	// CompletionDirectiveNoFileComp tells the shell not to complete
+	// file names when there are no completions.
+	CompletionDirectiveNoFileComp CompletionDirective = 4
+)
+
+// Completion is the result of a completion request. See
+// docs/extend/cli_plugins.md for the canonical description of the format.
+type Completion struct {
+	// Values are the candidates for the word being completed. A value
+	// may be followed by a tab and a description of the value.
+	Values []string
+	// Directive tells the shell how to handle the values.
+	Directive CompletionDirective
+}
+
+// WriteCompletion writes a completion in the format of the completion
+// protocol: one value per line, followed by a line holding the directive
+// prefixed with a colon.
+func WriteCompletion(w io.Writer, c Completion) error {
+	for _, v := range c.Values {
+		// A value can't span several lines
+		if i := strings.IndexByte(v, '\n'); i >= 0 {
+			v = v[:i]
+		}
+		if _, err := fmt.Fprintln(w, v); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, ":%d\n", c.Directive)
+	return err
+}
+
+// ParseCompletion parses the output of the completion subcommand of a
+// plugin.
+func ParseCompletion(out []byte) (Completion, error) {
+	var (
+		c     Completion
+		lines []string
+	)
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return c, err
+	}
+	if len(lines) == 0 || !strings.HasPrefix(lines[len(lines)-1], ":") {
+		return c, errors.New("invalid completion: missing directive")
+	}
+	directive, err := strconv.Atoi(strings.TrimPrefix(lines[len(lines)-1], ":"))
+	if err != nil {
+		return c, errors.Wrap(err, "invalid completion directive")
+	}
+	c.Directive = CompletionDirective(directive)
+	for _, l := range lines[:len(lines)-1] {
+		if l != "" {
+			c.Values = append(c.Values, l)
+		}
+	}
+	return c, nil
+}
+
+// PluginComplete runs the completion subcommand of the named plugin and
+// returns its completions for args, the last of which is the word being
+// completed. The rootcmd argument is referenced to determine the set of
+// builtin commands in order to detect conficts. The error returned satisfies
+// the IsNotFound() predicate if no plugin was found or if the first
+// candidate plugin was invalid somehow.
+func PluginComplete(dockerCli command.Cli, name string, args []string, rootcmd *cobra.Command) (Completion, error) {
+	if len(args) == 0 {
+		return Completion{}, errors.New("nothing to complete")
+	}
+	cmd, err := pluginCommand(dockerCli, name, rootcmd, append([]string{CompletionSubcommandName, name}, args...))
+	if err != nil {
+		return Completion{}, err
+	}
+	// The completion subcommand must not interact with the user: stdin
+	// and stderr are left to /dev/null.
+	cmd.Stdin = nil
+	cmd.Stdout = nil
+	cmd.Stderr = nil
+	out, err := cmd.Output()
+	if err != nil {
+		return Completion{}, wrapAsPluginError(err, "failed to fetch completions")
+	}
+	c, err := ParseCompletion(out)
+	if err != nil {
+		return Completion{}, wrapAsPluginError(err, "failed to fetch completions")
+	}
+	return c, nil
+}
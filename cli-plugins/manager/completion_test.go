@@ -0,0 +1,45 @@
+package manager
+
+import (
+	"bytes"
+	"testing"
+
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+func TestWriteParseCompletion(t *testing.T) {
+	c := Completion{
+		Values:    []string{"goodbye\tSay Goodbye", "--who\tWho are we addressing?\nSecond line"},
+		Directive: CompletionDirectiveNoSpace | CompletionDirectiveNoFileComp,
+	}
+	var buf bytes.Buffer
+	assert.NilError(t, WriteCompletion(&buf, c))
+	assert.Check(t, is.Equal(buf.String(), "goodbye\tSay Goodbye\n--who\tWho are we addressing?\n:6\n"))
+
+	actual, err := ParseCompletion(buf.Bytes())
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(actual, Completion{
+		Values:    []string{"goodbye\tSay Goodbye", "--who\tWho are we addressing?"},
+		Directive: CompletionDirectiveNoSpace | CompletionDirectiveNoFileComp,
+	}))
+}
+
+func TestParseCompletionEmpty(t *testing.T) {
+	actual, err := ParseCompletion([]byte(":0\n"))
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(actual, Completion{}))
+}
+
+func TestParseCompletionInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		out, err string
+	}{
+		{out: "", err: "invalid completion: missing directive"},
+		{out: "goodbye\n", err: "invalid completion: missing directive"},
+		{out: "goodbye\n:nospace\n", err: "invalid completion directive"},
+	} {
+		_, err := ParseCompletion([]byte(tc.out))
+		assert.Check(t, is.ErrorContains(err, tc.err), tc.out)
+	}
+}
@@ -1,6 +1,8 @@
 package manager
 
 import (
+	"fmt"
+
 	"github.com/pkg/errors"
 )
 
@@ -41,3 +43,53 @@ func wrapAsPluginError(err error, msg string) error {
 func NewPluginError(msg string, args ...interface{}) error {
 	return &pluginError{cause: errors.Errorf(msg, args...)}
 }
+
+// errPluginIncompatible is the cause of the pluginError of a plugin which
+// requirements are not met by the CLI or the daemon.
+type errPluginIncompatible string
+
+func (e errPluginIncompatible) Incompatible() {}
+
+func (e errPluginIncompatible) Error() string {
+	return string(e)
+}
+
+type incompatible interface{ Incompatible() }
+
+// IsIncompatible is true if the given error is due to a plugin which
+// requirements are not met by the CLI or the daemon.
+func IsIncompatible(err error) bool {
+	_, ok := errors.Cause(err).(incompatible)
+	return ok
+}
+
+// NewIncompatiblePluginError creates a new pluginError for an incompatible plugin,
+// analogous to errors.Errorf.
+func NewIncompatiblePluginError(msg string, args ...interface{}) error {
+	return &pluginError{cause: errPluginIncompatible(fmt.Sprintf(msg, args...))}
+}
+
+// errPluginUnverified is the cause of the pluginError of a plugin which
+// binary could not be verified.
+type errPluginUnverified string
+
+func (e errPluginUnverified) Unverified() {}
+
+func (e errPluginUnverified) Error() string {
+	return string(e)
+}
+
+type unverified interface{ Unverified() }
+
+// IsUnverified is true if the given error is due to a plugin which binary
+// matches neither a trusted digest nor a trusted signature.
+func IsUnverified(err error) bool {
+	_, ok := errors.Cause(err).(unverified)
+	return ok
+}
+
+// NewUnverifiedPluginError creates a new pluginError for an unverified
+// plugin, analogous to errors.Errorf.
+func NewUnverifiedPluginError(msg string, args ...interface{}) error {
+	return &pluginError{cause: errPluginUnverified(fmt.Sprintf(msg, args...))}
+}
@@ -0,0 +1,151 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/cli/cli-plugins/hooks"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// HookSubcommandName is the name of the plugin subcommand which is
+// invoked after the builtin commands the plugin hooks, see
+// Metadata.Hooks. It takes a single argument, the HookEvent encoded in
+// JSON.
+const HookSubcommandName = "docker-cli-plugin-hook"
+
+// hookTimeout is the maximum duration of a plugin hook. The plugin is killed
+// if it doesn't exit in time.
+var hookTimeout = 5 * time.Second
+
+// hookedCommands maps the path of the builtin commands which plugins can
+// hook, without the name of the root command, to the name of the hook.
+var hookedCommands = map[string]string{
+	"build":       "build",
+	"image build": "build",
+	"push":        "push",
+	"image push":  "push",
+	"login":       "login",
+}
+
+// HookEvent describes the invocation of a builtin command, passed to the
+// plugins which hook it.
+type HookEvent struct {
+	// Hook is the name of the hook, as declared in the plugin metadata.
+	Hook string
+	// Command is the path of the builtin command which was invoked,
+	// without the name of the root command, e.g. "image push".
+	Command string
+	// Args are the positional arguments of the command.
+	Args []string `json:",omitempty"`
+	// Flags are the flags set on the command line, with their value.
+	// Sensitive flags, such as passwords, are omitted.
+	Flags map[string]string `json:",omitempty"`
+	// ExitCode is the exit status of the command.
+	ExitCode int
+}
+
+// RunHooks invokes the plugins which hook cmd, the builtin command which was
+// executed, with the exit status of the command. The rootcmd argument is
+// referenced to determine the set of builtin commands in order to detect
+// conficts. Each plugin is killed if it doesn't exit in time. Failing hooks
+// are reported as warnings, and don't affect the exit status of the CLI.
+func RunHooks(dockerCli command.Cli, rootcmd, cmd *cobra.Command, exitCode int) {
+	if cmd == nil || dockerCli.ConfigFile() == nil || hooksDisabled(dockerCli.ConfigFile()) {
+		return
+	}
+	commandPath := strings.TrimPrefix(cmd.CommandPath(), rootcmd.Name()+" ")
+	hook, ok := hookedCommands[commandPath]
+	if !ok {
+		return
+	}
+	plugins, err := ListPlugins(dockerCli, rootcmd)
+	if err != nil {
+		logrus.Debugf("failed to list plugins for the %s hook: %v", hook, err)
+		return
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+
+	event, err := json.Marshal(newHookEvent(hook, commandPath, cmd, exitCode))
+	if err != nil {
+		logrus.Debugf("failed to encode the %s hook event: %v", hook, err)
+		return
+	}
+	for _, p := range plugins {
+		if p.Err != nil || !p.hooks(hook) {
+			continue
+		}
+		if err := runHook(p, event); err != nil {
+			fmt.Fprintf(dockerCli.Err(), "Warning: %s hook of CLI plugin %s failed: %v\n", hook, p.Name, err)
+		}
+	}
+}
+
+// hooksDisabled returns whether the user opted out of plugin hooks.
+func hooksDisabled(configFile *configfile.ConfigFile) bool {
+	return configFile.CLIPluginsHooks != nil && !*configFile.CLIPluginsHooks
+}
+
+func newHookEvent(hook, commandPath string, cmd *cobra.Command, exitCode int) HookEvent {
+	event := HookEvent{
+		Hook:     hook,
+		Command:  commandPath,
+		Args:     cmd.Flags().Args(),
+		ExitCode: exitCode,
+	}
+	// Global flags are not passed, only those of the command
+	local := cmd.LocalFlags()
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if local.Lookup(f.Name) == nil {
+			return
+		}
+		if _, sensitive := f.Annotations[hooks.FlagAnnotationSensitive]; sensitive {
+			return
+		}
+		if event.Flags == nil {
+			event.Flags = make(map[string]string)
+		}
+		event.Flags[f.Name] = f.Value.String()
+	})
+	return event
+}
+
+func (p Plugin) hooks(hook string) bool {
+	for _, h := range p.Hooks {
+		if h == hook {
+			return true
+		}
+	}
+	return false
+}
+
+func runHook(p Plugin, event []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, p.Path, HookSubcommandName, string(event))
+	// The output of hooks goes to stderr, so as not to interfere with
+	// the output of the builtin command. os.Stderr is used rather than
+	// dockerCli.Err() so that the hook can't outlive the timeout by
+	// holding the output pipe open.
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), ReexecEnvvar+"="+os.Args[0])
+
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return errors.Errorf("timed out after %s", hookTimeout)
+	}
+	return err
+}
@@ -0,0 +1,82 @@
+package manager
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/docker/cli/cli-plugins/hooks"
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/cli/internal/test"
+	"github.com/spf13/cobra"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+	"gotest.tools/fs"
+	"gotest.tools/skip"
+)
+
+func newTestRootCommand() (*cobra.Command, *cobra.Command) {
+	root := &cobra.Command{Use: "docker"}
+	login := &cobra.Command{Use: "login [OPTIONS] [SERVER]", Run: func(*cobra.Command, []string) {}}
+	login.Flags().StringP("username", "u", "", "Username")
+	login.Flags().StringP("password", "p", "", "Password")
+	login.Flags().SetAnnotation("password", hooks.FlagAnnotationSensitive, nil)
+	root.AddCommand(login)
+	return root, login
+}
+
+func TestNewHookEvent(t *testing.T) {
+	_, login := newTestRootCommand()
+	assert.NilError(t, login.ParseFlags([]string{"-u", "foo", "--password", "secret", "registry.example.com"}))
+
+	event := newHookEvent("login", "login", login, 1)
+	assert.Check(t, is.DeepEqual(event, HookEvent{
+		Hook:     "login",
+		Command:  "login",
+		Args:     []string{"registry.example.com"},
+		Flags:    map[string]string{"username": "foo"},
+		ExitCode: 1,
+	}))
+}
+
+func TestRunHooksNotHooked(t *testing.T) {
+	root, _ := newTestRootCommand()
+	version := &cobra.Command{Use: "version"}
+	root.AddCommand(version)
+
+	// No plugin is listed, since no configuration is available
+	cli := test.NewFakeCli(nil)
+	RunHooks(cli, root, version, 0)
+	RunHooks(cli, root, nil, 1)
+	assert.Check(t, is.Equal(cli.ErrBuffer().String(), ""))
+}
+
+func TestHooksDisabled(t *testing.T) {
+	enabled, disabled := true, false
+	assert.Check(t, !hooksDisabled(&configfile.ConfigFile{}))
+	assert.Check(t, !hooksDisabled(&configfile.ConfigFile{CLIPluginsHooks: &enabled}))
+	assert.Check(t, hooksDisabled(&configfile.ConfigFile{CLIPluginsHooks: &disabled}))
+}
+
+func TestRunHook(t *testing.T) {
+	skip.If(t, runtime.GOOS == "windows", "hook plugins are shell scripts")
+
+	dir := fs.NewDir(t, t.Name(),
+		fs.WithFile("docker-good", "#!/bin/sh\n[ \"$1\" = docker-cli-plugin-hook ] && [ \"$2\" = '{\"Hook\":\"login\"}' ]\n", fs.WithMode(0755)),
+		fs.WithFile("docker-slow", "#!/bin/sh\nexec sleep 10\n", fs.WithMode(0755)),
+	)
+	defer dir.Remove()
+	defer func(timeout time.Duration) { hookTimeout = timeout }(hookTimeout)
+	hookTimeout = 100 * time.Millisecond
+
+	event := []byte(`{"Hook":"login"}`)
+	assert.NilError(t, runHook(Plugin{Name: "good", Path: dir.Join("docker-good")}, event))
+	assert.Check(t, is.ErrorContains(runHook(Plugin{Name: "good", Path: dir.Join("docker-good")}, []byte("{}")), "exit status 1"))
+	assert.Check(t, is.Error(runHook(Plugin{Name: "slow", Path: dir.Join("docker-slow")}, event), "timed out after 100ms"))
+}
+
+func TestPluginHooks(t *testing.T) {
+	p := Plugin{Metadata: Metadata{Hooks: []string{"build", "push"}}}
+	assert.Check(t, p.hooks("push"))
+	assert.Check(t, !p.hooks("login"))
+}
@@ -0,0 +1,175 @@
+package manager
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/cli/cli/config"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ConfigMediaType is the media type of the config of a plugin
+	// packaged as an artifact in a registry. The config holds the
+	// plugin metadata, as returned by the metadata subcommand.
+	ConfigMediaType = "application/vnd.docker.cli-plugin.config.v1+json"
+
+	// BinaryMediaType is the media type of the single layer of a
+	// plugin packaged as an artifact in a registry. The layer is the
+	// plugin binary, for the platform of the manifest.
+	BinaryMediaType = "application/vnd.docker.cli-plugin.binary.v1"
+
+	// installRecordsDir is the directory of the user plugin directory
+	// where install records are stored. Directories are not plugin
+	// candidates.
+	installRecordsDir = ".installed"
+)
+
+// InstallRecord records where a plugin installed from a registry comes
+// from, so that it can be updated.
+type InstallRecord struct {
+	// Name is the name of the plugin.
+	Name string
+	// Source is the reference the plugin was installed from.
+	Source string
+	// Digest is the digest of the manifest, or manifest list, the
+	// plugin was installed from.
+	Digest digest.Digest
+	// Version is the version of the installed plugin.
+	Version string `json:",omitempty"`
+}
+
+// UserPluginDir returns the directory where plugins are installed for the
+// current user.
+func UserPluginDir() string {
+	return config.Path("cli-plugins")
+}
+
+// UserPluginPath returns the path of a plugin installed in the user plugin
+// directory.
+func UserPluginPath(name string) string {
+	return filepath.Join(UserPluginDir(), addExeSuffix(NamePrefix+name))
+}
+
+func installRecordPath(name string) string {
+	return filepath.Join(UserPluginDir(), installRecordsDir, name+".json")
+}
+
+// InstallPlugin installs the binary of the plugin in the user plugin
+// directory, replacing any plugin with the same name, and saves its install
+// record. It returns the path of the installed plugin.
+func InstallPlugin(record InstallRecord, binary []byte) (string, error) {
+	if !pluginNameRe.MatchString(record.Name) {
+		return "", errors.Errorf("invalid plugin name %q: must match %q", record.Name, pluginNameRe.String())
+	}
+	dir := UserPluginDir()
+	if err := os.MkdirAll(filepath.Join(dir, installRecordsDir), 0755); err != nil {
+		return "", err
+	}
+
+	// The binary is written to a temporary file first, so that a running
+	// plugin is not modified, and an interrupted install doesn't leave a
+	// truncated plugin behind.
+	tmp, err := ioutil.TempFile(dir, "."+NamePrefix+record.Name)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(binary)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to write plugin %s", record.Name)
+	}
+	if err := os.Chmod(tmp.Name(), 0755); err != nil {
+		return "", err
+	}
+
+	path := UserPluginPath(record.Name)
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", errors.Wrapf(err, "failed to install plugin %s", record.Name)
+	}
+
+	data, err := json.MarshalIndent(record, "", "\t")
+	if err != nil {
+		return "", err
+	}
+	return path, ioutil.WriteFile(installRecordPath(record.Name), data, 0644)
+}
+
+// UninstallPlugin removes a plugin from the user plugin directory, along
+// with its install record. The error returned satisfies the IsNotFound()
+// predicate if the plugin is not installed in the user plugin directory.
+func UninstallPlugin(name string) error {
+	if !pluginNameRe.MatchString(name) {
+		return errPluginNotFound(name)
+	}
+	if err := os.Remove(UserPluginPath(name)); err != nil {
+		if os.IsNotExist(err) {
+			return errPluginNotFound(name)
+		}
+		return err
+	}
+	// The detached signature of the binary, if any, is stale.
+	if err := os.Remove(UserPluginPath(name) + SignatureSuffix); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Remove(installRecordPath(name)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// GetInstallRecord returns the install record of a plugin. The error returned
+// satisfies the IsNotFound() predicate if the plugin was not installed from a
+// registry.
+func GetInstallRecord(name string) (InstallRecord, error) {
+	var record InstallRecord
+	if !pluginNameRe.MatchString(name) {
+		return record, errPluginNotFound(name)
+	}
+	data, err := ioutil.ReadFile(installRecordPath(name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return record, errPluginNotFound(name)
+		}
+		return record, err
+	}
+	if err := json.Unmarshal(data, &record); err != nil {
+		return record, errors.Wrapf(err, "invalid install record for plugin %s", name)
+	}
+	return record, nil
+}
+
+// ListInstallRecords returns the install records of the plugins installed
+// from a registry, sorted by name.
+func ListInstallRecords() ([]InstallRecord, error) {
+	dentries, err := ioutil.ReadDir(filepath.Join(UserPluginDir(), installRecordsDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var records []InstallRecord
+	for _, dentry := range dentries {
+		name := strings.TrimSuffix(dentry.Name(), ".json")
+		if dentry.IsDir() || name == dentry.Name() {
+			continue
+		}
+		record, err := GetInstallRecord(name)
+		if err != nil {
+			if IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
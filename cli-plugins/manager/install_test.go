@@ -0,0 +1,83 @@
+package manager
+
+import (
+	"io/ioutil"
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/docker/cli/cli/config"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+	"gotest.tools/fs"
+)
+
+func TestInstallPlugin(t *testing.T) {
+	dir := fs.NewDir(t, t.Name())
+	defer dir.Remove()
+	defer config.SetDir(config.Dir())
+	config.SetDir(dir.Path())
+
+	record := InstallRecord{
+		Name:    "hello",
+		Source:  "example.com/plugins/docker-hello:1.0",
+		Digest:  "sha256:0000000000000000000000000000000000000000000000000000000000000000",
+		Version: "1.0",
+	}
+	path, err := InstallPlugin(record, []byte("#!/bin/sh\n"))
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(UserPluginPath("hello"), path))
+
+	content, err := ioutil.ReadFile(path)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal("#!/bin/sh\n", string(content)))
+	if runtime.GOOS != "windows" {
+		fi, err := os.Stat(path)
+		assert.NilError(t, err)
+		assert.Check(t, is.Equal(os.FileMode(0755), fi.Mode().Perm()))
+	}
+
+	// the install record is not a plugin candidate
+	candidates, err := listPluginCandidates([]string{UserPluginDir()})
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(map[string][]string{"hello": {path}}, candidates))
+
+	actual, err := GetInstallRecord("hello")
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(record, actual))
+
+	records, err := ListInstallRecords()
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual([]InstallRecord{record}, records))
+
+	assert.NilError(t, ioutil.WriteFile(path+SignatureSuffix, []byte("{}"), 0644))
+	assert.NilError(t, UninstallPlugin("hello"))
+	_, err = os.Stat(path)
+	assert.Check(t, os.IsNotExist(err))
+	_, err = os.Stat(path + SignatureSuffix)
+	assert.Check(t, os.IsNotExist(err))
+	_, err = GetInstallRecord("hello")
+	assert.Check(t, IsNotFound(err))
+	assert.Check(t, IsNotFound(UninstallPlugin("hello")))
+}
+
+func TestInstallPluginInvalidName(t *testing.T) {
+	dir := fs.NewDir(t, t.Name())
+	defer dir.Remove()
+	defer config.SetDir(config.Dir())
+	config.SetDir(dir.Path())
+
+	_, err := InstallPlugin(InstallRecord{Name: "../hello"}, nil)
+	assert.ErrorContains(t, err, `invalid plugin name "../hello"`)
+}
+
+func TestListInstallRecordsEmpty(t *testing.T) {
+	dir := fs.NewDir(t, t.Name())
+	defer dir.Remove()
+	defer config.SetDir(config.Dir())
+	config.SetDir(dir.Path())
+
+	records, err := ListInstallRecords()
+	assert.NilError(t, err)
+	assert.Check(t, is.Len(records, 0))
+}
@@ -8,7 +8,7 @@ import (
 	"strings"
 
 	"github.com/docker/cli/cli/command"
-	"github.com/docker/cli/cli/config"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
@@ -41,7 +41,7 @@ func getPluginDirs(dockerCli command.Cli) []string {
 	if cfg := dockerCli.ConfigFile(); cfg != nil {
 		pluginDirs = append(pluginDirs, cfg.CLIPluginsExtraDirs...)
 	}
-	pluginDirs = append(pluginDirs, config.Path("cli-plugins"))
+	pluginDirs = append(pluginDirs, UserPluginDir())
 	pluginDirs = append(pluginDirs, defaultSystemPluginDirs...)
 	return pluginDirs
 }
@@ -60,7 +60,7 @@ func addPluginCandidatesFromDir(res map[string][]string, d string) error {
 			continue
 		}
 		name := dentry.Name()
-		if !strings.HasPrefix(name, NamePrefix) {
+		if !strings.HasPrefix(name, NamePrefix) || strings.HasSuffix(name, SignatureSuffix) {
 			continue
 		}
 		name = strings.TrimPrefix(name, NamePrefix)
@@ -107,7 +107,7 @@ func ListPlugins(dockerCli command.Cli, rootcmd *cobra.Command) ([]Plugin, error
 			continue
 		}
 		c := &candidate{paths[0]}
-		p, err := newPlugin(c, rootcmd)
+		p, err := newPlugin(c, rootcmd, dockerCli)
 		if err != nil {
 			return nil, err
 		}
@@ -125,7 +125,12 @@ func PluginRunCommand(dockerCli command.Cli, name string, rootcmd *cobra.Command
 	// This uses the full original args, not the args which may
 	// have been provided by cobra to our caller. This is because
 	// they lack e.g. global options which we must propagate here.
-	args := os.Args[1:]
+	return pluginCommand(dockerCli, name, rootcmd, os.Args[1:])
+}
+
+// pluginCommand returns an "os/exec".Cmd which when .Run() will execute the
+// named plugin with args.
+func pluginCommand(dockerCli command.Cli, name string, rootcmd *cobra.Command, args []string) (*exec.Cmd, error) {
 	if !pluginNameRe.MatchString(name) {
 		// We treat this as "not found" so that callers will
 		// fallback to their "invalid" command path.
@@ -144,13 +149,24 @@ func PluginRunCommand(dockerCli command.Cli, name string, rootcmd *cobra.Command
 		}
 
 		c := &candidate{path: path}
-		plugin, err := newPlugin(c, rootcmd)
+		plugin, err := newPlugin(c, rootcmd, dockerCli)
 		if err != nil {
 			return nil, err
 		}
 		if plugin.Err != nil {
+			if IsIncompatible(plugin.Err) {
+				return nil, errors.Errorf("CLI plugin %s is not compatible: %v", name, plugin.Err)
+			}
+			if IsUnverified(plugin.Err) {
+				return nil, errors.Errorf("CLI plugin %s is not verified: %v", name, plugin.Err)
+			}
 			return nil, errPluginNotFound(name)
 		}
+		if plugin.verified != nil {
+			if err := checkUnmodified(plugin.Path, plugin.verified); err != nil {
+				return nil, errors.Errorf("CLI plugin %s is not verified: %v", name, err)
+			}
+		}
 		cmd := exec.Command(plugin.Path, args...)
 		// Using dockerCli.{In,Out,Err}() here results in a hang until something is input.
 		// See: - https://github.com/golang/go/issues/10338
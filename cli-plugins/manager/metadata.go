@@ -8,11 +8,30 @@ const (
 	// which must be supported by every plugin and returns the
 	// plugin metadata.
 	MetadataSubcommandName = "docker-cli-plugin-metadata"
+
+	// PluginAPIVersion is the version of the CLI plugin API implemented
+	// by this CLI, which plugins can require with
+	// Capabilities.MinPluginAPIVersion:
+	//
+	//  - 1.0: the metadata and primary entry point subcommands
+	//  - 1.1: the completion subcommand
+	//  - 1.2: the hook subcommand
+	PluginAPIVersion = "1.2"
+
+	// ExperimentalCLI is the experimental feature which plugins
+	// require with Capabilities.Experimental when they need the
+	// experimental features of the CLI to be enabled.
+	ExperimentalCLI = "cli"
+
+	// ExperimentalDaemon is the experimental feature which plugins
+	// require with Capabilities.Experimental when they need the
+	// experimental features of the daemon to be enabled.
+	ExperimentalDaemon = "daemon"
 )
 
 // Metadata provided by the plugin. See docs/extend/cli_plugins.md for canonical information.
 type Metadata struct {
-	// SchemaVersion describes the version of this struct. Mandatory, must be "0.1.0"
+	// SchemaVersion describes the version of this struct. Mandatory, must be "0.1.0", or "0.2.0" when Capabilities are declared
 	SchemaVersion string `json:",omitempty"`
 	// Vendor is the name of the plugin vendor. Mandatory
 	Vendor string `json:",omitempty"`
@@ -22,4 +41,25 @@ type Metadata struct {
 	ShortDescription string `json:",omitempty"`
 	// URL is a pointer to the plugin's homepage.
 	URL string `json:",omitempty"`
+	// Hooks are the names of the builtin commands after which the plugin
+	// is invoked with the HookSubcommandName subcommand: "build", "push"
+	// or "login".
+	Hooks []string `json:",omitempty"`
+	// Capabilities are the optional requirements of the plugin on the
+	// CLI and the daemon. Plugins which requirements are not met are
+	// marked as incompatible, and are not run.
+	Capabilities *Capabilities `json:",omitempty"`
+}
+
+// Capabilities are the requirements of a plugin on the CLI and the daemon.
+type Capabilities struct {
+	// MinPluginAPIVersion is the minimum version of the CLI plugin API
+	// the plugin requires, see PluginAPIVersion.
+	MinPluginAPIVersion string `json:",omitempty"`
+	// MinAPIVersion is the minimum version of the Engine API the plugin
+	// requires.
+	MinAPIVersion string `json:",omitempty"`
+	// Experimental are the experimental features the plugin requires to
+	// be enabled: ExperimentalCLI or ExperimentalDaemon.
+	Experimental []string `json:",omitempty"`
 }
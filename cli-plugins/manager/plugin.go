@@ -2,11 +2,15 @@ package manager
 
 import (
 	"encoding/json"
+	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
 
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/docker/api/types/versions"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
@@ -26,14 +30,24 @@ type Plugin struct {
 
 	// ShadowedPaths contains the paths of any other plugins which this plugin takes precedence over.
 	ShadowedPaths []string `json:",omitempty"`
+
+	// Verification describes how the binary of the plugin was verified,
+	// if the policy requires plugins to be verified, see VerifyPlugin.
+	Verification string `json:",omitempty"`
+
+	// verified is the FileInfo of the verified binary, if the binary was
+	// verified
+	verified os.FileInfo
 }
 
 // newPlugin determines if the given candidate is valid and returns a
 // Plugin.  If the candidate fails one of the tests then `Plugin.Err`
 // is set, and is always a `pluginError`, but the `Plugin` is still
 // returned with no error. An error is only returned due to a
-// non-recoverable error.
-func newPlugin(c Candidate, rootcmd *cobra.Command) (Plugin, error) {
+// non-recoverable error. The capabilities of the plugin are checked
+// against dockerCli, unless it is nil, and so is its binary if the
+// policy of the config file requires plugins to be verified.
+func newPlugin(c Candidate, rootcmd *cobra.Command, dockerCli command.Cli) (Plugin, error) {
 	path := c.Path()
 	if path == "" {
 		return Plugin{}, errors.New("plugin candidate path cannot be empty")
@@ -59,28 +73,23 @@ func newPlugin(c Candidate, rootcmd *cobra.Command) (Plugin, error) {
 	}
 
 	// Now apply the candidate tests, so these update p.Err.
-	if !pluginNameRe.MatchString(p.Name) {
-		p.Err = NewPluginError("plugin candidate %q did not match %q", p.Name, pluginNameRe.String())
+	if err := ValidateName(p.Name, rootcmd); err != nil {
+		p.Err = err
 		return p, nil
 	}
 
-	if rootcmd != nil {
-		for _, cmd := range rootcmd.Commands() {
-			// Ignore conflicts with commands which are
-			// just plugin stubs (i.e. from a previous
-			// call to AddPluginCommandStubs).
-			if p := cmd.Annotations[CommandAnnotationPlugin]; p == "true" {
-				continue
-			}
-			if cmd.Name() == p.Name {
-				p.Err = NewPluginError("plugin %q duplicates builtin command", p.Name)
-				return p, nil
-			}
-			if cmd.HasAlias(p.Name) {
-				p.Err = NewPluginError("plugin %q duplicates an alias of builtin command %q", p.Name, cmd.Name())
-				return p, nil
+	// The binary is verified before being run to fetch the metadata.
+	if dockerCli != nil && verificationEnforced(dockerCli.ConfigFile()) {
+		verification, verified, err := verifyPlugin(dockerCli.ConfigFile(), p.Name, p.Path)
+		if err != nil {
+			if !IsUnverified(err) {
+				err = wrapAsPluginError(err, "failed to verify plugin")
 			}
+			p.Err = err
+			return p, nil
 		}
+		p.Verification = verification
+		p.verified = verified
 	}
 
 	// We are supposed to check for relevant execute permissions here. Instead we rely on an attempt to execute.
@@ -95,13 +104,95 @@ func newPlugin(c Candidate, rootcmd *cobra.Command) (Plugin, error) {
 		return p, nil
 	}
 
-	if p.Metadata.SchemaVersion != "0.1.0" {
-		p.Err = NewPluginError("plugin SchemaVersion %q is not valid, must be 0.1.0", p.Metadata.SchemaVersion)
+	if err := ValidateMetadata(p.Metadata); err != nil {
+		p.Err = err
 		return p, nil
 	}
-	if p.Metadata.Vendor == "" {
-		p.Err = NewPluginError("plugin metadata does not define a vendor")
-		return p, nil
+	if dockerCli != nil {
+		p.Err = checkCapabilities(p.Capabilities, dockerCli)
 	}
 	return p, nil
 }
+
+// ValidateName checks that name is a valid plugin name, which doesn't
+// conflict with the builtin commands of rootcmd. The error returned is a
+// pluginError.
+func ValidateName(name string, rootcmd *cobra.Command) error {
+	if !pluginNameRe.MatchString(name) {
+		return NewPluginError("plugin candidate %q did not match %q", name, pluginNameRe.String())
+	}
+	if rootcmd == nil {
+		return nil
+	}
+	for _, cmd := range rootcmd.Commands() {
+		// Ignore conflicts with commands which are
+		// just plugin stubs (i.e. from a previous
+		// call to AddPluginCommandStubs).
+		if p := cmd.Annotations[CommandAnnotationPlugin]; p == "true" {
+			continue
+		}
+		if cmd.Name() == name {
+			return NewPluginError("plugin %q duplicates builtin command", name)
+		}
+		if cmd.HasAlias(name) {
+			return NewPluginError("plugin %q duplicates an alias of builtin command %q", name, cmd.Name())
+		}
+	}
+	return nil
+}
+
+// ValidateMetadata checks the mandatory fields of the metadata of a plugin.
+// The error returned is a pluginError.
+func ValidateMetadata(meta Metadata) error {
+	switch meta.SchemaVersion {
+	case "0.1.0":
+		if meta.Capabilities != nil {
+			return NewPluginError("plugin SchemaVersion %q does not support capabilities, must be 0.2.0", meta.SchemaVersion)
+		}
+	case "0.2.0":
+	default:
+		return NewPluginError("plugin SchemaVersion %q is not valid, must be 0.1.0 or 0.2.0", meta.SchemaVersion)
+	}
+	if meta.Vendor == "" {
+		return NewPluginError("plugin metadata does not define a vendor")
+	}
+	return nil
+}
+
+// checkCapabilities checks that the CLI and the daemon meet the requirements
+// of a plugin. The error returned is a pluginError, which satisfies the
+// IsIncompatible() predicate.
+//
+// The minimum API version can't be checked if the API version of the daemon
+// is unknown, as when the daemon is unreachable: the plugin is then
+// considered compatible, and reports the error when it connects.
+func checkCapabilities(caps *Capabilities, dockerCli command.Cli) error {
+	if caps == nil {
+		return nil
+	}
+	if v := caps.MinPluginAPIVersion; v != "" && versions.LessThan(PluginAPIVersion, v) {
+		return NewIncompatiblePluginError("plugin requires CLI plugin API version %s, but the CLI plugin API version is %s", v, PluginAPIVersion)
+	}
+	if v := caps.MinAPIVersion; v != "" {
+		if apiVersion := dockerCli.ServerInfo().APIVersion; apiVersion == "" {
+			logrus.Debugf("the API version of the daemon is unknown, assuming it is at least %s", v)
+		} else if versions.LessThan(apiVersion, v) {
+			return NewIncompatiblePluginError("plugin requires API version %s, but the Docker daemon API version is %s", v, apiVersion)
+		}
+	}
+	for _, feature := range caps.Experimental {
+		switch feature {
+		case ExperimentalCLI:
+			if !dockerCli.ClientInfo().HasExperimental {
+				return NewIncompatiblePluginError("plugin requires the experimental features of the CLI to be enabled")
+			}
+		case ExperimentalDaemon:
+			if !dockerCli.ServerInfo().HasExperimental {
+				return NewIncompatiblePluginError("plugin requires the experimental features of the daemon to be enabled")
+			}
+		default:
+			return NewIncompatiblePluginError("plugin requires unsupported experimental feature %q", feature)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package manager
+
+import (
+	"testing"
+
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/internal/test"
+	"github.com/docker/docker/client"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+func TestValidateMetadataCapabilities(t *testing.T) {
+	caps := &Capabilities{MinPluginAPIVersion: "1.0"}
+	assert.Check(t, ValidateMetadata(Metadata{SchemaVersion: "0.2.0", Vendor: "ACME Corp", Capabilities: caps}))
+	assert.Check(t, ValidateMetadata(Metadata{SchemaVersion: "0.2.0", Vendor: "ACME Corp"}))
+	err := ValidateMetadata(Metadata{SchemaVersion: "0.1.0", Vendor: "ACME Corp", Capabilities: caps})
+	assert.Check(t, is.Error(err, `plugin SchemaVersion "0.1.0" does not support capabilities, must be 0.2.0`))
+}
+
+func TestCheckCapabilities(t *testing.T) {
+	// the API version of the client is ignored, in favor of the daemon's
+	apiClient, err := client.NewClientWithOpts(client.WithVersion("1.40"))
+	assert.NilError(t, err)
+	cli := test.NewFakeCli(apiClient)
+	cli.SetServerInfo(command.ServerInfo{APIVersion: "1.30"})
+	cli.SetClientInfo(func() command.ClientInfo { return command.ClientInfo{HasExperimental: true} })
+
+	for _, tc := range []struct {
+		caps *Capabilities
+		err  string
+	}{
+		{caps: nil},
+		{caps: &Capabilities{}},
+		{caps: &Capabilities{MinPluginAPIVersion: "1.0", MinAPIVersion: "1.25", Experimental: []string{ExperimentalCLI}}},
+		{caps: &Capabilities{MinPluginAPIVersion: PluginAPIVersion, MinAPIVersion: "1.30"}},
+		{
+			caps: &Capabilities{MinPluginAPIVersion: "99.0"},
+			err:  "plugin requires CLI plugin API version 99.0, but the CLI plugin API version is " + PluginAPIVersion,
+		},
+		{
+			caps: &Capabilities{MinAPIVersion: "1.40"},
+			err:  "plugin requires API version 1.40, but the Docker daemon API version is 1.30",
+		},
+		{
+			caps: &Capabilities{Experimental: []string{ExperimentalDaemon}},
+			err:  "plugin requires the experimental features of the daemon to be enabled",
+		},
+		{
+			caps: &Capabilities{Experimental: []string{"teleport"}},
+			err:  `plugin requires unsupported experimental feature "teleport"`,
+		},
+	} {
+		err := checkCapabilities(tc.caps, cli)
+		if tc.err == "" {
+			assert.Check(t, err)
+			continue
+		}
+		assert.Check(t, is.Error(err, tc.err))
+		assert.Check(t, IsIncompatible(err))
+	}
+
+	cli.SetClientInfo(func() command.ClientInfo { return command.ClientInfo{} })
+	err = checkCapabilities(&Capabilities{Experimental: []string{ExperimentalCLI}}, cli)
+	assert.Check(t, is.Error(err, "plugin requires the experimental features of the CLI to be enabled"))
+
+	// the daemon API version is unknown
+	cli.SetServerInfo(command.ServerInfo{})
+	assert.Check(t, checkCapabilities(&Capabilities{MinAPIVersion: "1.40"}, cli))
+}
+
+func TestIsIncompatible(t *testing.T) {
+	assert.Check(t, !IsIncompatible(NewPluginError("something wrong")))
+	assert.Check(t, !IsIncompatible(errPluginNotFound("foo")))
+	assert.Check(t, IsIncompatible(NewIncompatiblePluginError("too old")))
+}
@@ -0,0 +1,213 @@
+package manager
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/cli/cli/trust"
+	"github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	"github.com/theupdateframework/notary/tuf/data"
+	"github.com/theupdateframework/notary/tuf/signed"
+	tufutils "github.com/theupdateframework/notary/tuf/utils"
+)
+
+const (
+	// PolicyVerified is the value of the cliPluginsPolicy property of the
+	// config file which only allows verified plugins to run. A plugin is
+	// verified if the digest of its binary is one of the digests trusted
+	// for the plugin in the cliPluginsDigests property, or if its binary
+	// has a detached signature made with one of the keys of TrustDir().
+	PolicyVerified = "verified"
+
+	// SignatureSuffix is appended to the path of a plugin binary to get the
+	// path of its detached signature.
+	SignatureSuffix = ".sig"
+
+	// publicKeyExtension is the extension of the public keys in TrustDir(),
+	// as written by `docker trust key generate`.
+	publicKeyExtension = ".pub"
+)
+
+// Signature is the detached signature of a plugin binary, stored in JSON next
+// to the binary.
+type Signature struct {
+	// KeyID is the ID of the signing key.
+	KeyID string
+	// Method is the signature algorithm.
+	Method data.SigAlgorithm
+	// Signature is the signature of the digest of the plugin binary, such
+	// as "sha256:...".
+	Signature []byte
+}
+
+// hashedBinary is a plugin binary hashed during the invocation
+type hashedBinary struct {
+	info   os.FileInfo
+	digest digest.Digest
+}
+
+var (
+	hashedBinariesMu sync.Mutex
+	// hashedBinaries are the digests of the plugin binaries hashed during
+	// the invocation, by path. They are used as long as the file at the path
+	// is the same, with the same size and modification time.
+	hashedBinaries = make(map[string]hashedBinary)
+)
+
+// TrustDir returns the directory holding the public keys which the detached
+// signatures of plugins are verified against.
+func TrustDir() string {
+	return filepath.Join(trust.GetTrustDirectory(), "cli-plugins")
+}
+
+// verificationEnforced returns whether the user only allows verified plugins.
+func verificationEnforced(configFile *configfile.ConfigFile) bool {
+	return configFile != nil && configFile.CLIPluginsPolicy == PolicyVerified
+}
+
+// VerifyPlugin checks the binary at path of the named plugin against the
+// digests trusted for the plugin in configFile, then against its detached
+// signature. It returns how the binary was verified, either "digest" or
+// "signature (KEY)", KEY being the name of the public key. The error returned
+// satisfies the IsUnverified() predicate if the binary can't be verified.
+func VerifyPlugin(configFile *configfile.ConfigFile, name, path string) (string, error) {
+	verification, _, err := verifyPlugin(configFile, name, path)
+	return verification, err
+}
+
+// verifyPlugin verifies a plugin binary as VerifyPlugin does, and also
+// returns the FileInfo of the verified file, which must still be the file at
+// path when the plugin is run, see checkUnmodified.
+func verifyPlugin(configFile *configfile.ConfigFile, name, path string) (string, os.FileInfo, error) {
+	dgst, info, err := hashBinary(path)
+	if err != nil {
+		return "", nil, err
+	}
+	if configFile != nil {
+		for _, trusted := range configFile.CLIPluginsDigests[name] {
+			if digest.Digest(trusted) == dgst {
+				return "digest", info, nil
+			}
+		}
+	}
+
+	sigBytes, err := ioutil.ReadFile(path + SignatureSuffix)
+	switch {
+	case os.IsNotExist(err):
+		return "", nil, NewUnverifiedPluginError("plugin digest is not trusted and plugin is not signed")
+	case err != nil:
+		return "", nil, err
+	}
+	var sig Signature
+	if err := json.Unmarshal(sigBytes, &sig); err != nil {
+		return "", nil, NewUnverifiedPluginError("invalid signature %s: %v", path+SignatureSuffix, err)
+	}
+	keyName, key, err := findTrustedKey(sig.KeyID)
+	if err != nil {
+		return "", nil, err
+	}
+	verifier, ok := signed.Verifiers[sig.Method]
+	if !ok {
+		return "", nil, NewUnverifiedPluginError("unsupported signature method %q", sig.Method)
+	}
+	if err := verifier.Verify(key, sig.Signature, []byte(dgst.String())); err != nil {
+		return "", nil, NewUnverifiedPluginError("signature does not match plugin binary: %v", err)
+	}
+	return "signature (" + keyName + ")", info, nil
+}
+
+// hashBinary returns the digest of the plugin binary at path, streamed from
+// the file, and the FileInfo of the hashed file. The digest is computed once
+// per invocation, unless the file changes.
+func hashBinary(path string) (digest.Digest, os.FileInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return "", nil, err
+	}
+
+	hashedBinariesMu.Lock()
+	cached, ok := hashedBinaries[path]
+	hashedBinariesMu.Unlock()
+	if ok && sameBinary(cached.info, info) {
+		return cached.digest, info, nil
+	}
+	dgst, err := digest.Canonical.FromReader(f)
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "failed to hash %s", path)
+	}
+	hashedBinariesMu.Lock()
+	hashedBinaries[path] = hashedBinary{info: info, digest: dgst}
+	hashedBinariesMu.Unlock()
+	return dgst, info, nil
+}
+
+// sameBinary checks if two FileInfos describe the same file, with the same
+// size and modification time
+func sameBinary(a, b os.FileInfo) bool {
+	return os.SameFile(a, b) && a.Size() == b.Size() && a.ModTime().Equal(b.ModTime())
+}
+
+// checkUnmodified checks the file at path is still the verified file, so
+// that a binary replaced or modified after its verification isn't run.
+func checkUnmodified(path string, verified os.FileInfo) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !sameBinary(verified, info) {
+		return NewUnverifiedPluginError("plugin binary was modified after its verification")
+	}
+	return nil
+}
+
+// findTrustedKey returns the public key of TrustDir() with the given ID, and
+// its name.
+func findTrustedKey(keyID string) (string, data.PublicKey, error) {
+	entries, err := ioutil.ReadDir(TrustDir())
+	if err != nil && !os.IsNotExist(err) {
+		return "", nil, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != publicKeyExtension {
+			continue
+		}
+		pubKeyBytes, err := ioutil.ReadFile(filepath.Join(TrustDir(), entry.Name()))
+		if err != nil {
+			return "", nil, err
+		}
+		key, err := tufutils.ParsePEMPublicKey(pubKeyBytes)
+		if err != nil {
+			return "", nil, errors.Wrapf(err, "could not parse public key from file: %s", entry.Name())
+		}
+		if key.ID() == keyID {
+			return strings.TrimSuffix(entry.Name(), publicKeyExtension), key, nil
+		}
+	}
+	return "", nil, NewUnverifiedPluginError("plugin is signed with untrusted key %s", keyID)
+}
+
+// SignPlugin returns the detached signature made with key of a plugin binary,
+// given its digest.
+func SignPlugin(dgst digest.Digest, key data.PrivateKey) (Signature, error) {
+	sig, err := key.Sign(rand.Reader, []byte(dgst.String()), nil)
+	if err != nil {
+		return Signature{}, err
+	}
+	return Signature{
+		KeyID:     key.ID(),
+		Method:    key.SignatureAlgorithm(),
+		Signature: sig,
+	}, nil
+}
@@ -0,0 +1,153 @@
+package manager
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/cli/cli/config"
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/cli/internal/test"
+	"github.com/opencontainers/go-digest"
+	"github.com/theupdateframework/notary/tuf/data"
+	tufutils "github.com/theupdateframework/notary/tuf/utils"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+	"gotest.tools/fs"
+)
+
+const pluginBinary = "#!/bin/sh\necho hello\n"
+
+func TestVerifyPlugin(t *testing.T) {
+	dir := fs.NewDir(t, t.Name(), fs.WithFile(NamePrefix+"hello", pluginBinary))
+	defer dir.Remove()
+	defer config.SetDir(config.Dir())
+	config.SetDir(dir.Path())
+	path := dir.Join(NamePrefix + "hello")
+
+	_, err := VerifyPlugin(nil, "hello", path)
+	assert.Check(t, IsUnverified(err))
+	assert.Check(t, is.ErrorContains(err, "plugin digest is not trusted and plugin is not signed"))
+
+	configFile := &configfile.ConfigFile{
+		CLIPluginsDigests: map[string][]string{
+			"hello":  {digest.FromString(pluginBinary).String()},
+			"world":  {digest.FromString("another binary").String()},
+			"random": {"not a digest"},
+		},
+	}
+	verification, err := VerifyPlugin(configFile, "hello", path)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal("digest", verification))
+
+	// the digests are those of the named plugin
+	_, err = VerifyPlugin(configFile, "world", path)
+	assert.Check(t, IsUnverified(err))
+
+	key := generateTrustedKey(t, "alice")
+	writeSignature(t, path, key)
+	verification, err = VerifyPlugin(nil, "hello", path)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal("signature (alice)", verification))
+
+	// the signature no longer matches a modified binary
+	assert.NilError(t, ioutil.WriteFile(path, []byte(pluginBinary+"echo world\n"), 0755))
+	_, err = VerifyPlugin(nil, "hello", path)
+	assert.Check(t, IsUnverified(err))
+	assert.Check(t, is.ErrorContains(err, "signature does not match plugin binary"))
+
+	// the signing key must be trusted
+	untrusted, err := tufutils.GenerateKey(data.ECDSAKey)
+	assert.NilError(t, err)
+	writeSignature(t, path, untrusted)
+	_, err = VerifyPlugin(nil, "hello", path)
+	assert.Check(t, IsUnverified(err))
+	assert.Check(t, is.ErrorContains(err, "plugin is signed with untrusted key "+untrusted.ID()))
+}
+
+func TestVerifyPluginModifiedAfterVerification(t *testing.T) {
+	dir := fs.NewDir(t, t.Name(), fs.WithFile(NamePrefix+"hello", pluginBinary))
+	defer dir.Remove()
+	path := dir.Join(NamePrefix + "hello")
+	configFile := &configfile.ConfigFile{
+		CLIPluginsDigests: map[string][]string{"hello": {digest.FromString(pluginBinary).String()}},
+	}
+
+	_, verified, err := verifyPlugin(configFile, "hello", path)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(digest.FromString(pluginBinary), hashedBinaries[path].digest))
+	assert.NilError(t, checkUnmodified(path, verified))
+
+	// the binary is replaced after its verification
+	assert.NilError(t, ioutil.WriteFile(path+".new", []byte("#!/bin/sh\necho pwned\n"), 0755))
+	assert.NilError(t, os.Rename(path+".new", path))
+	err = checkUnmodified(path, verified)
+	assert.Check(t, IsUnverified(err))
+	assert.Check(t, is.ErrorContains(err, "plugin binary was modified after its verification"))
+
+	// the cached digest isn't used for the new binary
+	_, _, err = verifyPlugin(configFile, "hello", path)
+	assert.Check(t, IsUnverified(err))
+}
+
+func TestListPluginsSkipsSignatures(t *testing.T) {
+	dir := fs.NewDir(t, t.Name(),
+		fs.WithFile(NamePrefix+"hello", pluginBinary),
+		fs.WithFile(NamePrefix+"hello"+SignatureSuffix, "{}"),
+	)
+	defer dir.Remove()
+
+	candidates, err := listPluginCandidates([]string{dir.Path()})
+	assert.NilError(t, err)
+	assert.Check(t, is.Len(candidates, 1))
+	assert.Check(t, is.Len(candidates["hello"], 1))
+}
+
+func TestNewPluginVerificationEnforced(t *testing.T) {
+	dir := fs.NewDir(t, t.Name(), fs.WithFile(NamePrefix+"hello", pluginBinary))
+	defer dir.Remove()
+	defer config.SetDir(config.Dir())
+	config.SetDir(dir.Path())
+	path := dir.Join(NamePrefix + "hello")
+
+	configFile := &configfile.ConfigFile{CLIPluginsPolicy: PolicyVerified}
+	cli := test.NewFakeCli(nil)
+	cli.SetConfigFile(configFile)
+	c := &fakeCandidate{path: path, exec: true, meta: `{"SchemaVersion": "0.1.0", "Vendor": "e2e-testing"}`}
+	p, err := newPlugin(c, nil, cli)
+	assert.NilError(t, err)
+	assert.Check(t, IsUnverified(p.Err))
+	assert.Check(t, is.Equal("", p.Vendor), "the metadata of unverified plugins is not fetched")
+
+	configFile.CLIPluginsDigests = map[string][]string{"hello": {digest.FromString(pluginBinary).String()}}
+	p, err = newPlugin(c, nil, cli)
+	assert.NilError(t, err)
+	assert.NilError(t, p.Err)
+	assert.Check(t, is.Equal("digest", p.Verification))
+	assert.Check(t, is.Equal("e2e-testing", p.Vendor))
+}
+
+func generateTrustedKey(t *testing.T, name string) data.PrivateKey {
+	t.Helper()
+	key, err := tufutils.GenerateKey(data.ECDSAKey)
+	assert.NilError(t, err)
+	pubKey := data.PublicKeyFromPrivate(key)
+	pubPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubKey.Public()})
+	assert.NilError(t, os.MkdirAll(TrustDir(), 0755))
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(TrustDir(), name+".pub"), pubPEM, 0644))
+	return key
+}
+
+func writeSignature(t *testing.T, path string, key data.PrivateKey) {
+	t.Helper()
+	binary, err := ioutil.ReadFile(path)
+	assert.NilError(t, err)
+	sig, err := SignPlugin(digest.FromBytes(binary), key)
+	assert.NilError(t, err)
+	sigBytes, err := json.Marshal(sig)
+	assert.NilError(t, err)
+	assert.NilError(t, ioutil.WriteFile(path+SignatureSuffix, sigBytes, 0644))
+}
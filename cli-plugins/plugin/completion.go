@@ -0,0 +1,185 @@
+package plugin
+
+import (
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/docker/cli/cli-plugins/manager"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// CompletionFunc returns the completions of the word toComplete, for a
+// positional argument of cmd or for the value of one of its flags. args are
+// the positional arguments already on the command line, the flags have been
+// parsed. Values may be followed by a tab and a description. Completion
+// functions which need to connect to the daemon must call PersistentPreRunE
+// first.
+type CompletionFunc func(cmd *cobra.Command, args []string, toComplete string) ([]string, manager.CompletionDirective)
+
+var completionFuncs = struct {
+	sync.Mutex
+	args  map[*cobra.Command]CompletionFunc
+	flags map[*pflag.Flag]CompletionFunc
+}{
+	args:  make(map[*cobra.Command]CompletionFunc),
+	flags: make(map[*pflag.Flag]CompletionFunc),
+}
+
+// RegisterCompletionFunc registers the function completing the positional
+// arguments of cmd. Commands without a completion function complete their
+// subcommands, or their ValidArgs, or fall back to file names.
+func RegisterCompletionFunc(cmd *cobra.Command, fn CompletionFunc) {
+	completionFuncs.Lock()
+	defer completionFuncs.Unlock()
+	completionFuncs.args[cmd] = fn
+}
+
+// RegisterFlagCompletionFunc registers the function completing the values of
+// the named flag of cmd.
+func RegisterFlagCompletionFunc(cmd *cobra.Command, flagName string, fn CompletionFunc) error {
+	flag := cmd.Flags().Lookup(flagName)
+	if flag == nil {
+		return errors.Errorf("flag %q does not exist", flagName)
+	}
+	completionFuncs.Lock()
+	defer completionFuncs.Unlock()
+	completionFuncs.flags[flag] = fn
+	return nil
+}
+
+func newCompletionSubcommand(plugin *cobra.Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:    manager.CompletionSubcommandName,
+		Hidden: true,
+		// Flags being completed are arguments of this command.
+		DisableFlagParsing: true,
+		// Suppress the global/parent PersistentPreRunE, which needlessly initializes the client and tries to connect to the daemon.
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var c manager.Completion
+			if len(args) < 2 || args[0] != plugin.Name() {
+				c.Directive = manager.CompletionDirectiveError
+			} else {
+				c = complete(plugin, args[1:len(args)-1], args[len(args)-1])
+			}
+			return manager.WriteCompletion(os.Stdout, c)
+		},
+	}
+	return cmd
+}
+
+// complete returns the completions of the word toComplete, args being the
+// arguments of the plugin command which precede it.
+func complete(plugin *cobra.Command, args []string, toComplete string) manager.Completion {
+	// Errors are those of the validation of the positional arguments,
+	// which are irrelevant while the command line is being typed.
+	cmd, args, _ := plugin.Find(args)
+	// Find only strips the names of the subcommands, the remaining
+	// arguments are parsed to separate the flags from the positional
+	// arguments, and to make the value of the flags available to the
+	// completion functions.
+	_ = cmd.ParseFlags(args)
+	flags := cmd.Flags()
+
+	// The value of a flag, separated by a space
+	if len(args) > 0 {
+		if flag := flagExpectingValue(flags, args[len(args)-1]); flag != nil {
+			return completeFlagValue(cmd, flag, "", toComplete)
+		}
+	}
+	// The value of a flag, separated by an equal sign
+	if strings.HasPrefix(toComplete, "-") && strings.Contains(toComplete, "=") {
+		i := strings.Index(toComplete, "=")
+		if flag := flagExpectingValue(flags, toComplete[:i]); flag != nil {
+			return completeFlagValue(cmd, flag, toComplete[:i+1], toComplete[i+1:])
+		}
+		return manager.Completion{Directive: manager.CompletionDirectiveNoFileComp}
+	}
+	if strings.HasPrefix(toComplete, "-") {
+		return completeFlagNames(flags, toComplete)
+	}
+
+	if cmd.HasAvailableSubCommands() {
+		var c manager.Completion
+		for _, sub := range cmd.Commands() {
+			if sub.IsAvailableCommand() && strings.HasPrefix(sub.Name(), toComplete) {
+				c.Values = append(c.Values, withDescription(sub.Name(), sub.Short))
+			}
+		}
+		c.Directive = manager.CompletionDirectiveNoFileComp
+		return c
+	}
+
+	completionFuncs.Lock()
+	fn, ok := completionFuncs.args[cmd]
+	completionFuncs.Unlock()
+	if ok {
+		values, directive := fn(cmd, flags.Args(), toComplete)
+		return manager.Completion{Values: values, Directive: directive}
+	}
+	if len(cmd.ValidArgs) > 0 {
+		c := manager.Completion{Directive: manager.CompletionDirectiveNoFileComp}
+		for _, v := range cmd.ValidArgs {
+			if strings.HasPrefix(v, toComplete) {
+				c.Values = append(c.Values, v)
+			}
+		}
+		return c
+	}
+	return manager.Completion{}
+}
+
+// flagExpectingValue returns the flag named by arg, if it takes a value.
+func flagExpectingValue(flags *pflag.FlagSet, arg string) *pflag.Flag {
+	var flag *pflag.Flag
+	switch {
+	case strings.HasPrefix(arg, "--"):
+		flag = flags.Lookup(arg[2:])
+	case strings.HasPrefix(arg, "-") && len(arg) == 2:
+		flag = flags.ShorthandLookup(arg[1:])
+	}
+	if flag == nil || flag.NoOptDefVal != "" {
+		return nil
+	}
+	return flag
+}
+
+func completeFlagValue(cmd *cobra.Command, flag *pflag.Flag, prefix, toComplete string) manager.Completion {
+	completionFuncs.Lock()
+	fn, ok := completionFuncs.flags[flag]
+	completionFuncs.Unlock()
+	if !ok {
+		return manager.Completion{}
+	}
+	values, directive := fn(cmd, cmd.Flags().Args(), toComplete)
+	for i, v := range values {
+		values[i] = prefix + v
+	}
+	return manager.Completion{Values: values, Directive: directive}
+}
+
+func completeFlagNames(flags *pflag.FlagSet, toComplete string) manager.Completion {
+	c := manager.Completion{Directive: manager.CompletionDirectiveNoFileComp}
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if flag.Hidden || flag.Deprecated != "" {
+			return
+		}
+		if name := "--" + flag.Name; strings.HasPrefix(name, toComplete) {
+			c.Values = append(c.Values, withDescription(name, flag.Usage))
+		}
+		if name := "-" + flag.Shorthand; flag.Shorthand != "" && flag.ShorthandDeprecated == "" && strings.HasPrefix(name, toComplete) {
+			c.Values = append(c.Values, withDescription(name, flag.Usage))
+		}
+	})
+	return c
+}
+
+func withDescription(value, description string) string {
+	if description == "" {
+		return value
+	}
+	return value + "\t" + description
+}
@@ -0,0 +1,137 @@
+package plugin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/docker/cli/cli-plugins/manager"
+	"github.com/spf13/cobra"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+func newTestPlugin(t *testing.T) *cobra.Command {
+	cmd := &cobra.Command{Use: "helloworld"}
+	cmd.Flags().String("who", "", "Who are we addressing?")
+	cmd.Flags().BoolP("quiet", "q", false, "Say it quietly")
+	cmd.Flags().String("secret", "", "Hidden flag")
+	assert.NilError(t, cmd.Flags().MarkHidden("secret"))
+	assert.NilError(t, RegisterFlagCompletionFunc(cmd, "who", func(_ *cobra.Command, _ []string, toComplete string) ([]string, manager.CompletionDirective) {
+		return []string{"World", "Moon"}, manager.CompletionDirectiveNoFileComp
+	}))
+
+	run := func(*cobra.Command, []string) {}
+	goodbye := &cobra.Command{Use: "goodbye", Short: "Say Goodbye instead of Hello", Run: run}
+	goodbye.Flags().StringP("name", "n", "", "Name of the container")
+	RegisterCompletionFunc(goodbye, func(cmd *cobra.Command, args []string, toComplete string) ([]string, manager.CompletionDirective) {
+		name, _ := cmd.Flags().GetString("name")
+		return []string{name + ":" + strings.Join(args, ",") + ":" + toComplete}, manager.CompletionDirectiveNoSpace
+	})
+	legacy := &cobra.Command{Use: "legacy", Hidden: true, Run: run}
+	color := &cobra.Command{Use: "color", ValidArgs: []string{"red", "green", "blue"}, Run: run}
+	cmd.AddCommand(goodbye, legacy, color)
+	return cmd
+}
+
+func TestComplete(t *testing.T) {
+	testCases := []struct {
+		name       string
+		args       []string
+		toComplete string
+		expected   manager.Completion
+	}{
+		{
+			name: "subcommands",
+			expected: manager.Completion{
+				Values:    []string{"color", "goodbye\tSay Goodbye instead of Hello"},
+				Directive: manager.CompletionDirectiveNoFileComp,
+			},
+		},
+		{
+			name:       "subcommands with prefix",
+			toComplete: "go",
+			expected: manager.Completion{
+				Values:    []string{"goodbye\tSay Goodbye instead of Hello"},
+				Directive: manager.CompletionDirectiveNoFileComp,
+			},
+		},
+		{
+			name:       "flags",
+			toComplete: "-",
+			expected: manager.Completion{
+				Values:    []string{"--quiet\tSay it quietly", "-q\tSay it quietly", "--who\tWho are we addressing?"},
+				Directive: manager.CompletionDirectiveNoFileComp,
+			},
+		},
+		{
+			name:       "long flags",
+			toComplete: "--w",
+			expected: manager.Completion{
+				Values:    []string{"--who\tWho are we addressing?"},
+				Directive: manager.CompletionDirectiveNoFileComp,
+			},
+		},
+		{
+			name: "flag value",
+			args: []string{"--who"},
+			expected: manager.Completion{
+				Values:    []string{"World", "Moon"},
+				Directive: manager.CompletionDirectiveNoFileComp,
+			},
+		},
+		{
+			name:       "flag value with equal sign",
+			toComplete: "--who=M",
+			expected: manager.Completion{
+				Values:    []string{"--who=World", "--who=Moon"},
+				Directive: manager.CompletionDirectiveNoFileComp,
+			},
+		},
+		{
+			name: "flag value without completion function",
+			args: []string{"goodbye", "-n"},
+		},
+		{
+			name:       "arguments with completion function",
+			args:       []string{"goodbye", "--name", "foo", "one"},
+			toComplete: "tw",
+			expected: manager.Completion{
+				Values:    []string{"foo:one:tw"},
+				Directive: manager.CompletionDirectiveNoSpace,
+			},
+		},
+		{
+			name: "valid arguments",
+			args: []string{"color", "red"},
+			expected: manager.Completion{
+				Values:    []string{"red", "green", "blue"},
+				Directive: manager.CompletionDirectiveNoFileComp,
+			},
+		},
+		{
+			name:       "valid arguments with prefix",
+			args:       []string{"color"},
+			toComplete: "g",
+			expected: manager.Completion{
+				Values:    []string{"green"},
+				Directive: manager.CompletionDirectiveNoFileComp,
+			},
+		},
+		{
+			name: "files",
+			args: []string{"legacy"},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			actual := complete(newTestPlugin(t), tc.args, tc.toComplete)
+			assert.Check(t, is.DeepEqual(actual, tc.expected))
+		})
+	}
+}
+
+func TestRegisterFlagCompletionFuncUnknownFlag(t *testing.T) {
+	err := RegisterFlagCompletionFunc(&cobra.Command{Use: "helloworld"}, "who", nil)
+	assert.Check(t, is.Error(err, `flag "who" does not exist`))
+}
@@ -0,0 +1,54 @@
+package plugin
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli-plugins/manager"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// HookFunc is invoked after the builtin commands the plugin hooks, as
+// declared by manager.Metadata.Hooks. Its output is displayed on the
+// standard error of the CLI. Hook functions which need to connect to the
+// daemon must call PersistentPreRunE first.
+type HookFunc func(event manager.HookEvent) error
+
+var hookFunc struct {
+	sync.Mutex
+	fn HookFunc
+}
+
+// RegisterHookFunc registers the function invoked after the builtin commands
+// the plugin hooks.
+func RegisterHookFunc(fn HookFunc) {
+	hookFunc.Lock()
+	defer hookFunc.Unlock()
+	hookFunc.fn = fn
+}
+
+func newHookSubcommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:    manager.HookSubcommandName + " EVENT",
+		Hidden: true,
+		Args:   cli.ExactArgs(1),
+		// Suppress the global/parent PersistentPreRunE, which needlessly initializes the client and tries to connect to the daemon.
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var event manager.HookEvent
+			if err := json.Unmarshal([]byte(args[0]), &event); err != nil {
+				return errors.Wrap(err, "invalid hook event")
+			}
+			hookFunc.Lock()
+			fn := hookFunc.fn
+			hookFunc.Unlock()
+			if fn == nil {
+				return nil
+			}
+			return fn(event)
+		},
+	}
+	return cmd
+}
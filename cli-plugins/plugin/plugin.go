@@ -131,6 +131,8 @@ func newPluginCommand(dockerCli *command.DockerCli, plugin *cobra.Command, meta
 	cmd.AddCommand(
 		plugin,
 		newMetadataSubcommand(plugin, meta),
+		newCompletionSubcommand(plugin),
+		newHookSubcommand(),
 	)
 
 	cli.DisableFlagsInUseLine(cmd)
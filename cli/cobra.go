@@ -38,9 +38,10 @@ func setupCommonRootCommand(rootCmd *cobra.Command) (*cliflags.ClientOptions, *p
 	rootCmd.SetUsageTemplate(usageTemplate)
 	rootCmd.SetHelpTemplate(helpTemplate)
 	rootCmd.SetFlagErrorFunc(FlagErrorFunc)
-	rootCmd.SetHelpCommand(helpCommand)
+	helpCmd := newHelpCommand()
+	rootCmd.SetHelpCommand(helpCmd)
 
-	return opts, flags, helpCommand
+	return opts, flags, helpCmd
 }
 
 // SetupRootCommand sets default usage, help, and error handling for the
@@ -103,21 +104,25 @@ func DisableFlagsInUseLine(cmd *cobra.Command) {
 	})
 }
 
-var helpCommand = &cobra.Command{
-	Use:               "help [command]",
-	Short:             "Help about the command",
-	PersistentPreRun:  func(cmd *cobra.Command, args []string) {},
-	PersistentPostRun: func(cmd *cobra.Command, args []string) {},
-	RunE: func(c *cobra.Command, args []string) error {
-		cmd, args, e := c.Root().Find(args)
-		if cmd == nil || e != nil || len(args) > 0 {
-			return errors.Errorf("unknown help topic: %v", strings.Join(args, " "))
-		}
+// newHelpCommand returns the help command of a root command. Each root
+// command has its own, as the help command is wrapped by the root command.
+func newHelpCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:               "help [command]",
+		Short:             "Help about the command",
+		PersistentPreRun:  func(cmd *cobra.Command, args []string) {},
+		PersistentPostRun: func(cmd *cobra.Command, args []string) {},
+		RunE: func(c *cobra.Command, args []string) error {
+			cmd, args, e := c.Root().Find(args)
+			if cmd == nil || e != nil || len(args) > 0 {
+				return errors.Errorf("unknown help topic: %v", strings.Join(args, " "))
+			}
 
-		helpFunc := cmd.HelpFunc()
-		helpFunc(cmd, args)
-		return nil
-	},
+			helpFunc := cmd.HelpFunc()
+			helpFunc(cmd, args)
+			return nil
+		},
+	}
 }
 
 func isPlugin(cmd *cobra.Command) bool {
@@ -2,7 +2,9 @@ package command
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"net/url"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -67,6 +69,22 @@ type Cli interface {
 	DockerEndpoint() docker.Endpoint
 }
 
+// ContextSourcer is implemented by the clients telling what selected their
+// current context. It is not part of Cli, so that the implementations of Cli
+// outside of this repository don't have to implement it.
+type ContextSourcer interface {
+	CurrentContextSource() string
+}
+
+// CurrentContextSource returns what selected the current context of the
+// client, or an empty string if the client doesn't tell it
+func CurrentContextSource(cli Cli) string {
+	if sourcer, ok := cli.(ContextSourcer); ok {
+		return sourcer.CurrentContextSource()
+	}
+	return ""
+}
+
 // DockerCli is an instance the docker command line client.
 // Instances of the client can be returned from NewDockerCli.
 type DockerCli struct {
@@ -81,6 +99,7 @@ type DockerCli struct {
 	newContainerizeClient func(string) (clitypes.ContainerizedClient, error)
 	contextStore          store.Store
 	currentContext        string
+	currentContextSource  string
 	dockerEndpoint        docker.Endpoint
 	contextStoreConfig    store.Config
 }
@@ -211,11 +230,11 @@ func (cli *DockerCli) Initialize(opts *cliflags.ClientOptions, ops ...Initialize
 
 	if cli.client == nil {
 		cli.contextStore = store.New(cliconfig.ContextStoreDir(), cli.contextStoreConfig)
-		cli.currentContext, err = resolveContextName(opts.Common, cli.configFile, cli.contextStore)
+		cli.currentContext, cli.currentContextSource, err = resolveContextName(opts.Common, cli.configFile, cli.contextStore)
 		if err != nil {
 			return err
 		}
-		endpoint, err := resolveDockerEndpoint(cli.contextStore, cli.currentContext, opts.Common)
+		endpoint, err := resolveDockerEndpoint(cli.contextStore, cli.currentContext, opts.Common, cli.configFile.ConnectionHelpers)
 		if err != nil {
 			return errors.Wrap(err, "unable to resolve docker endpoint")
 		}
@@ -254,11 +273,11 @@ func (cli *DockerCli) Initialize(opts *cliflags.ClientOptions, ops ...Initialize
 // NewAPIClientFromFlags creates a new APIClient from command line flags
 func NewAPIClientFromFlags(opts *cliflags.CommonOptions, configFile *configfile.ConfigFile) (client.APIClient, error) {
 	store := store.New(cliconfig.ContextStoreDir(), defaultContextStoreConfig())
-	contextName, err := resolveContextName(opts, configFile, store)
+	contextName, _, err := resolveContextName(opts, configFile, store)
 	if err != nil {
 		return nil, err
 	}
-	endpoint, err := resolveDockerEndpoint(store, contextName, opts)
+	endpoint, err := resolveDockerEndpoint(store, contextName, opts, configFile.ConnectionHelpers)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to resolve docker endpoint")
 	}
@@ -279,7 +298,7 @@ func newAPIClientFromEndpoint(ep docker.Endpoint, configFile *configfile.ConfigF
 	return client.NewClientWithOpts(clientOpts...)
 }
 
-func resolveDockerEndpoint(s store.Store, contextName string, opts *cliflags.CommonOptions) (docker.Endpoint, error) {
+func resolveDockerEndpoint(s store.Store, contextName string, opts *cliflags.CommonOptions, connectionHelpers map[string]string) (docker.Endpoint, error) {
 	if contextName != "" {
 		ctxMeta, err := s.GetContextMetadata(contextName)
 		if err != nil {
@@ -289,9 +308,16 @@ func resolveDockerEndpoint(s store.Store, contextName string, opts *cliflags.Com
 		if err != nil {
 			return docker.Endpoint{}, err
 		}
-		return docker.WithTLSData(s, contextName, epMeta)
+		conn, err := docker.ConnectionFromContext(ctxMeta)
+		if err != nil {
+			return docker.Endpoint{}, err
+		}
+		ep, err := docker.WithTLSData(s, contextName, epMeta)
+		ep.Connection = conn
+		ep.ConnectionHelpers = connectionHelpers
+		return ep, err
 	}
-	host, err := getServerHost(opts.Hosts, opts.TLSOptions)
+	host, err := getServerHost(opts.Hosts, opts.TLSOptions, connectionHelpers)
 	if err != nil {
 		return docker.Endpoint{}, err
 	}
@@ -314,7 +340,8 @@ func resolveDockerEndpoint(s store.Store, contextName string, opts *cliflags.Com
 			Host:          host,
 			SkipTLSVerify: skipTLSVerify,
 		},
-		TLSData: tlsData,
+		TLSData:           tlsData,
+		ConnectionHelpers: connectionHelpers,
 	}, nil
 }
 
@@ -333,7 +360,7 @@ func (cli *DockerCli) initializeFromClient() {
 	ping, err := cli.client.Ping(context.Background())
 	if err != nil {
 		// Default to true if we fail to connect to daemon
-		cli.serverInfo = ServerInfo{HasExperimental: true}
+		cli.serverInfo = ServerInfo{HasExperimental: true, APIVersion: ping.APIVersion}
 
 		if ping.APIVersion != "" {
 			cli.client.NegotiateAPIVersionPing(ping)
@@ -345,6 +372,7 @@ func (cli *DockerCli) initializeFromClient() {
 		HasExperimental: ping.Experimental,
 		OSType:          ping.OSType,
 		BuildkitVersion: ping.BuilderVersion,
+		APIVersion:      ping.APIVersion,
 	}
 	cli.client.NegotiateAPIVersionPing(ping)
 }
@@ -383,6 +411,12 @@ func (cli *DockerCli) CurrentContext() string {
 	return cli.currentContext
 }
 
+// CurrentContextSource returns what selected the current context, such as
+// "the DOCKER_CONTEXT environment variable"
+func (cli *DockerCli) CurrentContextSource() string {
+	return cli.currentContextSource
+}
+
 // StackOrchestrator resolves which stack orchestrator is in use
 func (cli *DockerCli) StackOrchestrator(flagValue string) (Orchestrator, error) {
 	var ctxOrchestrator string
@@ -440,6 +474,8 @@ type ServerInfo struct {
 	HasExperimental bool
 	OSType          string
 	BuildkitVersion types.BuilderVersion
+	// APIVersion is the API version of the daemon, empty if it is unknown
+	APIVersion string
 }
 
 // ClientInfo stores details about the supported features of the client
@@ -477,7 +513,7 @@ func NewDockerCli(ops ...DockerCliOption) (*DockerCli, error) {
 	return cli, nil
 }
 
-func getServerHost(hosts []string, tlsOptions *tlsconfig.Options) (string, error) {
+func getServerHost(hosts []string, tlsOptions *tlsconfig.Options, connectionHelpers map[string]string) (string, error) {
 	var host string
 	switch len(hosts) {
 	case 0:
@@ -488,6 +524,11 @@ func getServerHost(hosts []string, tlsOptions *tlsconfig.Options) (string, error
 		return "", errors.New("Please specify only one -H")
 	}
 
+	// hosts whose scheme has a connection helper are handled by the helper
+	if u, err := url.Parse(host); err == nil && connectionHelpers[u.Scheme] != "" {
+		return host, nil
+	}
+
 	return dopts.ParseHost(tlsOptions != nil, host)
 }
 
@@ -502,38 +543,69 @@ func UserAgent() string {
 // - if --host flag or DOCKER_HOST is set, fallbacks to use the same logic as before context-store was added
 // for backward compatibility with existing scripts
 // - if DOCKER_CONTEXT is set, use this value
+// - if the working directory or one of its parents has a .docker-context file,
+// or is mapped to a context by the "ProjectContexts" of the config file, use
+// the context of the nearest one
 // - if Config file has a globally set "CurrentContext", use this value
 // - fallbacks to default HOST, uses TLS config from flags/env vars
-func resolveContextName(opts *cliflags.CommonOptions, config *configfile.ConfigFile, contextstore store.Store) (string, error) {
+//
+// It also returns what selected the context.
+func resolveContextName(opts *cliflags.CommonOptions, config *configfile.ConfigFile, contextstore store.Store) (string, string, error) {
+	if ctxName, source, err := ContextNameFromOptions(opts); err != nil || source != "" {
+		return ctxName, source, err
+	}
+	ctxName, source, err := findProjectContext(config)
+	if err != nil {
+		return "", "", err
+	}
+	if source != "" {
+		if ctxName == "default" {
+			return "", source, nil
+		}
+		_, err := contextstore.GetContextMetadata(ctxName)
+		if store.IsErrContextDoesNotExist(err) {
+			return "", "", errors.Errorf("Context %q set by %s is not found", ctxName, source)
+		}
+		return ctxName, source, err
+	}
+	if config != nil && config.CurrentContext != "" {
+		_, err := contextstore.GetContextMetadata(config.CurrentContext)
+		if store.IsErrContextDoesNotExist(err) {
+			return "", "", errors.Errorf("Current context %q is not found on the file system, please check your config file at %s", config.CurrentContext, config.Filename)
+		}
+		return config.CurrentContext, fmt.Sprintf("the currentContext of %s", config.Filename), err
+	}
+	return "", "", nil
+}
+
+// ContextNameFromOptions returns the context selected by the --context or
+// --host flags, or by the DOCKER_HOST or DOCKER_CONTEXT environment variables,
+// and what selected it. The context is empty if the host is selected instead,
+// and the source is empty if none of them is set.
+func ContextNameFromOptions(opts *cliflags.CommonOptions) (string, string, error) {
 	if opts.Context != "" && len(opts.Hosts) > 0 {
-		return "", errors.New("Conflicting options: either specify --host or --context, not both")
+		return "", "", errors.New("Conflicting options: either specify --host or --context, not both")
 	}
 	if opts.Context != "" {
-		return opts.Context, nil
+		return opts.Context, "the --context flag", nil
 	}
 	if len(opts.Hosts) > 0 {
-		return "", nil
+		return "", "the --host flag", nil
 	}
 	if _, present := os.LookupEnv("DOCKER_HOST"); present {
-		return "", nil
+		return "", "the DOCKER_HOST environment variable", nil
 	}
 	if ctxName, ok := os.LookupEnv("DOCKER_CONTEXT"); ok {
-		return ctxName, nil
-	}
-	if config != nil && config.CurrentContext != "" {
-		_, err := contextstore.GetContextMetadata(config.CurrentContext)
-		if store.IsErrContextDoesNotExist(err) {
-			return "", errors.Errorf("Current context %q is not found on the file system, please check your config file at %s", config.CurrentContext, config.Filename)
-		}
-		return config.CurrentContext, err
+		return ctxName, "the DOCKER_CONTEXT environment variable", nil
 	}
-	return "", nil
+	return "", "", nil
 }
 
 func defaultContextStoreConfig() store.Config {
 	return store.NewConfig(
 		func() interface{} { return &DockerContext{} },
 		store.EndpointTypeGetter(docker.DockerEndpoint, func() interface{} { return &docker.EndpointMeta{} }),
+		store.EndpointTypeGetter(docker.ConnectionEndpoint, func() interface{} { return &docker.ConnectionMeta{} }),
 		store.EndpointTypeGetter(kubcontext.KubernetesEndpoint, func() interface{} { return &kubcontext.EndpointMeta{} }),
 	)
 }
@@ -47,6 +47,19 @@ func TestNewAPIClientFromFlags(t *testing.T) {
 	assert.Check(t, is.Equal(api.DefaultVersion, apiclient.ClientVersion()))
 }
 
+func TestNewAPIClientFromFlagsWithConnectionHelper(t *testing.T) {
+	opts := &flags.CommonOptions{Hosts: []string{"dind://builder"}}
+	_, err := NewAPIClientFromFlags(opts, &configfile.ConfigFile{})
+	assert.Check(t, is.ErrorContains(err, "Invalid bind address format: dind://builder"))
+
+	configFile := &configfile.ConfigFile{
+		ConnectionHelpers: map[string]string{"dind": "docker-connection-dind"},
+	}
+	apiclient, err := NewAPIClientFromFlags(opts, configFile)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal("http://docker", apiclient.DaemonHost()))
+}
+
 func TestNewAPIClientFromFlagsForDefaultSchema(t *testing.T) {
 	host := ":2375"
 	opts := &flags.CommonOptions{Hosts: []string{host}}
@@ -112,7 +125,7 @@ func TestInitializeFromClient(t *testing.T) {
 			pingFunc: func() (types.Ping, error) {
 				return types.Ping{Experimental: true, OSType: "linux", APIVersion: "v1.30"}, nil
 			},
-			expectedServer: ServerInfo{HasExperimental: true, OSType: "linux"},
+			expectedServer: ServerInfo{HasExperimental: true, OSType: "linux", APIVersion: "v1.30"},
 			negotiated:     true,
 		},
 		{
@@ -127,7 +140,7 @@ func TestInitializeFromClient(t *testing.T) {
 			pingFunc: func() (types.Ping, error) {
 				return types.Ping{APIVersion: "v1.33"}, errors.New("failed")
 			},
-			expectedServer: ServerInfo{HasExperimental: true},
+			expectedServer: ServerInfo{HasExperimental: true, APIVersion: "v1.33"},
 			negotiated:     true,
 		},
 	}
@@ -0,0 +1,29 @@
+package cliplugin
+
+import (
+	"context"
+
+	registryclient "github.com/docker/cli/cli/registry/client"
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/reference"
+)
+
+type fakeRegistryClient struct {
+	registryclient.ContentClient
+	getRawManifestFunc func(ctx context.Context, ref reference.Named) (distribution.Manifest, error)
+	getBlobFunc        func(ctx context.Context, ref reference.Canonical) ([]byte, error)
+}
+
+func (c *fakeRegistryClient) GetRawManifest(ctx context.Context, ref reference.Named) (distribution.Manifest, error) {
+	if c.getRawManifestFunc != nil {
+		return c.getRawManifestFunc(ctx, ref)
+	}
+	return nil, nil
+}
+
+func (c *fakeRegistryClient) GetBlob(ctx context.Context, ref reference.Canonical) ([]byte, error) {
+	if c.getBlobFunc != nil {
+		return c.getBlobFunc(ctx, ref)
+	}
+	return nil, nil
+}
@@ -0,0 +1,26 @@
+package cliplugin
+
+import (
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/spf13/cobra"
+)
+
+// NewCLIPluginCommand returns a cobra command for `cli-plugin` subcommands
+func NewCLIPluginCommand(dockerCli command.Cli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cli-plugin",
+		Short: "Manage CLI plugins",
+		Args:  cli.NoArgs,
+		RunE:  command.ShowHelp(dockerCli.Err()),
+	}
+	cmd.AddCommand(
+		newInitCommand(dockerCli),
+		newInstallCommand(dockerCli),
+		newListCommand(dockerCli),
+		newRemoveCommand(dockerCli),
+		newSignCommand(dockerCli),
+		newUpdateCommand(dockerCli),
+	)
+	return cmd
+}
@@ -0,0 +1,128 @@
+package cliplugin
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+	"strings"
+
+	"github.com/containerd/containerd/platforms"
+	pluginmanager "github.com/docker/cli/cli-plugins/manager"
+	registryclient "github.com/docker/cli/cli/registry/client"
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/manifest/manifestlist"
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/docker/distribution/reference"
+	digest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+// pluginArtifact is a plugin fetched from a registry, for the platform of
+// the CLI
+type pluginArtifact struct {
+	// digest is the digest of the manifest, or manifest list, the plugin
+	// was fetched from
+	digest   digest.Digest
+	metadata pluginmanager.Metadata
+	binary   []byte
+}
+
+// parsePluginReference normalizes the reference of a plugin, and returns
+// the name of the plugin, which is the last component of the repository
+// path, without the "docker-" prefix
+func parsePluginReference(ref string) (reference.Named, string, error) {
+	namedRef, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return nil, "", err
+	}
+	name := strings.TrimPrefix(path.Base(reference.Path(namedRef)), pluginmanager.NamePrefix)
+	return reference.TagNameOnly(namedRef), name, nil
+}
+
+// fetchPlugin fetches the plugin for the platform of the CLI. The reference
+// may be a manifest list, with a manifest per platform, or a single manifest.
+func fetchPlugin(ctx context.Context, rclient registryclient.ContentClient, ref reference.Named) (pluginArtifact, error) {
+	manifest, err := rclient.GetRawManifest(ctx, ref)
+	if err != nil {
+		return pluginArtifact{}, err
+	}
+	_, payload, err := manifest.Payload()
+	if err != nil {
+		return pluginArtifact{}, err
+	}
+	artifact := pluginArtifact{digest: digest.FromBytes(payload)}
+
+	if list, ok := manifest.(*manifestlist.DeserializedManifestList); ok {
+		desc, err := matchPlatform(list)
+		if err != nil {
+			return pluginArtifact{}, errors.Wrapf(err, "plugin %s", reference.FamiliarString(ref))
+		}
+		canonical, err := reference.WithDigest(reference.TrimNamed(ref), desc.Digest)
+		if err != nil {
+			return pluginArtifact{}, err
+		}
+		if manifest, err = rclient.GetRawManifest(ctx, canonical); err != nil {
+			return pluginArtifact{}, err
+		}
+	}
+	image, ok := manifest.(*schema2.DeserializedManifest)
+	if !ok || image.Config.MediaType != pluginmanager.ConfigMediaType {
+		return pluginArtifact{}, errors.Errorf("%s is not a CLI plugin", reference.FamiliarString(ref))
+	}
+	if len(image.Layers) != 1 || image.Layers[0].MediaType != pluginmanager.BinaryMediaType {
+		return pluginArtifact{}, errors.Errorf("invalid CLI plugin %s: it must have a single layer of type %s", reference.FamiliarString(ref), pluginmanager.BinaryMediaType)
+	}
+
+	config, err := fetchBlob(ctx, rclient, ref, image.Config)
+	if err != nil {
+		return pluginArtifact{}, err
+	}
+	if err := json.Unmarshal(config, &artifact.metadata); err != nil {
+		return pluginArtifact{}, errors.Wrapf(err, "invalid metadata for CLI plugin %s", reference.FamiliarString(ref))
+	}
+	if err := pluginmanager.ValidateMetadata(artifact.metadata); err != nil {
+		return pluginArtifact{}, errors.Wrapf(err, "invalid metadata for CLI plugin %s", reference.FamiliarString(ref))
+	}
+	if artifact.binary, err = fetchBlob(ctx, rclient, ref, image.Layers[0]); err != nil {
+		return pluginArtifact{}, err
+	}
+	return artifact, nil
+}
+
+// matchPlatform returns the manifest of the list which matches the platform
+// of the CLI
+func matchPlatform(list *manifestlist.DeserializedManifestList) (manifestlist.ManifestDescriptor, error) {
+	matcher := platforms.Default()
+	for _, desc := range list.Manifests {
+		if matcher.Match(ocispec.Platform{
+			OS:           desc.Platform.OS,
+			Architecture: desc.Platform.Architecture,
+			Variant:      desc.Platform.Variant,
+		}) {
+			return desc, nil
+		}
+	}
+	return manifestlist.ManifestDescriptor{}, errors.Errorf("no binary available for platform %s", platforms.DefaultString())
+}
+
+// fetchBlob fetches a blob of the plugin repository, and verifies its size
+// and digest
+func fetchBlob(ctx context.Context, rclient registryclient.ContentClient, ref reference.Named, desc distribution.Descriptor) ([]byte, error) {
+	canonical, err := reference.WithDigest(reference.TrimNamed(ref), desc.Digest)
+	if err != nil {
+		return nil, err
+	}
+	blob, err := rclient.GetBlob(ctx, canonical)
+	if err != nil {
+		return nil, err
+	}
+	verifier := desc.Digest.Verifier()
+	if _, err := verifier.Write(blob); err != nil {
+		return nil, err
+	}
+	if int64(len(blob)) != desc.Size || !verifier.Verified() {
+		return nil, errors.Errorf("verification failed for blob %s of %s", desc.Digest, reference.FamiliarString(ref))
+	}
+	return blob, nil
+}
@@ -0,0 +1,125 @@
+package cliplugin
+
+import (
+	pluginmanager "github.com/docker/cli/cli-plugins/manager"
+	"github.com/docker/cli/cli/command/formatter"
+)
+
+const (
+	defaultPluginTableFormat = "table {{.Name}}\t{{.Version}}\t{{.Source}}\t{{.Verification}}\t{{.Description}}"
+
+	versionHeader      = "VERSION"
+	vendorHeader       = "VENDOR"
+	sourceHeader       = "SOURCE"
+	pathHeader         = "PATH"
+	verificationHeader = "VERIFICATION"
+	errorHeader        = "ERROR"
+)
+
+// pluginEntry is a CLI plugin, along with the reference it was installed
+// from, if any
+type pluginEntry struct {
+	pluginmanager.Plugin
+	Source string
+}
+
+// NewFormat returns a Format for rendering using a CLI plugin Context
+func NewFormat(source string, quiet bool) formatter.Format {
+	switch source {
+	case formatter.TableFormatKey:
+		if quiet {
+			return `{{.Name}}`
+		}
+		return defaultPluginTableFormat
+	case formatter.RawFormatKey:
+		if quiet {
+			return `name: {{.Name}}`
+		}
+		return `name: {{.Name}}\nversion: {{.Version}}\nvendor: {{.Vendor}}\nsource: {{.Source}}\npath: {{.Path}}\nverification: {{.Verification}}\n`
+	}
+	return formatter.Format(source)
+}
+
+// FormatWrite writes the context
+func FormatWrite(ctx formatter.Context, plugins []pluginEntry) error {
+	render := func(format func(subContext formatter.SubContext) error) error {
+		for _, plugin := range plugins {
+			if err := format(&pluginContext{p: plugin}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	pluginCtx := pluginContext{}
+	pluginCtx.Header = formatter.SubHeaderContext{
+		"Name":         formatter.NameHeader,
+		"Version":      versionHeader,
+		"Vendor":       vendorHeader,
+		"Source":       sourceHeader,
+		"Path":         pathHeader,
+		"Verification": verificationHeader,
+		"Description":  formatter.DescriptionHeader,
+		"Error":        errorHeader,
+	}
+	return ctx.Write(&pluginCtx, render)
+}
+
+type pluginContext struct {
+	formatter.HeaderContext
+	p pluginEntry
+}
+
+func (c *pluginContext) MarshalJSON() ([]byte, error) {
+	return formatter.MarshalJSON(c)
+}
+
+func (c *pluginContext) Name() string {
+	return c.p.Name
+}
+
+func (c *pluginContext) Version() string {
+	return c.p.Version
+}
+
+func (c *pluginContext) Vendor() string {
+	return c.p.Vendor
+}
+
+func (c *pluginContext) Source() string {
+	return c.p.Source
+}
+
+func (c *pluginContext) Path() string {
+	return c.p.Path
+}
+
+// Verification returns how the binary of the plugin was verified, or
+// "unverified"
+func (c *pluginContext) Verification() string {
+	if c.p.Verification == "" {
+		return "unverified"
+	}
+	return c.p.Verification
+}
+
+// Description returns the short description of the plugin, or the reason why
+// it is invalid or incompatible
+func (c *pluginContext) Description() string {
+	if pluginmanager.IsIncompatible(c.p.Err) {
+		return "Incompatible plugin: " + c.p.Err.Error()
+	}
+	if pluginmanager.IsUnverified(c.p.Err) {
+		return "Unverified plugin: " + c.p.Err.Error()
+	}
+	if c.p.Err != nil {
+		return "Invalid plugin: " + c.p.Err.Error()
+	}
+	return c.p.ShortDescription
+}
+
+func (c *pluginContext) Error() string {
+	if c.p.Err == nil {
+		return ""
+	}
+	return c.p.Err.Error()
+}
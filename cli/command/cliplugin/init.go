@@ -0,0 +1,97 @@
+package cliplugin
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/docker/cli/cli"
+	pluginmanager "github.com/docker/cli/cli-plugins/manager"
+	"github.com/docker/cli/cli/command"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type initOptions struct {
+	name   string
+	dir    string
+	module string
+	vendor string
+}
+
+func newInitCommand(dockerCli command.Cli) *cobra.Command {
+	var options initOptions
+
+	cmd := &cobra.Command{
+		Use:   "init [OPTIONS] NAME",
+		Short: "Generate the source code of a new CLI plugin",
+		Args:  cli.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			options.name = args[0]
+			return runInit(dockerCli, cmd.Root(), options)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&options.dir, "dir", "", "Directory to generate the plugin in (default \"docker-NAME\")")
+	flags.StringVar(&options.module, "module", "", "Go import path of the plugin (default \"docker-NAME\")")
+	flags.StringVar(&options.vendor, "vendor", "Example", "Vendor of the plugin, as reported in its metadata")
+
+	return cmd
+}
+
+func runInit(dockerCli command.Cli, rootcmd *cobra.Command, options initOptions) error {
+	if err := pluginmanager.ValidateName(options.name, rootcmd); err != nil {
+		return err
+	}
+	if options.dir == "" {
+		options.dir = pluginmanager.NamePrefix + options.name
+	}
+	if options.module == "" {
+		options.module = pluginmanager.NamePrefix + options.name
+	}
+	if options.vendor == "" {
+		return errors.New("the vendor of the plugin must not be empty")
+	}
+	if err := checkScaffoldDir(options.dir); err != nil {
+		return err
+	}
+
+	files, err := renderScaffold(scaffoldData{
+		Name:   options.name,
+		Binary: pluginmanager.NamePrefix + options.name,
+		Module: options.module,
+		Vendor: options.vendor,
+	})
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		path := filepath.Join(options.dir, filepath.FromSlash(f.path))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(path, f.content, 0644); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(dockerCli.Out(), "Generated CLI plugin %s in %s\n", options.name, options.dir)
+	fmt.Fprintf(dockerCli.Out(), "Run \"make -C %s install\" to build and install it\n", options.dir)
+	return nil
+}
+
+// checkScaffoldDir makes sure that generating a plugin in dir doesn't
+// overwrite anything.
+func checkScaffoldDir(dir string) error {
+	entries, err := ioutil.ReadDir(dir)
+	switch {
+	case os.IsNotExist(err):
+		return nil
+	case err != nil:
+		return err
+	case len(entries) > 0:
+		return errors.Errorf("directory %s is not empty", dir)
+	}
+	return nil
+}
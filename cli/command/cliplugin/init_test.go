@@ -0,0 +1,77 @@
+package cliplugin
+
+import (
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/cli/internal/test"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+	"gotest.tools/fs"
+	"gotest.tools/golden"
+)
+
+func TestInit(t *testing.T) {
+	dir := fs.NewDir(t, "plugin-init")
+	defer dir.Remove()
+	pluginDir := filepath.Join(dir.Path(), "hello")
+
+	cli := test.NewFakeCli(nil)
+	cmd := newInitCommand(cli)
+	cmd.SetArgs([]string{"--dir", pluginDir, "--module", "example.com/docker-hello", "--vendor", "Example \"Corp\"", "hello"})
+	assert.NilError(t, cmd.Execute())
+	assert.Check(t, is.Contains(cli.OutBuffer().String(), "Generated CLI plugin hello in "+pluginDir))
+
+	for _, name := range []string{"main.go", "Makefile"} {
+		content, err := ioutil.ReadFile(filepath.Join(pluginDir, name))
+		assert.NilError(t, err)
+		golden.Assert(t, string(content), "init-"+name+".golden")
+	}
+	for _, name := range []string{"testdata/root-default.golden", "testdata/root-who.golden", ".gitignore"} {
+		assert.Check(t, fileExists(filepath.Join(pluginDir, filepath.FromSlash(name))), name)
+	}
+	// the Go files are valid
+	for _, name := range []string{"main.go", "cmd.go", "cmd_test.go"} {
+		_, err := parser.ParseFile(token.NewFileSet(), filepath.Join(pluginDir, name), nil, parser.AllErrors)
+		assert.Check(t, err, name)
+	}
+}
+
+func TestInitErrors(t *testing.T) {
+	dir := fs.NewDir(t, "plugin-init", fs.WithFile("main.go", "package main\n"))
+	defer dir.Remove()
+
+	testCases := []struct {
+		name          string
+		args          []string
+		expectedError string
+	}{
+		{
+			name:          "invalid-name",
+			args:          []string{"--dir", filepath.Join(dir.Path(), "new"), "Hello"},
+			expectedError: `plugin candidate "Hello" did not match`,
+		},
+		{
+			name:          "not-empty",
+			args:          []string{"--dir", dir.Path(), "hello"},
+			expectedError: "directory " + dir.Path() + " is not empty",
+		},
+		{
+			name:          "no-vendor",
+			args:          []string{"--dir", filepath.Join(dir.Path(), "new"), "--vendor", "", "hello"},
+			expectedError: "the vendor of the plugin must not be empty",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := newInitCommand(test.NewFakeCli(nil))
+			cmd.SetArgs(tc.args)
+			cmd.SetOutput(ioutil.Discard)
+			assert.ErrorContains(t, cmd.Execute(), tc.expectedError)
+		})
+	}
+	assert.Check(t, !fileExists(filepath.Join(dir.Path(), "new")))
+}
@@ -0,0 +1,87 @@
+package cliplugin
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/docker/cli/cli"
+	pluginmanager "github.com/docker/cli/cli-plugins/manager"
+	"github.com/docker/cli/cli/command"
+	registryclient "github.com/docker/cli/cli/registry/client"
+	"github.com/docker/distribution/reference"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type installOptions struct {
+	plugin   string
+	insecure bool
+}
+
+func newInstallCommand(dockerCli command.Cli) *cobra.Command {
+	var options installOptions
+
+	cmd := &cobra.Command{
+		Use:   "install [OPTIONS] PLUGIN[:TAG|@DIGEST]",
+		Short: "Install a CLI plugin from a registry",
+		Args:  cli.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			options.plugin = args[0]
+			return runInstall(dockerCli, cmd.Root(), options)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVar(&options.insecure, "insecure", false, "Allow communication with an insecure registry")
+
+	return cmd
+}
+
+func runInstall(dockerCli command.Cli, rootcmd *cobra.Command, options installOptions) error {
+	ref, name, err := parsePluginReference(options.plugin)
+	if err != nil {
+		return err
+	}
+	if err := pluginmanager.ValidateName(name, rootcmd); err != nil {
+		return err
+	}
+	if _, err := pluginmanager.GetInstallRecord(name); err == nil {
+		return errors.Errorf("CLI plugin %s is already installed, use \"docker cli-plugin update\" to update it", name)
+	} else if !pluginmanager.IsNotFound(err) {
+		return err
+	}
+	if fileExists(pluginmanager.UserPluginPath(name)) {
+		return errors.Errorf("CLI plugin %s is already installed in %s", name, pluginmanager.UserPluginDir())
+	}
+
+	rclient, err := registryclient.AsContentClient(dockerCli.RegistryClient(options.insecure))
+	if err != nil {
+		return err
+	}
+	artifact, err := fetchPlugin(context.Background(), rclient, ref)
+	if err != nil {
+		return err
+	}
+	return installPlugin(dockerCli, name, ref, artifact)
+}
+
+func installPlugin(dockerCli command.Cli, name string, ref reference.Named, artifact pluginArtifact) error {
+	record := pluginmanager.InstallRecord{
+		Name:    name,
+		Source:  reference.FamiliarString(ref),
+		Digest:  artifact.digest,
+		Version: artifact.metadata.Version,
+	}
+	path, err := pluginmanager.InstallPlugin(record, artifact.binary)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(dockerCli.Out(), "Installed %s %s to %s\n", name, record.Version, path)
+	return nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
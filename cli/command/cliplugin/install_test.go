@@ -0,0 +1,219 @@
+package cliplugin
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"runtime"
+	"testing"
+
+	pluginmanager "github.com/docker/cli/cli-plugins/manager"
+	"github.com/docker/cli/cli/config"
+	"github.com/docker/cli/internal/test"
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/manifest/manifestlist"
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/docker/distribution/reference"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+	"gotest.tools/fs"
+)
+
+// fakePluginRegistry serves plugins packaged as manifest lists, with a
+// manifest for the platform of the test
+type fakePluginRegistry struct {
+	t         *testing.T
+	manifests map[string]distribution.Manifest
+	blobs     map[digest.Digest][]byte
+}
+
+func newFakePluginRegistry(t *testing.T) *fakePluginRegistry {
+	return &fakePluginRegistry{
+		t:         t,
+		manifests: map[string]distribution.Manifest{},
+		blobs:     map[digest.Digest][]byte{},
+	}
+}
+
+func (r *fakePluginRegistry) addBlob(mediaType string, blob []byte) distribution.Descriptor {
+	dgst := digest.FromBytes(blob)
+	r.blobs[dgst] = blob
+	return distribution.Descriptor{MediaType: mediaType, Digest: dgst, Size: int64(len(blob))}
+}
+
+// push adds a plugin to the registry, and returns the digest of its manifest
+// list
+func (r *fakePluginRegistry) push(ref string, version string, binary string) digest.Digest {
+	namedRef, err := reference.ParseNormalizedNamed(ref)
+	assert.NilError(r.t, err)
+	metadata, err := json.Marshal(pluginmanager.Metadata{SchemaVersion: "0.1.0", Vendor: "Example", Version: version})
+	assert.NilError(r.t, err)
+
+	manifest, err := schema2.FromStruct(schema2.Manifest{
+		Versioned: schema2.SchemaVersion,
+		Config:    r.addBlob(pluginmanager.ConfigMediaType, metadata),
+		Layers:    []distribution.Descriptor{r.addBlob(pluginmanager.BinaryMediaType, []byte(binary))},
+	})
+	assert.NilError(r.t, err)
+	_, payload, err := manifest.Payload()
+	assert.NilError(r.t, err)
+	canonical, err := reference.WithDigest(reference.TrimNamed(namedRef), digest.FromBytes(payload))
+	assert.NilError(r.t, err)
+	r.manifests[canonical.String()] = manifest
+
+	list, err := manifestlist.FromDescriptors([]manifestlist.ManifestDescriptor{
+		{
+			Descriptor: distribution.Descriptor{MediaType: schema2.MediaTypeManifest, Digest: canonical.Digest(), Size: int64(len(payload))},
+			Platform:   manifestlist.PlatformSpec{OS: "plan9", Architecture: "386"},
+		},
+		{
+			Descriptor: distribution.Descriptor{MediaType: schema2.MediaTypeManifest, Digest: canonical.Digest(), Size: int64(len(payload))},
+			Platform:   manifestlist.PlatformSpec{OS: runtime.GOOS, Architecture: runtime.GOARCH},
+		},
+	})
+	assert.NilError(r.t, err)
+	r.manifests[reference.TagNameOnly(namedRef).String()] = list
+	_, payload, err = list.Payload()
+	assert.NilError(r.t, err)
+	return digest.FromBytes(payload)
+}
+
+func (r *fakePluginRegistry) client() *fakeRegistryClient {
+	return &fakeRegistryClient{
+		getRawManifestFunc: func(_ context.Context, ref reference.Named) (distribution.Manifest, error) {
+			if manifest, ok := r.manifests[ref.String()]; ok {
+				return manifest, nil
+			}
+			return nil, errors.Errorf("manifest unknown: %s", ref)
+		},
+		getBlobFunc: func(_ context.Context, ref reference.Canonical) ([]byte, error) {
+			if blob, ok := r.blobs[ref.Digest()]; ok {
+				return blob, nil
+			}
+			return nil, errors.Errorf("blob unknown: %s", ref)
+		},
+	}
+}
+
+func setupPluginDir(t *testing.T) func() {
+	dir := fs.NewDir(t, t.Name())
+	configDir := config.Dir()
+	config.SetDir(dir.Path())
+	return func() {
+		config.SetDir(configDir)
+		dir.Remove()
+	}
+}
+
+func TestInstall(t *testing.T) {
+	defer setupPluginDir(t)()
+	registry := newFakePluginRegistry(t)
+	dgst := registry.push("example.com/plugins/docker-hello:1.0", "1.0.0", "hello binary")
+
+	cli := test.NewFakeCli(nil)
+	cli.SetRegistryClient(registry.client())
+	cmd := newInstallCommand(cli)
+	cmd.SetArgs([]string{"example.com/plugins/docker-hello:1.0"})
+	assert.NilError(t, cmd.Execute())
+
+	path := pluginmanager.UserPluginPath("hello")
+	assert.Check(t, is.Equal("Installed hello 1.0.0 to "+path+"\n", cli.OutBuffer().String()))
+	binary, err := ioutil.ReadFile(path)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal("hello binary", string(binary)))
+
+	record, err := pluginmanager.GetInstallRecord("hello")
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(pluginmanager.InstallRecord{
+		Name:    "hello",
+		Source:  "example.com/plugins/docker-hello:1.0",
+		Digest:  dgst,
+		Version: "1.0.0",
+	}, record))
+
+	cmd = newInstallCommand(cli)
+	cmd.SetArgs([]string{"example.com/plugins/docker-hello:1.0"})
+	cmd.SetOutput(ioutil.Discard)
+	assert.ErrorContains(t, cmd.Execute(), `CLI plugin hello is already installed, use "docker cli-plugin update"`)
+}
+
+func TestInstallErrors(t *testing.T) {
+	defer setupPluginDir(t)()
+	registry := newFakePluginRegistry(t)
+	registry.push("example.com/plugins/docker-hello:1.0", "1.0.0", "hello binary")
+	image, err := schema2.FromStruct(schema2.Manifest{
+		Versioned: schema2.SchemaVersion,
+		Config:    registry.addBlob(schema2.MediaTypeImageConfig, []byte("{}")),
+	})
+	assert.NilError(t, err)
+	registry.manifests["example.com/image:latest"] = image
+
+	testCases := []struct {
+		args          []string
+		expectedError string
+	}{
+		{
+			args:          []string{},
+			expectedError: "requires exactly 1 argument",
+		},
+		{
+			args:          []string{"example.com/plugins/docker-hello:2.0"},
+			expectedError: "manifest unknown",
+		},
+		{
+			args:          []string{"example.com/plugins/docker-hello-world"},
+			expectedError: `plugin candidate "hello-world" did not match`,
+		},
+		{
+			args:          []string{"example.com/image"},
+			expectedError: "example.com/image:latest is not a CLI plugin",
+		},
+	}
+	for _, tc := range testCases {
+		cli := test.NewFakeCli(nil)
+		cli.SetRegistryClient(registry.client())
+		cmd := newInstallCommand(cli)
+		cmd.SetArgs(tc.args)
+		cmd.SetOutput(ioutil.Discard)
+		assert.ErrorContains(t, cmd.Execute(), tc.expectedError)
+	}
+}
+
+func TestInstallVerifiesDigests(t *testing.T) {
+	defer setupPluginDir(t)()
+	registry := newFakePluginRegistry(t)
+	registry.push("example.com/plugins/docker-hello:1.0", "1.0.0", "hello binary")
+	for dgst, blob := range registry.blobs {
+		if string(blob) == "hello binary" {
+			registry.blobs[dgst] = []byte("evil binary!")
+		}
+	}
+
+	cli := test.NewFakeCli(nil)
+	cli.SetRegistryClient(registry.client())
+	cmd := newInstallCommand(cli)
+	cmd.SetArgs([]string{"example.com/plugins/docker-hello:1.0"})
+	cmd.SetOutput(ioutil.Discard)
+	assert.ErrorContains(t, cmd.Execute(), "verification failed for blob")
+	assert.Check(t, !fileExists(pluginmanager.UserPluginPath("hello")))
+}
+
+func TestParsePluginReference(t *testing.T) {
+	testCases := []struct {
+		ref          string
+		expectedRef  string
+		expectedName string
+	}{
+		{ref: "example.com/plugins/docker-hello", expectedRef: "example.com/plugins/docker-hello:latest", expectedName: "hello"},
+		{ref: "example.com/plugins/hello:1.0", expectedRef: "example.com/plugins/hello:1.0", expectedName: "hello"},
+		{ref: "docker/scan", expectedRef: "docker.io/docker/scan:latest", expectedName: "scan"},
+	}
+	for _, tc := range testCases {
+		ref, name, err := parsePluginReference(tc.ref)
+		assert.NilError(t, err)
+		assert.Check(t, is.Equal(tc.expectedRef, ref.String()))
+		assert.Check(t, is.Equal(tc.expectedName, name))
+	}
+}
@@ -0,0 +1,81 @@
+package cliplugin
+
+import (
+	"sort"
+
+	"github.com/docker/cli/cli"
+	pluginmanager "github.com/docker/cli/cli-plugins/manager"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/command/formatter"
+	"github.com/spf13/cobra"
+)
+
+type listOptions struct {
+	quiet  bool
+	format string
+}
+
+func newListCommand(dockerCli command.Cli) *cobra.Command {
+	var options listOptions
+
+	cmd := &cobra.Command{
+		Use:     "ls [OPTIONS]",
+		Short:   "List CLI plugins",
+		Aliases: []string{"list"},
+		Args:    cli.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runList(dockerCli, cmd.Root(), options)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVarP(&options.quiet, "quiet", "q", false, "Only display plugin names")
+	flags.StringVar(&options.format, "format", "", "Pretty-print plugins using a Go template")
+
+	return cmd
+}
+
+func runList(dockerCli command.Cli, rootcmd *cobra.Command, options listOptions) error {
+	plugins, err := pluginmanager.ListPlugins(dockerCli, rootcmd)
+	if err != nil {
+		return err
+	}
+	sort.Slice(plugins, func(i, j int) bool {
+		return plugins[i].Name < plugins[j].Name
+	})
+
+	records, err := pluginmanager.ListInstallRecords()
+	if err != nil {
+		return err
+	}
+	sources := make(map[string]string, len(records))
+	for _, record := range records {
+		sources[record.Name] = record.Source
+	}
+
+	entries := make([]pluginEntry, 0, len(plugins))
+	for _, p := range plugins {
+		entry := pluginEntry{Plugin: p}
+		// a plugin installed from a registry may be shadowed by another
+		// plugin with the same name
+		if p.Path == pluginmanager.UserPluginPath(p.Name) {
+			entry.Source = sources[p.Name]
+		}
+		// plugins are only verified when listed if the policy doesn't
+		// require it
+		if entry.Verification == "" && !pluginmanager.IsUnverified(p.Err) {
+			entry.Verification, _ = pluginmanager.VerifyPlugin(dockerCli.ConfigFile(), p.Name, p.Path)
+		}
+		entries = append(entries, entry)
+	}
+
+	format := options.format
+	if len(format) == 0 {
+		format = formatter.TableFormatKey
+	}
+	pluginsCtx := formatter.Context{
+		Output: dockerCli.Out(),
+		Format: NewFormat(format, options.quiet),
+	}
+	return FormatWrite(pluginsCtx, entries)
+}
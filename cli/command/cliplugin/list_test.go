@@ -0,0 +1,63 @@
+package cliplugin
+
+import (
+	"io/ioutil"
+	"runtime"
+	"testing"
+
+	pluginmanager "github.com/docker/cli/cli-plugins/manager"
+	"github.com/docker/cli/internal/test"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+	"gotest.tools/golden"
+	"gotest.tools/skip"
+)
+
+const helloPlugin = `#!/bin/sh
+echo '{"SchemaVersion":"0.1.0","Vendor":"Example","Version":"1.0.0","ShortDescription":"Say hello"}'
+`
+
+const futurePlugin = `#!/bin/sh
+echo '{"SchemaVersion":"0.2.0","Vendor":"Example","Version":"2.0.0","ShortDescription":"From the future","Capabilities":{"MinPluginAPIVersion":"99.0"}}'
+`
+
+func TestList(t *testing.T) {
+	skip.If(t, runtime.GOOS == "windows", "plugins are shell scripts")
+	defer setupPluginDir(t)()
+	registry := newFakePluginRegistry(t)
+	registry.push("example.com/plugins/docker-hello:1.0", "1.0.0", helloPlugin)
+
+	cli := test.NewFakeCli(nil)
+	cli.SetRegistryClient(registry.client())
+	cmd := newInstallCommand(cli)
+	cmd.SetArgs([]string{"example.com/plugins/docker-hello:1.0"})
+	assert.NilError(t, cmd.Execute())
+	assert.NilError(t, ioutil.WriteFile(pluginmanager.UserPluginPath("invalid"), []byte("#!/bin/sh\necho '{}'\n"), 0755))
+	assert.NilError(t, ioutil.WriteFile(pluginmanager.UserPluginPath("future"), []byte(futurePlugin), 0755))
+
+	testCases := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "simple",
+			args: []string{},
+		},
+		{
+			name: "quiet",
+			args: []string{"-q"},
+		},
+		{
+			name: "format",
+			args: []string{"--format", "{{.Name}} {{.Vendor}} {{.Error}}"},
+		},
+	}
+	for _, tc := range testCases {
+		cli.OutBuffer().Reset()
+		cmd := newListCommand(cli)
+		cmd.SetArgs(tc.args)
+		assert.NilError(t, cmd.Execute())
+		golden.Assert(t, cli.OutBuffer().String(), "list-command-success."+tc.name+".golden")
+	}
+	assert.Check(t, is.Equal("", cli.ErrBuffer().String()))
+}
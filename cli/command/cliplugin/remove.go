@@ -0,0 +1,42 @@
+package cliplugin
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/docker/cli/cli"
+	pluginmanager "github.com/docker/cli/cli-plugins/manager"
+	"github.com/docker/cli/cli/command"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func newRemoveCommand(dockerCli command.Cli) *cobra.Command {
+	return &cobra.Command{
+		Use:     "rm PLUGIN [PLUGIN...]",
+		Short:   "Remove one or more CLI plugins",
+		Aliases: []string{"remove"},
+		Args:    cli.RequiresMinArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRemove(dockerCli, args)
+		},
+	}
+}
+
+func runRemove(dockerCli command.Cli, names []string) error {
+	var errs []string
+	for _, name := range names {
+		if err := pluginmanager.UninstallPlugin(name); err != nil {
+			if pluginmanager.IsNotFound(err) {
+				err = errors.Errorf("CLI plugin %s is not installed in %s", name, pluginmanager.UserPluginDir())
+			}
+			errs = append(errs, err.Error())
+			continue
+		}
+		fmt.Fprintln(dockerCli.Out(), name)
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package cliplugin
+
+import (
+	"io/ioutil"
+	"testing"
+
+	pluginmanager "github.com/docker/cli/cli-plugins/manager"
+	"github.com/docker/cli/internal/test"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+func TestRemove(t *testing.T) {
+	defer setupPluginDir(t)()
+	registry := newFakePluginRegistry(t)
+	registry.push("example.com/plugins/docker-hello", "1.0.0", "hello binary")
+
+	cli := test.NewFakeCli(nil)
+	cli.SetRegistryClient(registry.client())
+	cmd := newInstallCommand(cli)
+	cmd.SetArgs([]string{"example.com/plugins/docker-hello"})
+	assert.NilError(t, cmd.Execute())
+
+	cli.OutBuffer().Reset()
+	cmd = newRemoveCommand(cli)
+	cmd.SetArgs([]string{"hello", "world"})
+	cmd.SetOutput(ioutil.Discard)
+	assert.ErrorContains(t, cmd.Execute(), "CLI plugin world is not installed in "+pluginmanager.UserPluginDir())
+	assert.Check(t, is.Equal("hello\n", cli.OutBuffer().String()))
+	assert.Check(t, !fileExists(pluginmanager.UserPluginPath("hello")))
+
+	records, err := pluginmanager.ListInstallRecords()
+	assert.NilError(t, err)
+	assert.Check(t, is.Len(records, 0))
+}
@@ -0,0 +1,214 @@
+package cliplugin
+
+import (
+	"bytes"
+	"text/template"
+)
+
+// scaffoldData holds the values substituted in the files of a generated
+// plugin.
+type scaffoldData struct {
+	// Name is the name of the plugin, e.g. "hello".
+	Name string
+	// Binary is the name of the plugin binary, e.g. "docker-hello".
+	Binary string
+	// Module is the Go import path of the plugin.
+	Module string
+	// Vendor is the vendor of the plugin, as reported in its metadata.
+	Vendor string
+}
+
+type scaffoldFile struct {
+	// path is relative to the plugin directory, with forward slashes.
+	path    string
+	content []byte
+}
+
+// scaffoldTemplates are the templates of the files of a generated plugin.
+// The generated plugin builds on the plugin package, and its tests use a
+// CLI writing to buffers, in the way of internal/test.FakeCli.
+//
+// As the CLI isn't a Go module, the plugin is built in GOPATH mode, with the
+// packages of the CLI and their dependencies vendored from a checkout of the
+// CLI, so that it builds with the same dependencies.
+var scaffoldTemplates = []struct {
+	path     string
+	template string
+}{
+	{path: "main.go", template: scaffoldMain},
+	{path: "cmd.go", template: scaffoldCmd},
+	{path: "cmd_test.go", template: scaffoldCmdTest},
+	{path: "testdata/root-default.golden", template: "Hello World!\n"},
+	{path: "testdata/root-who.golden", template: "Hello Moon!\n"},
+	{path: "Makefile", template: scaffoldMakefile},
+	{path: ".gitignore", template: "/{{.Binary}}\n/.gopath\n"},
+}
+
+func renderScaffold(data scaffoldData) ([]scaffoldFile, error) {
+	files := make([]scaffoldFile, 0, len(scaffoldTemplates))
+	for _, t := range scaffoldTemplates {
+		tmpl, err := template.New(t.path).Parse(t.template)
+		if err != nil {
+			return nil, err
+		}
+		var b bytes.Buffer
+		if err := tmpl.Execute(&b, data); err != nil {
+			return nil, err
+		}
+		files = append(files, scaffoldFile{path: t.path, content: b.Bytes()})
+	}
+	return files, nil
+}
+
+const scaffoldMain = `package main
+
+import (
+	"github.com/docker/cli/cli-plugins/manager"
+	"github.com/docker/cli/cli-plugins/plugin"
+)
+
+func main() {
+	plugin.Run(newRootCommand, manager.Metadata{
+		SchemaVersion:    "0.1.0",
+		Vendor:           {{printf "%q" .Vendor}},
+		Version:          "0.1.0",
+		ShortDescription: "The {{.Name}} CLI plugin",
+	})
+}
+`
+
+const scaffoldCmd = `package main
+
+import (
+	"fmt"
+
+	"github.com/docker/cli/cli-plugins/manager"
+	"github.com/docker/cli/cli-plugins/plugin"
+	"github.com/docker/cli/cli/command"
+	"github.com/spf13/cobra"
+)
+
+// newRootCommand returns the command tree of the plugin. The name of the
+// root command must be the name of the plugin.
+//
+// The CLI is initialized, and connects to the daemon, when a command is
+// run. Commands which set a PersistentPreRunE hook must call
+// plugin.PersistentPreRunE from it.
+func newRootCommand(dockerCli command.Cli) *cobra.Command {
+	var who string
+
+	cmd := &cobra.Command{
+		Use:   "{{.Name}}",
+		Short: "The {{.Name}} CLI plugin",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fmt.Fprintf(dockerCli.Out(), "Hello %s!\n", who)
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&who, "who", "World", "Who are we addressing?")
+	completeWho := func(_ *cobra.Command, _ []string, _ string) ([]string, manager.CompletionDirective) {
+		return []string{"World", "Moon"}, manager.CompletionDirectiveNoFileComp
+	}
+	if err := plugin.RegisterFlagCompletionFunc(cmd, "who", completeWho); err != nil {
+		panic(err)
+	}
+
+	return cmd
+}
+`
+
+const scaffoldCmdTest = `package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/docker/cli/cli/command"
+	"gotest.tools/assert"
+	"gotest.tools/golden"
+)
+
+// newFakeCli returns a CLI which reads its input from in and writes its
+// output to buffers. It doesn't connect to the daemon.
+func newFakeCli(t *testing.T, in string) (*command.DockerCli, *bytes.Buffer, *bytes.Buffer) {
+	t.Helper()
+	out := new(bytes.Buffer)
+	errOut := new(bytes.Buffer)
+	cli, err := command.NewDockerCli(
+		command.WithInputStream(ioutil.NopCloser(strings.NewReader(in))),
+		command.WithOutputStream(out),
+		command.WithErrorStream(errOut),
+	)
+	assert.NilError(t, err)
+	return cli, out, errOut
+}
+
+// The expected output of the commands is in testdata, run
+// "go test -test.update-golden" to update it.
+func TestRootCommand(t *testing.T) {
+	testCases := []struct {
+		name string
+		args []string
+	}{
+		{name: "default"},
+		{name: "who", args: []string{"--who", "Moon"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cli, out, _ := newFakeCli(t, "")
+			cmd := newRootCommand(cli)
+			cmd.SetArgs(tc.args)
+			cmd.SetOutput(ioutil.Discard)
+			assert.NilError(t, cmd.Execute())
+			golden.Assert(t, out.String(), "root-"+tc.name+".golden")
+		})
+	}
+}
+`
+
+const scaffoldMakefile = `# The checkout of github.com/docker/cli the plugin is built with
+CLI_SOURCE ?= $(shell go env GOPATH)/src/github.com/docker/cli
+PLUGIN_DIR ?= $(or $(DOCKER_CONFIG),$(HOME)/.docker)/cli-plugins
+
+# The plugin is built in GOPATH mode, in a GOPATH of its own
+GOPATH_DIR := $(CURDIR)/.gopath
+PACKAGE_DIR := $(GOPATH_DIR)/src/{{.Module}}
+GO := cd $(PACKAGE_DIR) && GOPATH=$(GOPATH_DIR) GO111MODULE=off go
+
+.PHONY: all
+all: build
+
+# The packages of the CLI and their dependencies are vendored from
+# CLI_SOURCE, remove the vendor directory to vendor them again.
+vendor:
+	@test -d $(CLI_SOURCE)/vendor || { echo "$(CLI_SOURCE) is not a checkout of github.com/docker/cli, set CLI_SOURCE"; exit 1; }
+	mkdir -p vendor.tmp/github.com/docker/cli
+	cp -R $(CLI_SOURCE)/vendor/. vendor.tmp/
+	tar -C $(CLI_SOURCE) --exclude ./vendor --exclude ./.git -cf - . | tar -C vendor.tmp/github.com/docker/cli -xf -
+	mv vendor.tmp vendor
+
+$(PACKAGE_DIR):
+	mkdir -p $(dir $(PACKAGE_DIR))
+	ln -s $(CURDIR) $(PACKAGE_DIR)
+
+.PHONY: build
+build: vendor $(PACKAGE_DIR) ## build the plugin
+	$(GO) build -o $(CURDIR)/{{.Binary}} .
+
+.PHONY: test
+test: vendor $(PACKAGE_DIR) ## run the tests
+	$(GO) test .
+
+.PHONY: install
+install: build ## install the plugin into the user plugin directory
+	mkdir -p $(PLUGIN_DIR)
+	install -m 0755 {{.Binary}} $(PLUGIN_DIR)/{{.Binary}}
+
+.PHONY: clean
+clean: ## remove the plugin binary
+	rm -rf {{.Binary}} .gopath
+`
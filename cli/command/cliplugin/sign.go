@@ -0,0 +1,91 @@
+package cliplugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/docker/cli/cli"
+	pluginmanager "github.com/docker/cli/cli-plugins/manager"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/trust"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/theupdateframework/notary/trustmanager"
+	"github.com/theupdateframework/notary/tuf/data"
+)
+
+type signOptions struct {
+	binary string
+	key    string
+}
+
+func newSignCommand(dockerCli command.Cli) *cobra.Command {
+	var options signOptions
+
+	cmd := &cobra.Command{
+		Use:   "sign [OPTIONS] BINARY",
+		Short: "Sign the binary of a CLI plugin",
+		Args:  cli.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			options.binary = args[0]
+			return runSign(dockerCli, options)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&options.key, "key", "", "Name of the signing key, as generated by \"docker trust key generate\"")
+
+	return cmd
+}
+
+func runSign(dockerCli command.Cli, options signOptions) error {
+	if options.key == "" {
+		return errors.New("the name of the signing key must be provided using the `--key` flag")
+	}
+	binary, err := os.Open(options.binary)
+	if err != nil {
+		return err
+	}
+	defer binary.Close()
+	dgst, err := digest.Canonical.FromReader(binary)
+	if err != nil {
+		return errors.Wrapf(err, "failed to hash %s", options.binary)
+	}
+	keyStore, err := trustmanager.NewKeyFileStore(trust.GetTrustDirectory(), trust.GetPassphraseRetriever(dockerCli.In(), dockerCli.Out()))
+	if err != nil {
+		return err
+	}
+	key, err := getSigningKey(keyStore, options.key)
+	if err != nil {
+		return err
+	}
+	sig, err := pluginmanager.SignPlugin(dgst, key)
+	if err != nil {
+		return errors.Wrapf(err, "failed to sign %s", options.binary)
+	}
+	sigBytes, err := json.Marshal(sig)
+	if err != nil {
+		return err
+	}
+	sigPath := options.binary + pluginmanager.SignatureSuffix
+	if err := ioutil.WriteFile(sigPath, sigBytes, 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(dockerCli.Out(), "Signed %s with key %s (%s) to %s\n", options.binary, options.key, key.ID(), sigPath)
+	return nil
+}
+
+// getSigningKey returns the private key of keyStore with the given name,
+// which is the role of the keys generated by `docker trust key generate`.
+func getSigningKey(keyStore trustmanager.KeyStore, name string) (data.PrivateKey, error) {
+	for keyID, info := range keyStore.ListKeys() {
+		if info.Role == data.RoleName(name) {
+			key, _, err := keyStore.GetKey(keyID)
+			return key, err
+		}
+	}
+	return nil, errors.Errorf("no private key named %s in %s", name, trust.GetTrustDirectory())
+}
@@ -0,0 +1,84 @@
+package cliplugin
+
+import (
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pluginmanager "github.com/docker/cli/cli-plugins/manager"
+	"github.com/docker/cli/cli/trust"
+	"github.com/docker/cli/internal/test"
+	"github.com/theupdateframework/notary/passphrase"
+	"github.com/theupdateframework/notary/trustmanager"
+	"github.com/theupdateframework/notary/tuf/data"
+	tufutils "github.com/theupdateframework/notary/tuf/utils"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+	"gotest.tools/env"
+	"gotest.tools/fs"
+)
+
+func TestSign(t *testing.T) {
+	defer setupPluginDir(t)()
+	defer env.Patch(t, "DOCKER_CONTENT_TRUST_REPOSITORY_PASSPHRASE", "password")()
+	dir := fs.NewDir(t, t.Name(), fs.WithFile("docker-hello", "hello binary"))
+	defer dir.Remove()
+
+	keyStore, err := trustmanager.NewKeyFileStore(trust.GetTrustDirectory(), passphrase.ConstantRetriever("password"))
+	assert.NilError(t, err)
+	key, err := tufutils.GenerateKey(data.ECDSAKey)
+	assert.NilError(t, err)
+	assert.NilError(t, keyStore.AddKey(trustmanager.KeyInfo{Role: "alice"}, key))
+	pubPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: data.PublicKeyFromPrivate(key).Public()})
+	assert.NilError(t, os.MkdirAll(pluginmanager.TrustDir(), 0755))
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(pluginmanager.TrustDir(), "alice.pub"), pubPEM, 0644))
+
+	path := dir.Join("docker-hello")
+	cli := test.NewFakeCli(nil)
+	cmd := newSignCommand(cli)
+	cmd.SetArgs([]string{"--key", "alice", path})
+	assert.NilError(t, cmd.Execute())
+	assert.Check(t, is.Equal("Signed "+path+" with key alice ("+key.ID()+") to "+path+".sig\n", cli.OutBuffer().String()))
+
+	verification, err := pluginmanager.VerifyPlugin(nil, "hello", path)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal("signature (alice)", verification))
+}
+
+func TestSignErrors(t *testing.T) {
+	defer setupPluginDir(t)()
+	dir := fs.NewDir(t, t.Name(), fs.WithFile("docker-hello", "hello binary"))
+	defer dir.Remove()
+
+	testCases := []struct {
+		name          string
+		args          []string
+		expectedError string
+	}{
+		{
+			name:          "no-key",
+			args:          []string{dir.Join("docker-hello")},
+			expectedError: "the name of the signing key must be provided using the `--key` flag",
+		},
+		{
+			name:          "unknown-key",
+			args:          []string{"--key", "bob", dir.Join("docker-hello")},
+			expectedError: "no private key named bob",
+		},
+		{
+			name:          "no-binary",
+			args:          []string{"--key", "bob", dir.Join("docker-world")},
+			expectedError: "no such file or directory",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := newSignCommand(test.NewFakeCli(nil))
+			cmd.SetArgs(tc.args)
+			cmd.SetOutput(ioutil.Discard)
+			assert.ErrorContains(t, cmd.Execute(), tc.expectedError)
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package cliplugin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	pluginmanager "github.com/docker/cli/cli-plugins/manager"
+	"github.com/docker/cli/cli/command"
+	registryclient "github.com/docker/cli/cli/registry/client"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type updateOptions struct {
+	names    []string
+	insecure bool
+}
+
+func newUpdateCommand(dockerCli command.Cli) *cobra.Command {
+	var options updateOptions
+
+	cmd := &cobra.Command{
+		Use:   "update [OPTIONS] [PLUGIN...]",
+		Short: "Update CLI plugins installed from a registry",
+		Long:  "Update CLI plugins installed from a registry. All the plugins installed from a registry are updated if none is specified.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			options.names = args
+			return runUpdate(dockerCli, options)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVar(&options.insecure, "insecure", false, "Allow communication with an insecure registry")
+
+	return cmd
+}
+
+func runUpdate(dockerCli command.Cli, options updateOptions) error {
+	var records []pluginmanager.InstallRecord
+	if len(options.names) == 0 {
+		var err error
+		if records, err = pluginmanager.ListInstallRecords(); err != nil {
+			return err
+		}
+	}
+	for _, name := range options.names {
+		record, err := pluginmanager.GetInstallRecord(name)
+		if err != nil {
+			if pluginmanager.IsNotFound(err) {
+				return errors.Errorf("CLI plugin %s was not installed from a registry", name)
+			}
+			return err
+		}
+		records = append(records, record)
+	}
+
+	var errs []string
+	for _, record := range records {
+		if err := updatePlugin(dockerCli, record, options.insecure); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// updatePlugin installs the plugin from its source again, if the source now
+// references another manifest
+func updatePlugin(dockerCli command.Cli, record pluginmanager.InstallRecord, insecure bool) error {
+	ref, _, err := parsePluginReference(record.Source)
+	if err != nil {
+		return err
+	}
+	rclient, err := registryclient.AsContentClient(dockerCli.RegistryClient(insecure))
+	if err != nil {
+		return errors.Wrapf(err, "failed to update %s", record.Name)
+	}
+	artifact, err := fetchPlugin(context.Background(), rclient, ref)
+	if err != nil {
+		return errors.Wrapf(err, "failed to update %s", record.Name)
+	}
+	if artifact.digest == record.Digest {
+		fmt.Fprintf(dockerCli.Out(), "%s %s is up to date\n", record.Name, record.Version)
+		return nil
+	}
+	return installPlugin(dockerCli, record.Name, ref, artifact)
+}
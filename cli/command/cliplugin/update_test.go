@@ -0,0 +1,52 @@
+package cliplugin
+
+import (
+	"io/ioutil"
+	"testing"
+
+	pluginmanager "github.com/docker/cli/cli-plugins/manager"
+	"github.com/docker/cli/internal/test"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+func TestUpdate(t *testing.T) {
+	defer setupPluginDir(t)()
+	registry := newFakePluginRegistry(t)
+	registry.push("example.com/plugins/docker-hello:1", "1.0.0", "hello binary")
+	registry.push("example.com/plugins/docker-world", "1.0.0", "world binary")
+
+	cli := test.NewFakeCli(nil)
+	cli.SetRegistryClient(registry.client())
+	for _, ref := range []string{"example.com/plugins/docker-hello:1", "example.com/plugins/docker-world"} {
+		cmd := newInstallCommand(cli)
+		cmd.SetArgs([]string{ref})
+		assert.NilError(t, cmd.Execute())
+	}
+
+	dgst := registry.push("example.com/plugins/docker-hello:1", "1.1.0", "new hello binary")
+	cli.OutBuffer().Reset()
+	cmd := newUpdateCommand(cli)
+	cmd.SetArgs([]string{})
+	assert.NilError(t, cmd.Execute())
+
+	path := pluginmanager.UserPluginPath("hello")
+	assert.Check(t, is.Equal("Installed hello 1.1.0 to "+path+"\nworld 1.0.0 is up to date\n", cli.OutBuffer().String()))
+	binary, err := ioutil.ReadFile(path)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal("new hello binary", string(binary)))
+	record, err := pluginmanager.GetInstallRecord("hello")
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(dgst, record.Digest))
+	assert.Check(t, is.Equal("1.1.0", record.Version))
+}
+
+func TestUpdateNotInstalledFromRegistry(t *testing.T) {
+	defer setupPluginDir(t)()
+	cli := test.NewFakeCli(nil)
+	cli.SetRegistryClient(newFakePluginRegistry(t).client())
+	cmd := newUpdateCommand(cli)
+	cmd.SetArgs([]string{"hello"})
+	cmd.SetOutput(ioutil.Discard)
+	assert.ErrorContains(t, cmd.Execute(), "CLI plugin hello was not installed from a registry")
+}
@@ -202,9 +202,12 @@ func createContainer(ctx context.Context, dockerCli command.Cli, containerConfig
 	if named, ok := ref.(reference.Named); ok {
 		namedRef = reference.TagNameOnly(named)
 
-		if taggedRef, ok := namedRef.(reference.NamedTagged); ok && !opts.untrusted {
-			var err error
-			trustedRef, err = image.TrustedReference(ctx, dockerCli, taggedRef, nil)
+		policy, err := command.ContentTrustPolicy(dockerCli)
+		if err != nil {
+			return nil, err
+		}
+		if taggedRef, ok := namedRef.(reference.NamedTagged); ok && command.ContentTrustVerified(policy, namedRef, opts.untrusted) {
+			trustedRef, err = image.TrustedReferenceWithPolicy(ctx, dockerCli, taggedRef, nil, policy)
 			if err != nil {
 				return nil, err
 			}
@@ -8,8 +8,9 @@ import (
 
 // DockerContext is a typed representation of what we put in Context metadata
 type DockerContext struct {
-	Description       string       `json:",omitempty"`
-	StackOrchestrator Orchestrator `json:",omitempty"`
+	Description        string       `json:",omitempty"`
+	StackOrchestrator  Orchestrator `json:",omitempty"`
+	ContentTrustPolicy string       `json:",omitempty"`
 }
 
 // GetDockerContext extracts metadata from stored context metadata
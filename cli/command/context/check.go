@@ -0,0 +1,260 @@
+package context
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/cli/cli/command"
+	clicontext "github.com/docker/cli/cli/context"
+	"github.com/docker/cli/cli/context/docker"
+	kubecontext "github.com/docker/cli/cli/context/kubernetes"
+	"github.com/docker/cli/cli/context/store"
+	"github.com/docker/cli/kubernetes"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/docker/docker/client"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// defaultCheckTimeout is the default timeout of the health check of an endpoint
+const defaultCheckTimeout = 5 * time.Second
+
+// certificateExpiryWarning is how long before it expires that the expiry of a
+// TLS certificate is shown in the status of an endpoint
+const certificateExpiryWarning = 30 * 24 * time.Hour
+
+// endpointStatus is the result of the health check of an endpoint
+type endpointStatus struct {
+	Reachable     bool
+	Latency       string     `json:",omitempty"`
+	ServerVersion string     `json:",omitempty"`
+	SwarmRole     string     `json:",omitempty"`
+	TLSExpiry     *time.Time `json:",omitempty"`
+	Error         string     `json:",omitempty"`
+}
+
+// String returns a summary of the status, as shown by "docker context ls"
+func (s *endpointStatus) String() string {
+	if s == nil {
+		return ""
+	}
+	var details []string
+	if s.TLSExpiry != nil {
+		if expiresIn := time.Until(*s.TLSExpiry); expiresIn <= 0 {
+			details = append(details, "certificate expired")
+		} else if expiresIn < certificateExpiryWarning {
+			details = append(details, fmt.Sprintf("certificate expires in %d days", int(expiresIn.Hours()/24)))
+		}
+	}
+	if !s.Reachable {
+		return strings.Join(append([]string{"unreachable"}, details...), ", ")
+	}
+	details = append([]string{s.ServerVersion, s.SwarmRole, s.Latency}, details...)
+	var parts []string
+	for _, d := range details {
+		if d != "" {
+			parts = append(parts, d)
+		}
+	}
+	return fmt.Sprintf("reachable (%s)", strings.Join(parts, ", "))
+}
+
+// contextStatus is the result of the health check of the endpoints of a
+// context
+type contextStatus struct {
+	Docker     *endpointStatus `json:",omitempty"`
+	Kubernetes *endpointStatus `json:",omitempty"`
+}
+
+// checkContexts checks the endpoints of the contexts concurrently, and
+// returns their status indexed by context name.
+func checkContexts(dockerCli command.Cli, names []string, timeout time.Duration) map[string]*contextStatus {
+	s := dockerCli.ContextStore()
+	connectionHelpers := dockerCli.ConfigFile().ConnectionHelpers
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		statuses = make(map[string]*contextStatus, len(names))
+	)
+	for _, name := range names {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			status := checkContext(s, name, connectionHelpers, timeout)
+			mu.Lock()
+			statuses[name] = status
+			mu.Unlock()
+		}(name)
+	}
+	wg.Wait()
+	return statuses
+}
+
+// checkContext checks the endpoints of the context concurrently
+func checkContext(s store.Store, name string, connectionHelpers map[string]string, timeout time.Duration) *contextStatus {
+	var (
+		status contextStatus
+		wg     sync.WaitGroup
+	)
+	meta, err := s.GetContextMetadata(name)
+	if err != nil {
+		return &contextStatus{Docker: &endpointStatus{Error: err.Error()}}
+	}
+	if epMeta, err := docker.EndpointFromContext(meta); err == nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			conn, err := docker.ConnectionFromContext(meta)
+			if err != nil {
+				status.Docker = &endpointStatus{Error: err.Error()}
+				return
+			}
+			ep, err := docker.WithTLSData(s, name, epMeta)
+			if err != nil {
+				status.Docker = &endpointStatus{Error: err.Error()}
+				return
+			}
+			ep.Connection = conn
+			ep.ConnectionHelpers = connectionHelpers
+			status.Docker = checkDockerEndpoint(ep, timeout)
+		}()
+	}
+	if epMeta := kubecontext.EndpointFromContext(meta); epMeta != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ep, err := epMeta.WithTLSData(s, name)
+			if err != nil {
+				status.Kubernetes = &endpointStatus{Error: err.Error()}
+				return
+			}
+			status.Kubernetes = checkKubernetesEndpoint(ep.KubernetesConfig(), timeout)
+			status.Kubernetes.TLSExpiry = certificateExpiry(ep.TLSData)
+		}()
+	}
+	wg.Wait()
+	return &status
+}
+
+// checkDefaultContext checks the endpoints of the default context, which
+// are resolved from the environment.
+func checkDefaultContext(dockerCli command.Cli, timeout time.Duration) *contextStatus {
+	var (
+		status contextStatus
+		wg     sync.WaitGroup
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		status.Docker = checkDockerEndpoint(dockerCli.DockerEndpoint(), timeout)
+	}()
+	go func() {
+		defer wg.Done()
+		kubeconfig := kubernetes.NewKubernetesConfig("")
+		if _, err := kubeconfig.ClientConfig(); err == nil {
+			status.Kubernetes = checkKubernetesEndpoint(kubeconfig, timeout)
+		}
+	}()
+	wg.Wait()
+	return &status
+}
+
+// checkDockerEndpoint pings the Docker endpoint, and gets its version and
+// swarm role.
+func checkDockerEndpoint(ep docker.Endpoint, timeout time.Duration) *endpointStatus {
+	status := &endpointStatus{TLSExpiry: certificateExpiry(ep.TLSData)}
+	clientOpts, err := ep.ClientOpts()
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	apiClient, err := client.NewClientWithOpts(append(clientOpts, client.WithHTTPHeaders(map[string]string{"User-Agent": command.UserAgent()}))...)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	defer apiClient.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	start := time.Now()
+	ping, err := apiClient.Ping(ctx)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	status.Reachable = true
+	status.Latency = time.Since(start).Round(time.Millisecond).String()
+	apiClient.NegotiateAPIVersionPing(ping)
+
+	info, err := apiClient.Info(ctx)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	status.ServerVersion = info.ServerVersion
+	if info.Swarm.LocalNodeState == swarm.LocalNodeStateActive {
+		if info.Swarm.ControlAvailable {
+			status.SwarmRole = "manager"
+		} else {
+			status.SwarmRole = "worker"
+		}
+	}
+	return status
+}
+
+// checkKubernetesEndpoint gets the version of the Kubernetes endpoint
+func checkKubernetesEndpoint(clientConfig clientcmd.ClientConfig, timeout time.Duration) *endpointStatus {
+	status := &endpointStatus{}
+	restConfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	restConfig.Timeout = timeout
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	start := time.Now()
+	version, err := discoveryClient.ServerVersion()
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	status.Reachable = true
+	status.Latency = time.Since(start).Round(time.Millisecond).String()
+	status.ServerVersion = version.GitVersion
+	return status
+}
+
+// certificateExpiry returns the earliest expiry of the client certificate and
+// of the CA certificate of the TLS data, if any.
+func certificateExpiry(tlsData *clicontext.TLSData) *time.Time {
+	if tlsData == nil {
+		return nil
+	}
+	var expiry *time.Time
+	for _, pemData := range [][]byte{tlsData.Cert, tlsData.CA} {
+		for block, rest := pem.Decode(pemData); block != nil; block, rest = pem.Decode(rest) {
+			if block.Type != "CERTIFICATE" {
+				continue
+			}
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				continue
+			}
+			if expiry == nil || cert.NotAfter.Before(*expiry) {
+				notAfter := cert.NotAfter
+				expiry = &notAfter
+			}
+		}
+	}
+	return expiry
+}
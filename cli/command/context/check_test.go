@@ -0,0 +1,193 @@
+package context
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	clicontext "github.com/docker/cli/cli/context"
+	"github.com/docker/cli/cli/context/docker"
+	kubecontext "github.com/docker/cli/cli/context/kubernetes"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/swarm"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+func newTestDockerServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/_ping"):
+			w.Header().Set("API-Version", "1.40")
+			w.Write([]byte("OK"))
+		case strings.HasSuffix(r.URL.Path, "/info"):
+			json.NewEncoder(w).Encode(types.Info{
+				ServerVersion: "19.03.1",
+				Swarm:         swarm.Info{LocalNodeState: swarm.LocalNodeStateActive, ControlAvailable: true},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestCheckDockerEndpoint(t *testing.T) {
+	server := newTestDockerServer(t)
+	defer server.Close()
+
+	status := checkDockerEndpoint(docker.Endpoint{
+		EndpointMeta: docker.EndpointMeta{Host: "tcp://" + server.Listener.Addr().String()},
+	}, time.Second)
+	assert.Check(t, status.Reachable)
+	assert.Check(t, is.Equal("19.03.1", status.ServerVersion))
+	assert.Check(t, is.Equal("manager", status.SwarmRole))
+	assert.Check(t, status.Latency != "")
+	assert.Check(t, is.Equal("", status.Error))
+
+	server.Close()
+	status = checkDockerEndpoint(docker.Endpoint{
+		EndpointMeta: docker.EndpointMeta{Host: "tcp://" + server.Listener.Addr().String()},
+	}, time.Second)
+	assert.Check(t, !status.Reachable)
+	assert.Check(t, is.Contains(status.Error, "Cannot connect to the Docker daemon"))
+}
+
+func TestCheckKubernetesEndpoint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/version" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"major": "1", "minor": "15", "gitVersion": "v1.15.2"}`))
+	}))
+	defer server.Close()
+
+	ep := kubecontext.Endpoint{EndpointMeta: kubecontext.EndpointMeta{EndpointMetaBase: clicontext.EndpointMetaBase{Host: server.URL}}}
+	status := checkKubernetesEndpoint(ep.KubernetesConfig(), time.Second)
+	assert.Check(t, status.Reachable)
+	assert.Check(t, is.Equal("v1.15.2", status.ServerVersion))
+	assert.Check(t, is.Equal("", status.Error))
+
+	server.Close()
+	status = checkKubernetesEndpoint(ep.KubernetesConfig(), time.Second)
+	assert.Check(t, !status.Reachable)
+	assert.Check(t, status.Error != "")
+}
+
+func newTestCertificate(t *testing.T, notAfter time.Time) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    notAfter.Add(-48 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NilError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestCertificateExpiry(t *testing.T) {
+	early := time.Now().Add(24 * time.Hour).Truncate(time.Second).UTC()
+	late := early.Add(365 * 24 * time.Hour)
+
+	assert.Check(t, is.Nil(certificateExpiry(nil)))
+	assert.Check(t, is.Nil(certificateExpiry(&clicontext.TLSData{})))
+	expiry := certificateExpiry(&clicontext.TLSData{
+		CA:   newTestCertificate(t, late),
+		Cert: newTestCertificate(t, early),
+	})
+	assert.Assert(t, expiry != nil)
+	assert.Check(t, expiry.Equal(early))
+	expiry = certificateExpiry(&clicontext.TLSData{CA: newTestCertificate(t, late)})
+	assert.Assert(t, expiry != nil)
+	assert.Check(t, expiry.Equal(late))
+}
+
+func TestEndpointStatusString(t *testing.T) {
+	expired := time.Now().Add(-time.Hour)
+	expiresSoon := time.Now().Add(10*24*time.Hour + time.Hour)
+	expiresLater := time.Now().Add(365 * 24 * time.Hour)
+
+	testCases := []struct {
+		status   *endpointStatus
+		expected string
+	}{
+		{status: nil, expected: ""},
+		{status: &endpointStatus{Error: "connection refused"}, expected: "unreachable"},
+		{status: &endpointStatus{TLSExpiry: &expired}, expected: "unreachable, certificate expired"},
+		{status: &endpointStatus{Reachable: true, ServerVersion: "19.03.1", Latency: "3ms"}, expected: "reachable (19.03.1, 3ms)"},
+		{status: &endpointStatus{Reachable: true, ServerVersion: "19.03.1", SwarmRole: "worker", Latency: "3ms", TLSExpiry: &expiresLater}, expected: "reachable (19.03.1, worker, 3ms)"},
+		{status: &endpointStatus{Reachable: true, ServerVersion: "v1.15.2", Latency: "12ms", TLSExpiry: &expiresSoon}, expected: "reachable (v1.15.2, 12ms, certificate expires in 10 days)"},
+	}
+	for _, tc := range testCases {
+		assert.Check(t, is.Equal(tc.expected, tc.status.String()))
+	}
+}
+
+func TestListCheck(t *testing.T) {
+	cli, cleanup := makeFakeCli(t)
+	defer cleanup()
+	server := newTestDockerServer(t)
+	defer server.Close()
+	assert.NilError(t, RunCreate(cli, &CreateOptions{
+		Name:                     "reachable",
+		DefaultStackOrchestrator: "swarm",
+		Docker:                   map[string]string{keyHost: "tcp://" + server.Listener.Addr().String()},
+	}))
+	assert.NilError(t, RunCreate(cli, &CreateOptions{
+		Name:                     "unreachable",
+		DefaultStackOrchestrator: "swarm",
+		Docker:                   map[string]string{keyHost: "unix:///var/run/nonexistent.sock"},
+	}))
+	cli.SetCurrentContext("reachable")
+	cli.OutBuffer().Reset()
+	assert.NilError(t, runList(cli, &listOptions{
+		format:       "{{.Name}}: {{.DockerStatus}}",
+		check:        true,
+		checkTimeout: time.Second,
+	}))
+	lines := strings.Split(strings.TrimSpace(cli.OutBuffer().String()), "\n")
+	assert.Assert(t, is.Len(lines, 3))
+	assert.Check(t, is.Equal("default: ", lines[0]))
+	assert.Check(t, strings.HasPrefix(lines[1], "reachable: reachable (19.03.1, manager, "), lines[1])
+	assert.Check(t, is.Equal("unreachable: unreachable", lines[2]))
+}
+
+func TestInspectCheck(t *testing.T) {
+	cli, cleanup := makeFakeCli(t)
+	defer cleanup()
+	server := newTestDockerServer(t)
+	defer server.Close()
+	assert.NilError(t, RunCreate(cli, &CreateOptions{
+		Name:                     "reachable",
+		DefaultStackOrchestrator: "swarm",
+		Docker:                   map[string]string{keyHost: "tcp://" + server.Listener.Addr().String()},
+	}))
+	cli.OutBuffer().Reset()
+	assert.NilError(t, runInspect(cli, inspectOptions{
+		refs:         []string{"reachable"},
+		format:       "{{json .Status.Docker}}",
+		check:        true,
+		checkTimeout: time.Second,
+	}))
+	var status endpointStatus
+	assert.NilError(t, json.Unmarshal(cli.OutBuffer().Bytes(), &status))
+	assert.Check(t, status.Reachable)
+	assert.Check(t, is.Equal("19.03.1", status.ServerVersion))
+	assert.Check(t, is.Equal("manager", status.SwarmRole))
+}
@@ -3,6 +3,7 @@ package context
 import (
 	"bytes"
 	"fmt"
+	"path/filepath"
 	"text/tabwriter"
 
 	"github.com/docker/cli/cli"
@@ -19,8 +20,17 @@ type CreateOptions struct {
 	Name                     string
 	Description              string
 	DefaultStackOrchestrator string
+	ContentTrustPolicy       string
 	Docker                   map[string]string
 	Kubernetes               map[string]string
+	// FromFile is the file declaring the contexts to create or update,
+	// instead of the other options
+	FromFile string
+	// Prune removes the contexts not declared in FromFile
+	Prune bool
+	// kubernetesEndpoint is the Kubernetes endpoint of an imported context,
+	// instead of the Kubernetes endpoint configuration
+	kubernetesEndpoint *kubernetes.Endpoint
 }
 
 func longCreateDescription() string {
@@ -48,8 +58,24 @@ func newCreateCommand(dockerCli command.Cli) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "create [OPTIONS] CONTEXT",
 		Short: "Create a context",
-		Args:  cli.ExactArgs(1),
+		Args: func(cmd *cobra.Command, args []string) error {
+			if opts.FromFile != "" {
+				return cli.NoArgs(cmd, args)
+			}
+			return cli.ExactArgs(1)(cmd, args)
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.FromFile != "" {
+				for _, flag := range []string{"description", "default-stack-orchestrator", "content-trust-policy", "docker", "kubernetes"} {
+					if cmd.Flags().Changed(flag) {
+						return errors.Errorf("conflicting options: --from-file can't be used with --%s", flag)
+					}
+				}
+				return RunCreateFromFile(dockerCli, opts)
+			}
+			if opts.Prune {
+				return errors.New("--prune requires --from-file")
+			}
 			opts.Name = args[0]
 			return RunCreate(dockerCli, opts)
 		},
@@ -61,8 +87,11 @@ func newCreateCommand(dockerCli command.Cli) *cobra.Command {
 		&opts.DefaultStackOrchestrator,
 		"default-stack-orchestrator", "",
 		"Default orchestrator for stack operations to use with this context (swarm|kubernetes|all)")
+	flags.StringVar(&opts.ContentTrustPolicy, "content-trust-policy", "", "Path of the content trust policy file enforced with this context")
 	flags.StringToStringVar(&opts.Docker, "docker", nil, "set the docker endpoint")
 	flags.StringToStringVar(&opts.Kubernetes, "kubernetes", nil, "set the kubernetes endpoint")
+	flags.StringVar(&opts.FromFile, "from-file", "", `Create or update the contexts declared in a YAML file ("-" for STDIN)`)
+	flags.BoolVar(&opts.Prune, "prune", false, "Remove the contexts not declared in the --from-file file")
 	return cmd
 }
 
@@ -72,59 +101,84 @@ func RunCreate(cli command.Cli, o *CreateOptions) error {
 	if err := checkContextNameForCreation(s, o.Name); err != nil {
 		return err
 	}
+	contextMetadata, contextTLSData, err := newContextMetadataAndTLS(cli, o)
+	if err != nil {
+		return err
+	}
+	if err := s.CreateOrUpdateContext(contextMetadata); err != nil {
+		return err
+	}
+	if err := s.ResetContextTLSMaterial(o.Name, &contextTLSData); err != nil {
+		return err
+	}
+	fmt.Fprintln(cli.Out(), o.Name)
+	fmt.Fprintf(cli.Err(), "Successfully created context %q\n", o.Name)
+	return nil
+}
+
+// newContextMetadataAndTLS returns the metadata and the TLS material of the
+// context configured by the options
+func newContextMetadataAndTLS(cli command.Cli, o *CreateOptions) (store.ContextMetadata, store.ContextTLSData, error) {
 	stackOrchestrator, err := command.NormalizeOrchestrator(o.DefaultStackOrchestrator)
 	if err != nil {
-		return errors.Wrap(err, "unable to parse default-stack-orchestrator")
+		return store.ContextMetadata{}, store.ContextTLSData{}, errors.Wrap(err, "unable to parse default-stack-orchestrator")
+	}
+	contentTrustPolicy, err := absContentTrustPolicy(o.ContentTrustPolicy)
+	if err != nil {
+		return store.ContextMetadata{}, store.ContextTLSData{}, err
 	}
 	contextMetadata := store.ContextMetadata{
 		Endpoints: make(map[string]interface{}),
 		Metadata: command.DockerContext{
-			Description:       o.Description,
-			StackOrchestrator: stackOrchestrator,
+			Description:        o.Description,
+			StackOrchestrator:  stackOrchestrator,
+			ContentTrustPolicy: contentTrustPolicy,
 		},
 		Name: o.Name,
 	}
 	if o.Docker == nil {
-		return errors.New("docker endpoint configuration is required")
+		return store.ContextMetadata{}, store.ContextTLSData{}, errors.New("docker endpoint configuration is required")
 	}
 	contextTLSData := store.ContextTLSData{
 		Endpoints: make(map[string]store.EndpointTLSData),
 	}
-	dockerEP, dockerTLS, err := getDockerEndpointMetadataAndTLS(cli, o.Docker)
+	dockerEP, dockerConn, dockerTLS, err := getDockerEndpointMetadataAndTLS(cli, o.Docker)
 	if err != nil {
-		return errors.Wrap(err, "unable to create docker endpoint config")
+		return store.ContextMetadata{}, store.ContextTLSData{}, errors.Wrap(err, "unable to create docker endpoint config")
 	}
 	contextMetadata.Endpoints[docker.DockerEndpoint] = dockerEP
+	if dockerConn != nil {
+		contextMetadata.Endpoints[docker.ConnectionEndpoint] = *dockerConn
+	}
 	if dockerTLS != nil {
 		contextTLSData.Endpoints[docker.DockerEndpoint] = *dockerTLS
 	}
-	if o.Kubernetes != nil {
-		kubernetesEP, kubernetesTLS, err := getKubernetesEndpointMetadataAndTLS(cli, o.Kubernetes)
+	var (
+		kubernetesEP  *kubernetes.EndpointMeta
+		kubernetesTLS *store.EndpointTLSData
+	)
+	switch {
+	case o.kubernetesEndpoint != nil:
+		kubernetesEP, kubernetesTLS = &o.kubernetesEndpoint.EndpointMeta, o.kubernetesEndpoint.TLSData.ToStoreTLSData()
+	case o.Kubernetes != nil:
+		kubernetesEP, kubernetesTLS, err = getKubernetesEndpointMetadataAndTLS(cli, o.Kubernetes)
 		if err != nil {
-			return errors.Wrap(err, "unable to create kubernetes endpoint config")
+			return store.ContextMetadata{}, store.ContextTLSData{}, errors.Wrap(err, "unable to create kubernetes endpoint config")
 		}
 		if kubernetesEP == nil && stackOrchestrator.HasKubernetes() {
-			return errors.Errorf("cannot specify orchestrator %q without configuring a Kubernetes endpoint", stackOrchestrator)
-		}
-		if kubernetesEP != nil {
-			contextMetadata.Endpoints[kubernetes.KubernetesEndpoint] = kubernetesEP
-		}
-		if kubernetesTLS != nil {
-			contextTLSData.Endpoints[kubernetes.KubernetesEndpoint] = *kubernetesTLS
+			return store.ContextMetadata{}, store.ContextTLSData{}, errors.Errorf("cannot specify orchestrator %q without configuring a Kubernetes endpoint", stackOrchestrator)
 		}
 	}
-	if err := validateEndpointsAndOrchestrator(contextMetadata); err != nil {
-		return err
+	if kubernetesEP != nil {
+		contextMetadata.Endpoints[kubernetes.KubernetesEndpoint] = kubernetesEP
 	}
-	if err := s.CreateOrUpdateContext(contextMetadata); err != nil {
-		return err
+	if kubernetesTLS != nil {
+		contextTLSData.Endpoints[kubernetes.KubernetesEndpoint] = *kubernetesTLS
 	}
-	if err := s.ResetContextTLSMaterial(o.Name, &contextTLSData); err != nil {
-		return err
+	if err := validateEndpointsAndOrchestrator(contextMetadata); err != nil {
+		return store.ContextMetadata{}, store.ContextTLSData{}, err
 	}
-	fmt.Fprintln(cli.Out(), o.Name)
-	fmt.Fprintf(cli.Err(), "Successfully created context %q\n", o.Name)
-	return nil
+	return contextMetadata, contextTLSData, nil
 }
 
 func checkContextNameForCreation(s store.Store, name string) error {
@@ -139,3 +193,13 @@ func checkContextNameForCreation(s store.Store, name string) error {
 	}
 	return nil
 }
+
+// absContentTrustPolicy returns the absolute path of the content trust policy
+// file of a context, so that it doesn't depend on the working directory.
+func absContentTrustPolicy(policyFile string) (string, error) {
+	if policyFile == "" {
+		return "", nil
+	}
+	abs, err := filepath.Abs(policyFile)
+	return abs, errors.Wrap(err, "unable to resolve content-trust-policy")
+}
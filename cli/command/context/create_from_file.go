@@ -0,0 +1,231 @@
+package context
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/context/store"
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// contextsFile is the format of the files declaring contexts, created or
+// updated with "docker context create --from-file"
+type contextsFile struct {
+	// Defaults are the default values of the contexts
+	Defaults contextDeclaration   `yaml:"defaults,omitempty"`
+	Contexts []contextDeclaration `yaml:"contexts"`
+}
+
+// contextDeclaration declares a context, its string values are templates
+// executed with the name of the context as {{.Name}}
+type contextDeclaration struct {
+	Name                     string            `yaml:"name,omitempty"`
+	Description              string            `yaml:"description,omitempty"`
+	DefaultStackOrchestrator string            `yaml:"default-stack-orchestrator,omitempty"`
+	ContentTrustPolicy       string            `yaml:"content-trust-policy,omitempty"`
+	Docker                   map[string]string `yaml:"docker,omitempty"`
+	Kubernetes               map[string]string `yaml:"kubernetes,omitempty"`
+}
+
+// pathKeys are the config keys of the endpoints whose values are paths, which
+// are relative to the directory of the file
+var pathKeys = map[string]struct{}{
+	keyCA:          {},
+	keyCert:        {},
+	keyKey:         {},
+	keySSHIdentity: {},
+	keyKubeconfig:  {},
+}
+
+// RunCreateFromFile creates or updates the contexts declared in a file, and
+// removes the other contexts if pruning
+func RunCreateFromFile(dockerCli command.Cli, o *CreateOptions) error {
+	var (
+		data []byte
+		dir  string
+		err  error
+	)
+	if o.FromFile == "-" {
+		data, err = ioutil.ReadAll(dockerCli.In())
+	} else {
+		data, err = ioutil.ReadFile(o.FromFile)
+		dir = filepath.Dir(o.FromFile)
+	}
+	if err != nil {
+		return err
+	}
+	var file contextsFile
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return errors.Wrapf(err, "invalid contexts file %s", o.FromFile)
+	}
+	if len(file.Contexts) == 0 {
+		return errors.Errorf("no context declared in %s", o.FromFile)
+	}
+	if file.Defaults.Name != "" {
+		return errors.New("the defaults of the contexts can't have a name")
+	}
+
+	// validate all the contexts before writing any
+	s := dockerCli.ContextStore()
+	type declaredContext struct {
+		metadata store.ContextMetadata
+		tls      store.ContextTLSData
+	}
+	contexts := make([]declaredContext, 0, len(file.Contexts))
+	declared := make(map[string]struct{})
+	for i, d := range file.Contexts {
+		if err := validateContextName(d.Name); err != nil {
+			return errors.Wrapf(err, "context #%d", i+1)
+		}
+		if _, ok := declared[d.Name]; ok {
+			return errors.Errorf("context %q is declared more than once", d.Name)
+		}
+		declared[d.Name] = struct{}{}
+		opts, err := d.withDefaults(file.Defaults).createOptions(dir)
+		if err != nil {
+			return errors.Wrapf(err, "context %q", d.Name)
+		}
+		metadata, tls, err := newContextMetadataAndTLS(dockerCli, opts)
+		if err != nil {
+			return errors.Wrapf(err, "context %q", d.Name)
+		}
+		contexts = append(contexts, declaredContext{metadata: metadata, tls: tls})
+	}
+
+	for _, c := range contexts {
+		action := "updated"
+		if _, err := s.GetContextMetadata(c.metadata.Name); store.IsErrContextDoesNotExist(err) {
+			action = "created"
+		} else if err != nil {
+			return errors.Wrap(err, "error while getting existing contexts")
+		}
+		if err := s.CreateOrUpdateContext(c.metadata); err != nil {
+			return err
+		}
+		if err := s.ResetContextTLSMaterial(c.metadata.Name, &c.tls); err != nil {
+			return err
+		}
+		fmt.Fprintln(dockerCli.Out(), c.metadata.Name)
+		fmt.Fprintf(dockerCli.Err(), "Successfully %s context %q\n", action, c.metadata.Name)
+	}
+	if !o.Prune {
+		return nil
+	}
+
+	existing, err := s.ListContexts()
+	if err != nil {
+		return err
+	}
+	var errs []string
+	currentCtx := dockerCli.CurrentContext()
+	for _, c := range existing {
+		if _, ok := declared[c.Name]; ok {
+			continue
+		}
+		if err := doRemove(dockerCli, c.Name, c.Name == currentCtx, false); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", c.Name, err))
+			continue
+		}
+		fmt.Fprintf(dockerCli.Err(), "Successfully removed context %q\n", c.Name)
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// withDefaults returns the declaration completed with the default values,
+// the endpoint configurations are merged key by key
+func (d contextDeclaration) withDefaults(defaults contextDeclaration) contextDeclaration {
+	if d.Description == "" {
+		d.Description = defaults.Description
+	}
+	if d.DefaultStackOrchestrator == "" {
+		d.DefaultStackOrchestrator = defaults.DefaultStackOrchestrator
+	}
+	if d.ContentTrustPolicy == "" {
+		d.ContentTrustPolicy = defaults.ContentTrustPolicy
+	}
+	d.Docker = mergeConfig(defaults.Docker, d.Docker)
+	d.Kubernetes = mergeConfig(defaults.Kubernetes, d.Kubernetes)
+	return d
+}
+
+func mergeConfig(defaults, config map[string]string) map[string]string {
+	if defaults == nil {
+		return config
+	}
+	merged := make(map[string]string, len(defaults)+len(config))
+	for k, v := range defaults {
+		merged[k] = v
+	}
+	for k, v := range config {
+		merged[k] = v
+	}
+	return merged
+}
+
+// createOptions returns the options creating the declared context, with the
+// templates executed and the paths relative to dir resolved
+func (d contextDeclaration) createOptions(dir string) (*CreateOptions, error) {
+	expand := func(value string) (string, error) {
+		tmpl, err := template.New("").Option("missingkey=error").Parse(value)
+		if err != nil {
+			return "", errors.Wrapf(err, "invalid template %q", value)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, struct{ Name string }{d.Name}); err != nil {
+			return "", errors.Wrapf(err, "invalid template %q", value)
+		}
+		return buf.String(), nil
+	}
+	resolve := func(path string) string {
+		if path == "" || dir == "" || filepath.IsAbs(path) || strings.HasPrefix(path, "~") {
+			return path
+		}
+		return filepath.Join(dir, path)
+	}
+	expandConfig := func(config map[string]string) (map[string]string, error) {
+		if config == nil {
+			return nil, nil
+		}
+		expanded := make(map[string]string, len(config))
+		for k, v := range config {
+			value, err := expand(v)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := pathKeys[k]; ok {
+				value = resolve(value)
+			}
+			expanded[k] = value
+		}
+		return expanded, nil
+	}
+
+	o := &CreateOptions{Name: d.Name}
+	var err error
+	if o.Description, err = expand(d.Description); err != nil {
+		return nil, err
+	}
+	if o.DefaultStackOrchestrator, err = expand(d.DefaultStackOrchestrator); err != nil {
+		return nil, err
+	}
+	if o.ContentTrustPolicy, err = expand(d.ContentTrustPolicy); err != nil {
+		return nil, err
+	}
+	o.ContentTrustPolicy = resolve(o.ContentTrustPolicy)
+	if o.Docker, err = expandConfig(d.Docker); err != nil {
+		return nil, err
+	}
+	if o.Kubernetes, err = expandConfig(d.Kubernetes); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
@@ -0,0 +1,155 @@
+package context
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/context/docker"
+	"github.com/docker/cli/cli/context/store"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+	"gotest.tools/fs"
+)
+
+const testContextsFile = `
+defaults:
+  description: "{{.Name}} node"
+  docker:
+    host: "ssh://me@{{.Name}}.example.com"
+contexts:
+  - name: node-1
+  - name: node-2
+    docker:
+      host: tcp://node-2.example.com:2376
+  - name: cluster
+    description: Kubernetes cluster
+    default-stack-orchestrator: kubernetes
+    kubernetes:
+      config-file: kubeconfig
+`
+
+func TestCreateFromFile(t *testing.T) {
+	kubeconfig, err := ioutil.ReadFile("testdata/test-kubeconfig")
+	assert.NilError(t, err)
+	dir := fs.NewDir(t, t.Name(),
+		fs.WithFile("kubeconfig", string(kubeconfig)),
+		fs.WithFile("contexts.yaml", testContextsFile),
+	)
+	defer dir.Remove()
+	cli, cleanup := makeFakeCli(t)
+	defer cleanup()
+	for _, name := range []string{"node-1", "obsolete"} {
+		assert.NilError(t, RunCreate(cli, &CreateOptions{Name: name, Docker: map[string]string{}}))
+	}
+	cli.OutBuffer().Reset()
+	cli.ErrBuffer().Reset()
+
+	assert.NilError(t, RunCreateFromFile(cli, &CreateOptions{FromFile: dir.Join("contexts.yaml"), Prune: true}))
+	assert.Check(t, is.Equal("node-1\nnode-2\ncluster\n", cli.OutBuffer().String()))
+	assert.Check(t, is.Equal(`Successfully updated context "node-1"
+Successfully created context "node-2"
+Successfully created context "cluster"
+Successfully removed context "obsolete"
+`, cli.ErrBuffer().String()))
+
+	s := cli.ContextStore()
+	for name, expected := range map[string]struct{ description, host string }{
+		"node-1": {description: "node-1 node", host: "ssh://me@node-1.example.com"},
+		"node-2": {description: "node-2 node", host: "tcp://node-2.example.com:2376"},
+	} {
+		meta, err := s.GetContextMetadata(name)
+		assert.NilError(t, err)
+		assert.Check(t, is.Equal(expected.description, meta.Metadata.(command.DockerContext).Description))
+		ep, err := docker.EndpointFromContext(meta)
+		assert.NilError(t, err)
+		assert.Check(t, is.Equal(expected.host, ep.Host))
+	}
+	meta, err := s.GetContextMetadata("cluster")
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal("Kubernetes cluster", meta.Metadata.(command.DockerContext).Description))
+	validateTestKubeEndpoint(t, s, "cluster")
+	_, err = s.GetContextMetadata("obsolete")
+	assert.Check(t, store.IsErrContextDoesNotExist(err))
+}
+
+func TestCreateFromFileInvalids(t *testing.T) {
+	cli, cleanup := makeFakeCli(t)
+	defer cleanup()
+	testCases := []struct {
+		doc           string
+		content       string
+		expectedError string
+	}{
+		{
+			doc:           "no context",
+			content:       "contexts: []",
+			expectedError: "no context declared in",
+		},
+		{
+			doc:           "unknown field",
+			content:       "contexts:\n  - name: foo\n    host: tcp://foo:2376",
+			expectedError: "field host not found",
+		},
+		{
+			doc:           "invalid name",
+			content:       "contexts:\n  - name: default\n    docker: {}",
+			expectedError: `context #1: "default" is a reserved context name`,
+		},
+		{
+			doc:           "duplicate",
+			content:       "contexts:\n  - name: foo\n    docker: {}\n  - name: foo\n    docker: {}",
+			expectedError: `context "foo" is declared more than once`,
+		},
+		{
+			doc:           "no docker endpoint",
+			content:       "contexts:\n  - name: foo",
+			expectedError: `context "foo": docker endpoint configuration is required`,
+		},
+		{
+			doc:           "invalid template",
+			content:       "contexts:\n  - name: foo\n    docker:\n      host: 'tcp://{{.Host}}:2376'",
+			expectedError: `context "foo": invalid template "tcp://{{.Host}}:2376"`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.doc, func(t *testing.T) {
+			file := fs.NewFile(t, "contexts", fs.WithContent(tc.content))
+			defer file.Remove()
+			err := RunCreateFromFile(cli, &CreateOptions{FromFile: file.Path()})
+			assert.Check(t, is.ErrorContains(err, tc.expectedError))
+		})
+	}
+	// nothing is written if a context is invalid
+	contexts, err := cli.ContextStore().ListContexts()
+	assert.NilError(t, err)
+	assert.Check(t, is.Len(contexts, 0))
+}
+
+func TestCreateFromFileCommandInvalids(t *testing.T) {
+	cli, cleanup := makeFakeCli(t)
+	defer cleanup()
+	testCases := []struct {
+		args          []string
+		expectedError string
+	}{
+		{
+			args:          []string{"--from-file", "contexts.yaml", "foo"},
+			expectedError: `accepts no arguments`,
+		},
+		{
+			args:          []string{"--from-file", "contexts.yaml", "--description", "foo"},
+			expectedError: `conflicting options: --from-file can't be used with --description`,
+		},
+		{
+			args:          []string{"--prune", "foo"},
+			expectedError: `--prune requires --from-file`,
+		},
+	}
+	for _, tc := range testCases {
+		cmd := newCreateCommand(cli)
+		cmd.SetArgs(tc.args)
+		cmd.SetOutput(ioutil.Discard)
+		assert.Check(t, is.ErrorContains(cmd.Execute(), tc.expectedError))
+	}
+}
@@ -3,15 +3,18 @@ package context
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/docker/cli/cli/command"
 	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/cli/cli/connhelper/ssh"
 	"github.com/docker/cli/cli/context/docker"
 	"github.com/docker/cli/cli/context/kubernetes"
 	"github.com/docker/cli/cli/context/store"
 	"github.com/docker/cli/internal/test"
 	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
 	"gotest.tools/env"
 )
 
@@ -21,6 +24,7 @@ func makeFakeCli(t *testing.T, opts ...func(*test.FakeCli)) (*test.FakeCli, func
 	storeConfig := store.NewConfig(
 		func() interface{} { return &command.DockerContext{} },
 		store.EndpointTypeGetter(docker.DockerEndpoint, func() interface{} { return &docker.EndpointMeta{} }),
+		store.EndpointTypeGetter(docker.ConnectionEndpoint, func() interface{} { return &docker.ConnectionMeta{} }),
 		store.EndpointTypeGetter(kubernetes.KubernetesEndpoint, func() interface{} { return &kubernetes.EndpointMeta{} }),
 	)
 	store := store.New(dir, storeConfig)
@@ -73,6 +77,47 @@ func TestCreateInvalids(t *testing.T) {
 			},
 			expecterErr: `unable to parse docker host`,
 		},
+		{
+			options: CreateOptions{
+				Name: "ssh-options-without-ssh-host",
+				Docker: map[string]string{
+					keyHost:        "tcp://example.com:2376",
+					keySSHIdentity: "/home/me/.ssh/id_ed25519",
+				},
+			},
+			expecterErr: `ssh-identity, ssh-jump, ssh-options and ssh-transport are only supported with ssh:// hosts`,
+		},
+		{
+			options: CreateOptions{
+				Name: "invalid-ssh-options",
+				Docker: map[string]string{
+					keyHost:       "ssh://example.com",
+					keySSHOptions: "ConnectTimeout",
+				},
+			},
+			expecterErr: `invalid ssh option "ConnectTimeout", expected KEY=VALUE`,
+		},
+		{
+			options: CreateOptions{
+				Name: "invalid-ssh-transport",
+				Docker: map[string]string{
+					keyHost:         "ssh://example.com",
+					keySSHTransport: "telnet",
+				},
+			},
+			expecterErr: `invalid ssh transport "telnet", expected "system" or "builtin"`,
+		},
+		{
+			options: CreateOptions{
+				Name: "unsupported-builtin-ssh-option",
+				Docker: map[string]string{
+					keyHost:         "ssh://example.com",
+					keySSHTransport: "builtin",
+					keySSHOptions:   "ProxyCommand=nc %h %p",
+				},
+			},
+			expecterErr: `ssh option "ProxyCommand=nc %h %p" is not supported by the builtin ssh transport`,
+		},
 		{
 			options: CreateOptions{
 				Name:                     "invalid-orchestrator",
@@ -113,6 +158,85 @@ func TestCreateInvalids(t *testing.T) {
 	}
 }
 
+func TestCreateSSHOptions(t *testing.T) {
+	cli, cleanup := makeFakeCli(t)
+	defer cleanup()
+
+	assert.NilError(t, RunCreate(cli, &CreateOptions{
+		Name: "ssh",
+		Docker: map[string]string{
+			keyHost:        "ssh://me@example.com",
+			keySSHIdentity: "/home/me/.ssh/id_ed25519",
+			keySSHJump:     "bastion1, bastion2",
+			keySSHOptions:  "ConnectTimeout=5;ServerAliveInterval=30",
+		},
+	}))
+	assert.NilError(t, RunCreate(cli, &CreateOptions{
+		Name: "builtin-ssh",
+		Docker: map[string]string{
+			keyHost:         "ssh://me@example.com",
+			keySSHTransport: "builtin",
+		},
+	}))
+	meta, err := cli.ContextStore().GetContextMetadata("ssh")
+	assert.NilError(t, err)
+	conn, err := docker.ConnectionFromContext(meta)
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(&ssh.Options{
+		IdentityFile: "/home/me/.ssh/id_ed25519",
+		JumpHosts:    []string{"bastion1", "bastion2"},
+		Config:       []string{"ConnectTimeout=5", "ServerAliveInterval=30"},
+	}, conn.SSH))
+	meta, err = cli.ContextStore().GetContextMetadata("builtin-ssh")
+	assert.NilError(t, err)
+	conn, err = docker.ConnectionFromContext(meta)
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(&ssh.Options{Transport: ssh.TransportBuiltin}, conn.SSH))
+}
+
+func TestCreateConnectionHelper(t *testing.T) {
+	cli, cleanup := makeFakeCli(t)
+	defer cleanup()
+
+	cli.ConfigFile().ConnectionHelpers = map[string]string{
+		"dind":         "docker-connection-dind",
+		"kubectl-prod": "kubectl-docker-connect --context prod",
+	}
+	assert.NilError(t, RunCreate(cli, &CreateOptions{
+		Name:   "dind",
+		Docker: map[string]string{keyHost: "dind://builder"},
+	}))
+	assert.NilError(t, RunCreate(cli, &CreateOptions{
+		Name: "kubectl",
+		Docker: map[string]string{
+			keyHost:       "kubectl://default/docker-0",
+			keyConnHelper: "kubectl-prod",
+		},
+	}))
+	meta, err := cli.ContextStore().GetContextMetadata("kubectl")
+	assert.NilError(t, err)
+	conn, err := docker.ConnectionFromContext(meta)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal("kubectl-prod", conn.ConnectionHelper))
+
+	err = RunCreate(cli, &CreateOptions{
+		Name: "unknown",
+		Docker: map[string]string{
+			keyHost:       "kubectl://default/docker-0",
+			keyConnHelper: "kubectl-staging",
+		},
+	})
+	assert.Check(t, is.ErrorContains(err, `connection helper "kubectl-staging" is not configured`))
+	err = RunCreate(cli, &CreateOptions{
+		Name: "command",
+		Docker: map[string]string{
+			keyHost:       "kubectl://default/docker-0",
+			keyConnHelper: "kubectl-docker-connect --context prod",
+		},
+	})
+	assert.Check(t, is.ErrorContains(err, "invalid connection helper name"))
+}
+
 func TestCreateOrchestratorSwarm(t *testing.T) {
 	cli, cleanup := makeFakeCli(t)
 	defer cleanup()
@@ -138,6 +262,25 @@ func TestCreateOrchestratorEmpty(t *testing.T) {
 	assert.NilError(t, err)
 }
 
+func TestCreateContentTrustPolicy(t *testing.T) {
+	cli, cleanup := makeFakeCli(t)
+	defer cleanup()
+
+	err := RunCreate(cli, &CreateOptions{
+		Name:               "test",
+		ContentTrustPolicy: "policy.json",
+		Docker:             map[string]string{},
+	})
+	assert.NilError(t, err)
+	cwd, err := os.Getwd()
+	assert.NilError(t, err)
+	c, err := cli.ContextStore().GetContextMetadata("test")
+	assert.NilError(t, err)
+	dockerContext, err := command.GetDockerContext(c)
+	assert.NilError(t, err)
+	assert.Equal(t, filepath.Join(cwd, "policy.json"), dockerContext.ContentTrustPolicy)
+}
+
 func validateTestKubeEndpoint(t *testing.T, s store.Store, name string) {
 	t.Helper()
 	ctxMetadata, err := s.GetContextMetadata(name)
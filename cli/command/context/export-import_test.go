@@ -2,14 +2,27 @@ package context
 
 import (
 	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/connhelper/ssh"
+	"github.com/docker/cli/cli/context/docker"
+	"github.com/docker/cli/cli/context/store"
 	"github.com/docker/cli/cli/streams"
 	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+	"gotest.tools/env"
 )
 
 func TestExportImportWithFile(t *testing.T) {
@@ -108,3 +121,180 @@ func TestExportExistingFile(t *testing.T) {
 	err = RunExport(cli, &ExportOptions{ContextName: "test", Dest: contextFile})
 	assert.Assert(t, os.IsExist(err))
 }
+
+// writeTestKeys writes an RSA key pair as rsa-key.pem and rsa-pub.pem, and an
+// ECDSA key pair as ec-key.pem and ec-pub.pem, in the directory
+func writeTestKeys(t *testing.T, dir string) {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NilError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+	ecKeyDER, err := x509.MarshalECPrivateKey(ecKey)
+	assert.NilError(t, err)
+	rsaPubDER, err := x509.MarshalPKIXPublicKey(rsaKey.Public())
+	assert.NilError(t, err)
+	ecPubDER, err := x509.MarshalPKIXPublicKey(ecKey.Public())
+	assert.NilError(t, err)
+	for name, block := range map[string]*pem.Block{
+		"rsa-key.pem": {Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)},
+		"rsa-pub.pem": {Type: "PUBLIC KEY", Bytes: rsaPubDER},
+		"ec-key.pem":  {Type: "EC PRIVATE KEY", Bytes: ecKeyDER},
+		"ec-pub.pem":  {Type: "PUBLIC KEY", Bytes: ecPubDER},
+	} {
+		assert.NilError(t, ioutil.WriteFile(filepath.Join(dir, name), pem.EncodeToMemory(block), 0600))
+	}
+}
+
+func TestExportImportEncrypted(t *testing.T) {
+	contextDir, err := ioutil.TempDir("", t.Name()+"context")
+	assert.NilError(t, err)
+	defer os.RemoveAll(contextDir)
+	writeTestKeys(t, contextDir)
+	cli, cleanup := makeFakeCli(t)
+	defer cleanup()
+	createTestContextWithKube(t, cli)
+	defer env.Patch(t, passphraseEnvVar, "secret")()
+
+	passphraseFile := filepath.Join(contextDir, "passphrase")
+	assert.NilError(t, RunExport(cli, &ExportOptions{ContextName: "test", Dest: passphraseFile, Encrypt: true}))
+	recipientFile := filepath.Join(contextDir, "recipient")
+	assert.NilError(t, RunExport(cli, &ExportOptions{ContextName: "test", Dest: recipientFile, Recipient: filepath.Join(contextDir, "rsa-pub.pem")}))
+
+	assert.NilError(t, RunImport(cli, "passphrase", passphraseFile))
+	err = runImport(cli, "recipient", recipientFile, importOptions{})
+	assert.Check(t, is.Error(err, "context archive is encrypted for a recipient key, a decryption key is required"))
+	assert.NilError(t, runImport(cli, "recipient", recipientFile, importOptions{decryptKey: filepath.Join(contextDir, "rsa-key.pem")}))
+	for _, name := range []string{"passphrase", "recipient"} {
+		validateTestKubeEndpoint(t, cli.ContextStore(), name)
+	}
+
+	defer env.Patch(t, passphraseEnvVar, "wrong")()
+	err = RunImport(cli, "wrong", passphraseFile)
+	assert.Check(t, store.IsErrArchiveTampered(err))
+}
+
+func TestExportImportSigned(t *testing.T) {
+	contextDir, err := ioutil.TempDir("", t.Name()+"context")
+	assert.NilError(t, err)
+	defer os.RemoveAll(contextDir)
+	writeTestKeys(t, contextDir)
+	cli, cleanup := makeFakeCli(t)
+	defer cleanup()
+	createTestContextWithKube(t, cli)
+
+	contextFile := filepath.Join(contextDir, "exported")
+	assert.NilError(t, RunExport(cli, &ExportOptions{ContextName: "test", Dest: contextFile, SignKey: filepath.Join(contextDir, "ec-key.pem")}))
+
+	cli.ErrBuffer().Reset()
+	assert.NilError(t, RunImport(cli, "unverified", contextFile))
+	assert.Check(t, is.Contains(cli.ErrBuffer().String(), "WARNING! The context archive is signed, but its signature was not verified"))
+	err = runImport(cli, "other-key", contextFile, importOptions{verifyKey: filepath.Join(contextDir, "rsa-pub.pem")})
+	assert.Check(t, store.IsErrArchiveTampered(err))
+	assert.NilError(t, runImport(cli, "verified", contextFile, importOptions{verifyKey: filepath.Join(contextDir, "ec-pub.pem")}))
+	validateTestKubeEndpoint(t, cli.ContextStore(), "verified")
+
+	// tamper with the archive
+	data, err := ioutil.ReadFile(contextFile)
+	assert.NilError(t, err)
+	var archive map[string]interface{}
+	assert.NilError(t, json.Unmarshal(data, &archive))
+	payload := archive["Payload"].(string)
+	archive["Payload"] = "A" + payload[1:]
+	if payload[0] == 'A' {
+		archive["Payload"] = "B" + payload[1:]
+	}
+	data, err = json.Marshal(archive)
+	assert.NilError(t, err)
+	tamperedFile := filepath.Join(contextDir, "tampered")
+	assert.NilError(t, ioutil.WriteFile(tamperedFile, data, 0600))
+	err = runImport(cli, "tampered", tamperedFile, importOptions{verifyKey: filepath.Join(contextDir, "ec-pub.pem")})
+	assert.Check(t, is.ErrorContains(err, "context archive signature verification failed: the archive has been tampered with"))
+
+	plainFile := filepath.Join(contextDir, "plain")
+	assert.NilError(t, RunExport(cli, &ExportOptions{ContextName: "test", Dest: plainFile}))
+	err = runImport(cli, "plain", plainFile, importOptions{verifyKey: filepath.Join(contextDir, "ec-pub.pem")})
+	assert.Check(t, is.Error(err, "context archive is not encrypted nor signed"))
+}
+
+func TestImportUnsafeDockerEndpoint(t *testing.T) {
+	contextDir, err := ioutil.TempDir("", t.Name()+"context")
+	assert.NilError(t, err)
+	defer os.RemoveAll(contextDir)
+	cli, cleanup := makeFakeCli(t)
+	defer cleanup()
+	s := cli.ContextStore()
+
+	testCases := []struct {
+		name          string
+		host          string
+		connection    docker.ConnectionMeta
+		expectedError string
+	}{
+		{
+			name:       "helper-name",
+			host:       "kubectl://default/docker-0",
+			connection: docker.ConnectionMeta{ConnectionHelper: "kubectl-prod"},
+		},
+		{
+			name:          "helper-command",
+			host:          "kubectl://default/docker-0",
+			connection:    docker.ConnectionMeta{ConnectionHelper: "sh -c 'touch /tmp/pwned'"},
+			expectedError: "invalid connection helper name",
+		},
+		{
+			name:          "ssh-options",
+			host:          "ssh://me@example.com",
+			connection:    docker.ConnectionMeta{SSH: &ssh.Options{Config: []string{"ProxyCommand=touch /tmp/pwned"}}},
+			expectedError: "the ssh options ProxyCommand of the docker endpoint aren't allowed",
+		},
+		{
+			name:          "ssh-options-space",
+			host:          "ssh://me@example.com",
+			connection:    docker.ConnectionMeta{SSH: &ssh.Options{Config: []string{"ProxyCommand touch /tmp/pwned=y"}}},
+			expectedError: "the ssh options ProxyCommand of the docker endpoint aren't allowed",
+		},
+		{
+			name:          "ssh-options-tab",
+			host:          "ssh://me@example.com",
+			connection:    docker.ConnectionMeta{SSH: &ssh.Options{Config: []string{"ProxyCommand\ttouch /tmp/pwned=y"}}},
+			expectedError: "the ssh options ProxyCommand of the docker endpoint aren't allowed",
+		},
+		{
+			name:       "ssh-safe-options",
+			host:       "ssh://me@example.com?option=ConnectTimeout%3D5",
+			connection: docker.ConnectionMeta{SSH: &ssh.Options{Config: []string{"ServerAliveInterval=30"}}},
+		},
+		{
+			name:          "ssh-url-options",
+			host:          "ssh://me@example.com?option=LocalCommand%3Dtouch%20/tmp/pwned&option=PermitLocalCommand%3Dyes",
+			expectedError: "the ssh options LocalCommand, PermitLocalCommand of the docker endpoint aren't allowed",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			endpoints := map[string]interface{}{
+				docker.DockerEndpoint: docker.EndpointMeta{Host: tc.host},
+			}
+			if !tc.connection.IsZero() {
+				endpoints[docker.ConnectionEndpoint] = tc.connection
+			}
+			assert.NilError(t, s.CreateOrUpdateContext(store.ContextMetadata{
+				Name:      tc.name,
+				Metadata:  command.DockerContext{},
+				Endpoints: endpoints,
+			}))
+			file := filepath.Join(contextDir, tc.name)
+			assert.NilError(t, RunExport(cli, &ExportOptions{ContextName: tc.name, Dest: file}))
+
+			err := RunImport(cli, tc.name+"-imported", file)
+			if tc.expectedError == "" {
+				assert.NilError(t, err)
+				return
+			}
+			assert.Check(t, is.ErrorContains(err, tc.expectedError))
+			_, err = s.GetContextMetadata(tc.name + "-imported")
+			assert.Check(t, store.IsErrContextDoesNotExist(err))
+		})
+	}
+}
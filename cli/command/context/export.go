@@ -20,6 +20,9 @@ type ExportOptions struct {
 	Kubeconfig  bool
 	ContextName string
 	Dest        string
+	Encrypt     bool
+	Recipient   string
+	SignKey     string
 }
 
 func newExportCommand(dockerCli command.Cli) *cobra.Command {
@@ -46,6 +49,9 @@ func newExportCommand(dockerCli command.Cli) *cobra.Command {
 
 	flags := cmd.Flags()
 	flags.BoolVar(&opts.Kubeconfig, "kubeconfig", false, "Export as a kubeconfig file")
+	flags.BoolVar(&opts.Encrypt, "encrypt", false, "Encrypt the archive with a passphrase")
+	flags.StringVar(&opts.Recipient, "recipient", "", "Encrypt the archive for the owner of a PEM encoded RSA public key or certificate")
+	flags.StringVar(&opts.SignKey, "sign-key", "", "Sign the archive with a PEM encoded RSA or ECDSA private key")
 	return cmd
 }
 
@@ -84,7 +90,14 @@ func RunExport(dockerCli command.Cli, opts *ExportOptions) error {
 	if err != nil {
 		return err
 	}
+	sealed := opts.Encrypt || opts.Recipient != "" || opts.SignKey != ""
+	if opts.Kubeconfig && sealed {
+		return errors.New("a kubeconfig file can't be encrypted or signed")
+	}
 	if !opts.Kubeconfig {
+		if sealed {
+			return exportSealed(dockerCli, opts)
+		}
 		reader := store.Export(opts.ContextName, dockerCli.ContextStore())
 		defer reader.Close()
 		return writeTo(dockerCli, reader, opts.Dest)
@@ -108,3 +121,33 @@ func RunExport(dockerCli command.Cli, opts *ExportOptions) error {
 	}
 	return writeTo(dockerCli, bytes.NewBuffer(data), opts.Dest)
 }
+
+func exportSealed(dockerCli command.Cli, opts *ExportOptions) error {
+	if opts.Encrypt && opts.Recipient != "" {
+		return errors.New("conflicting options: either specify --encrypt or --recipient, not both")
+	}
+	var (
+		sealOpts store.SealOptions
+		err      error
+	)
+	if opts.Encrypt {
+		if sealOpts.Passphrase, err = readPassphrase(dockerCli, true); err != nil {
+			return err
+		}
+	}
+	if opts.Recipient != "" {
+		if sealOpts.Recipient, err = loadPublicKey(opts.Recipient); err != nil {
+			return err
+		}
+	}
+	if opts.SignKey != "" {
+		if sealOpts.Signer, err = loadPrivateKey(opts.SignKey); err != nil {
+			return err
+		}
+	}
+	data, err := store.ExportSealed(opts.ContextName, dockerCli.ContextStore(), sealOpts)
+	if err != nil {
+		return err
+	}
+	return writeTo(dockerCli, bytes.NewReader(data), opts.Dest)
+}
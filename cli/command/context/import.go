@@ -1,30 +1,102 @@
 package context
 
 import (
+	"bufio"
+	"crypto"
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	"github.com/docker/cli/cli"
 	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/connhelper"
+	"github.com/docker/cli/cli/connhelper/ssh"
+	"github.com/docker/cli/cli/context/docker"
 	"github.com/docker/cli/cli/context/store"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
+type importOptions struct {
+	fromEnv     bool
+	fromMachine string
+	kubeconfig  bool
+	kube        kubeconfigImportOptions
+	decryptKey  string
+	verifyKey   string
+}
+
 func newImportCommand(dockerCli command.Cli) *cobra.Command {
+	var opts importOptions
 	cmd := &cobra.Command{
-		Use:   "import CONTEXT FILE|-",
-		Short: "Import a context from a tar file",
-		Args:  cli.ExactArgs(2),
+		Use:   "import [OPTIONS] CONTEXT [FILE|-]",
+		Short: "Import a context from a tar file, a Kubeconfig file, the environment or a docker-machine host",
+		Args:  cli.RequiresRangeArgs(1, 2),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return RunImport(dockerCli, args[0], args[1])
+			kubeOptionSet := opts.kube.kubeContext != "" || opts.kube.cluster != "" || opts.kube.user != "" || opts.kube.namespace != "" || opts.kube.allContexts
+			switch {
+			case kubeOptionSet && !opts.kubeconfig:
+				return errors.New("--kube-context, --kube-cluster, --kube-user, --kube-namespace and --all-kube-contexts require --kubeconfig")
+			case opts.kubeconfig && (opts.fromEnv || opts.fromMachine != ""):
+				return errors.New("conflicting options: --kubeconfig can't be used with --from-env or --from-machine")
+			case opts.kubeconfig && len(args) < 2:
+				return errors.New("a Kubeconfig file to import must be specified")
+			case opts.kubeconfig && args[1] == "-":
+				return errors.New("a Kubeconfig file can't be imported from stdin")
+			case opts.kubeconfig:
+				opts.kube.kubeconfig = args[1]
+				return importKubeconfig(dockerCli, args[0], opts.kube)
+			case opts.fromEnv && opts.fromMachine != "":
+				return errors.New("conflicting options: either specify --from-env or --from-machine, not both")
+			case (opts.fromEnv || opts.fromMachine != "") && len(args) > 1:
+				return errors.New("a file can't be imported with --from-env or --from-machine")
+			case opts.fromEnv:
+				config, err := dockerConfigFromEnv()
+				if err != nil {
+					return errors.Wrap(err, "unable to import the environment")
+				}
+				return importEndpoints(dockerCli, &CreateOptions{
+					Name:        args[0],
+					Description: "Imported from the environment",
+					Docker:      config,
+				})
+			case opts.fromMachine != "":
+				config, err := dockerConfigFromMachine(opts.fromMachine)
+				if err != nil {
+					return err
+				}
+				return importEndpoints(dockerCli, &CreateOptions{
+					Name:        args[0],
+					Description: fmt.Sprintf("Imported from docker-machine host %q", opts.fromMachine),
+					Docker:      config,
+				})
+			case len(args) < 2:
+				return errors.New("a file to import must be specified, or one of --from-env or --from-machine")
+			}
+			return runImport(dockerCli, args[0], args[1], opts)
 		},
 	}
+	flags := cmd.Flags()
+	flags.BoolVar(&opts.fromEnv, "from-env", false, "Import the Docker endpoint set by the DOCKER_HOST, DOCKER_TLS_VERIFY and DOCKER_CERT_PATH environment variables")
+	flags.StringVar(&opts.fromMachine, "from-machine", "", "Import the Docker endpoint of a docker-machine host")
+	flags.BoolVar(&opts.kubeconfig, "kubeconfig", false, "Import the Kubernetes endpoint of a Kubeconfig file")
+	flags.StringVar(&opts.kube.kubeContext, "kube-context", "", "Kubernetes context of the Kubeconfig file to import (default: its current context)")
+	flags.StringVar(&opts.kube.cluster, "kube-cluster", "", "Override the Kubernetes cluster of the imported context")
+	flags.StringVar(&opts.kube.user, "kube-user", "", "Override the Kubernetes user of the imported context")
+	flags.StringVar(&opts.kube.namespace, "kube-namespace", "", "Override the Kubernetes namespace of the imported context")
+	flags.BoolVar(&opts.kube.allContexts, "all-kube-contexts", false, "Import each context of the Kubeconfig file as a context named CONTEXT-<kube context>")
+	flags.StringVar(&opts.decryptKey, "decrypt-key", "", "PEM encoded RSA private key to decrypt an archive encrypted for its owner")
+	flags.StringVar(&opts.verifyKey, "verify-key", "", "Require the archive to be signed with the private key of a PEM encoded public key or certificate")
 	return cmd
 }
 
 // RunImport imports a Docker context
 func RunImport(dockerCli command.Cli, name string, source string) error {
+	return runImport(dockerCli, name, source, importOptions{})
+}
+
+func runImport(dockerCli command.Cli, name string, source string, opts importOptions) error {
 	if err := checkContextNameForCreation(dockerCli.ContextStore(), name); err != nil {
 		return err
 	}
@@ -40,10 +112,107 @@ func RunImport(dockerCli command.Cli, name string, source string) error {
 		reader = f
 	}
 
-	if err := store.Import(name, dockerCli.ContextStore(), reader); err != nil {
+	buffered := bufio.NewReader(reader)
+	reader = buffered
+	if store.IsSealed(buffered) {
+		unsealed, err := unseal(dockerCli, buffered, opts)
+		if err != nil {
+			return err
+		}
+		reader = unsealed
+	} else if opts.decryptKey != "" || opts.verifyKey != "" {
+		return errors.New("context archive is not encrypted nor signed")
+	}
+	s := dockerCli.ContextStore()
+	if err := store.Import(name, s, reader); err != nil {
 		return err
 	}
+	if err := checkImportedContext(s, name); err != nil {
+		if rmErr := s.RemoveContext(name); rmErr != nil {
+			return errors.Wrapf(err, "failed to remove the imported context: %v", rmErr)
+		}
+		return errors.Wrap(err, "the context can't be imported")
+	}
 	fmt.Fprintln(dockerCli.Out(), name)
 	fmt.Fprintf(dockerCli.Err(), "Successfully imported context %q\n", name)
 	return nil
 }
+
+// checkImportedContext rejects an imported context whose docker endpoint would
+// run local commands: a context archive can come from anyone, so its
+// connection helper must be a valid name of the connectionHelpers of the
+// configuration file, and its ssh options can't run commands or load code.
+func checkImportedContext(s store.Store, name string) error {
+	meta, err := s.GetContextMetadata(name)
+	if err != nil {
+		return err
+	}
+	if _, ok := meta.Endpoints[docker.DockerEndpoint]; !ok {
+		return nil
+	}
+	ep, err := docker.EndpointFromContext(meta)
+	if err != nil {
+		return err
+	}
+	conn, err := docker.ConnectionFromContext(meta)
+	if err != nil {
+		return err
+	}
+	if conn.ConnectionHelper != "" {
+		if err := connhelper.ValidateHelperName(conn.ConnectionHelper); err != nil {
+			return err
+		}
+	}
+	var sshOpts ssh.Options
+	if conn.SSH != nil {
+		sshOpts = *conn.SSH
+	}
+	if strings.HasPrefix(ep.Host, "ssh://") {
+		sp, err := ssh.ParseURL(ep.Host)
+		if err != nil {
+			return err
+		}
+		sshOpts.Merge(sp.Options)
+	}
+	if err := sshOpts.Validate(); err != nil {
+		return err
+	}
+	if unsafe := sshOpts.UnsafeOptions(); len(unsafe) > 0 {
+		return errors.Errorf("the ssh options %s of the docker endpoint aren't allowed in imported contexts, as they can run local commands", strings.Join(unsafe, ", "))
+	}
+	return nil
+}
+
+func unseal(dockerCli command.Cli, reader io.Reader, opts importOptions) (io.Reader, error) {
+	unsealOpts := store.UnsealOptions{
+		Passphrase: func() ([]byte, error) {
+			return readPassphrase(dockerCli, false)
+		},
+	}
+	if opts.decryptKey != "" {
+		key, err := loadPrivateKey(opts.decryptKey)
+		if err != nil {
+			return nil, err
+		}
+		decrypter, ok := key.(crypto.Decrypter)
+		if !ok {
+			return nil, errors.Errorf("%s: the key can't be used for decryption", opts.decryptKey)
+		}
+		unsealOpts.Decrypter = decrypter
+	}
+	if opts.verifyKey != "" {
+		key, err := loadPublicKey(opts.verifyKey)
+		if err != nil {
+			return nil, err
+		}
+		unsealOpts.Verifier = key
+	}
+	unsealed, signed, err := store.Unseal(reader, unsealOpts)
+	if err != nil {
+		return nil, err
+	}
+	if signed && opts.verifyKey == "" {
+		fmt.Fprintln(dockerCli.Err(), "WARNING! The context archive is signed, but its signature was not verified. Use --verify-key to verify it.")
+	}
+	return unsealed, nil
+}
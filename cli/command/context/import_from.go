@@ -0,0 +1,137 @@
+package context
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/docker/cli/cli/command"
+	cliconfig "github.com/docker/cli/cli/config"
+	"github.com/docker/docker/pkg/homedir"
+	"github.com/pkg/errors"
+)
+
+// defaultMachineEnginePort is the port of the Docker engine of docker-machine
+// hosts whose driver doesn't set one
+const defaultMachineEnginePort = 2376
+
+// dockerConfigFromEnv returns the Docker endpoint configuration defined by the
+// DOCKER_HOST, DOCKER_TLS_VERIFY and DOCKER_CERT_PATH environment variables,
+// as the client resolves it without a context.
+func dockerConfigFromEnv() (map[string]string, error) {
+	host := os.Getenv("DOCKER_HOST")
+	if host == "" {
+		return nil, errors.New("DOCKER_HOST is not set")
+	}
+	config := map[string]string{keyHost: host}
+	tlsVerify := os.Getenv("DOCKER_TLS_VERIFY") != ""
+	if !tlsVerify && os.Getenv("DOCKER_TLS") == "" {
+		return config, nil
+	}
+	certPath := os.Getenv("DOCKER_CERT_PATH")
+	if certPath == "" {
+		certPath = cliconfig.Dir()
+	}
+	for key, file := range map[string]string{keyCA: "ca.pem", keyCert: "cert.pem", keyKey: "key.pem"} {
+		path := filepath.Join(certPath, file)
+		_, err := os.Stat(path)
+		switch {
+		case err == nil:
+			config[key] = path
+		case key == keyCA && tlsVerify:
+			// the CA is required to verify the daemon
+			return nil, errors.Wrap(err, "invalid DOCKER_CERT_PATH")
+		}
+	}
+	if !tlsVerify {
+		config[keySkipTLSVerify] = "true"
+	}
+	return config, nil
+}
+
+// machineConfig is the part of the configuration of a docker-machine host
+// needed to connect to its Docker engine.
+type machineConfig struct {
+	Name   string
+	Driver struct {
+		IPAddress  string
+		EnginePort int
+	}
+	HostOptions struct {
+		EngineOptions struct {
+			TLSVerify bool `json:"TlsVerify"`
+		}
+		AuthOptions struct {
+			CaCertPath     string
+			ClientCertPath string
+			ClientKeyPath  string
+		}
+	}
+}
+
+// machineStoragePath returns the directory where docker-machine stores its
+// hosts
+func machineStoragePath() string {
+	if path := os.Getenv("MACHINE_STORAGE_PATH"); path != "" {
+		return path
+	}
+	return filepath.Join(homedir.Get(), ".docker", "machine")
+}
+
+// dockerConfigFromMachine returns the Docker endpoint configuration of a
+// docker-machine host, read from its configuration file.
+func dockerConfigFromMachine(name string) (map[string]string, error) {
+	filename := filepath.Join(machineStoragePath(), "machines", name, "config.json")
+	content, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil, errors.Errorf("docker-machine host %q does not exist", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var machine machineConfig
+	if err := json.Unmarshal(content, &machine); err != nil {
+		return nil, errors.Wrapf(err, "invalid configuration of docker-machine host %q", name)
+	}
+	if machine.Driver.IPAddress == "" {
+		return nil, errors.Errorf("docker-machine host %q has no IP address, it may not be running", name)
+	}
+	port := machine.Driver.EnginePort
+	if port == 0 {
+		port = defaultMachineEnginePort
+	}
+	auth := machine.HostOptions.AuthOptions
+	return map[string]string{
+		keyHost:          "tcp://" + net.JoinHostPort(machine.Driver.IPAddress, strconv.Itoa(port)),
+		keyCA:            auth.CaCertPath,
+		keyCert:          auth.ClientCertPath,
+		keyKey:           auth.ClientKeyPath,
+		keySkipTLSVerify: strconv.FormatBool(!machine.HostOptions.EngineOptions.TLSVerify),
+	}, nil
+}
+
+// importEndpoints creates a context as "docker context create" does, copying
+// the TLS material of its endpoints into the context store.
+func importEndpoints(dockerCli command.Cli, o *CreateOptions) error {
+	s := dockerCli.ContextStore()
+	if err := checkContextNameForCreation(s, o.Name); err != nil {
+		return err
+	}
+	contextMetadata, contextTLSData, err := newContextMetadataAndTLS(dockerCli, o)
+	if err != nil {
+		return err
+	}
+	if err := s.CreateOrUpdateContext(contextMetadata); err != nil {
+		return err
+	}
+	if err := s.ResetContextTLSMaterial(o.Name, &contextTLSData); err != nil {
+		return err
+	}
+	fmt.Fprintln(dockerCli.Out(), o.Name)
+	fmt.Fprintf(dockerCli.Err(), "Successfully imported context %q\n", o.Name)
+	return nil
+}
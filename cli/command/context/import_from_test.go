@@ -0,0 +1,172 @@
+package context
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/context/docker"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+	"gotest.tools/env"
+	"gotest.tools/fs"
+)
+
+// writeTestTLSFiles writes a CA, a client certificate and its key in the
+// directory, as ca.pem, cert.pem and key.pem.
+func writeTestTLSFiles(t *testing.T, dir string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NilError(t, err)
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.NilError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(dir, "ca.pem"), certPEM, 0644))
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(dir, "cert.pem"), certPEM, 0644))
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(dir, "key.pem"), keyPEM, 0600))
+}
+
+func TestImportFromEnv(t *testing.T) {
+	certDir := fs.NewDir(t, t.Name())
+	defer certDir.Remove()
+	writeTestTLSFiles(t, certDir.Path())
+	defer env.PatchAll(t, map[string]string{
+		"DOCKER_HOST":       "tcp://192.168.99.100:2376",
+		"DOCKER_TLS_VERIFY": "1",
+		"DOCKER_CERT_PATH":  certDir.Path(),
+	})()
+
+	cli, cleanup := makeFakeCli(t)
+	defer cleanup()
+	cmd := newImportCommand(cli)
+	cmd.SetArgs([]string{"--from-env", "imported"})
+	assert.NilError(t, cmd.Execute())
+	assert.Check(t, is.Equal("imported\n", cli.OutBuffer().String()))
+	assert.Check(t, is.Equal("Successfully imported context \"imported\"\n", cli.ErrBuffer().String()))
+
+	meta, err := cli.ContextStore().GetContextMetadata("imported")
+	assert.NilError(t, err)
+	ep, err := docker.EndpointFromContext(meta)
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(docker.EndpointMeta{Host: "tcp://192.168.99.100:2376"}, ep))
+	files, err := cli.ContextStore().ListContextTLSFiles("imported")
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual([]string{"ca.pem", "cert.pem", "key.pem"}, []string(files[docker.DockerEndpoint])))
+}
+
+func TestImportFromEnvWithoutTLS(t *testing.T) {
+	defer env.PatchAll(t, map[string]string{
+		"DOCKER_HOST":       "tcp://192.168.99.100:2375",
+		"DOCKER_TLS_VERIFY": "",
+		"DOCKER_TLS":        "",
+	})()
+
+	cli, cleanup := makeFakeCli(t)
+	defer cleanup()
+	cmd := newImportCommand(cli)
+	cmd.SetArgs([]string{"--from-env", "imported"})
+	assert.NilError(t, cmd.Execute())
+	meta, err := cli.ContextStore().GetContextMetadata("imported")
+	assert.NilError(t, err)
+	ep, err := docker.EndpointFromContext(meta)
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(docker.EndpointMeta{Host: "tcp://192.168.99.100:2375"}, ep))
+}
+
+func TestImportFromMachine(t *testing.T) {
+	machineDir := fs.NewDir(t, t.Name(),
+		fs.WithDir("machines",
+			fs.WithDir("dev"),
+			fs.WithDir("stopped", fs.WithFile("config.json", `{"Name": "stopped", "Driver": {}}`)),
+		),
+	)
+	defer machineDir.Remove()
+	devDir := machineDir.Join("machines", "dev")
+	writeTestTLSFiles(t, devDir)
+	config := `{
+	"Name": "dev",
+	"DriverName": "virtualbox",
+	"Driver": {"IPAddress": "192.168.99.101", "MachineName": "dev"},
+	"HostOptions": {
+		"EngineOptions": {"TlsVerify": true},
+		"AuthOptions": {
+			"CaCertPath": "` + filepath.ToSlash(filepath.Join(devDir, "ca.pem")) + `",
+			"ClientCertPath": "` + filepath.ToSlash(filepath.Join(devDir, "cert.pem")) + `",
+			"ClientKeyPath": "` + filepath.ToSlash(filepath.Join(devDir, "key.pem")) + `"
+		}
+	}
+}`
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(devDir, "config.json"), []byte(config), 0644))
+	defer env.Patch(t, "MACHINE_STORAGE_PATH", machineDir.Path())()
+
+	cli, cleanup := makeFakeCli(t)
+	defer cleanup()
+	cmd := newImportCommand(cli)
+	cmd.SetArgs([]string{"--from-machine", "dev", "dev"})
+	assert.NilError(t, cmd.Execute())
+	meta, err := cli.ContextStore().GetContextMetadata("dev")
+	assert.NilError(t, err)
+	ep, err := docker.EndpointFromContext(meta)
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(docker.EndpointMeta{Host: "tcp://192.168.99.101:2376"}, ep))
+	dockerContext, err := command.GetDockerContext(meta)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(`Imported from docker-machine host "dev"`, dockerContext.Description))
+	files, err := cli.ContextStore().ListContextTLSFiles("dev")
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual([]string{"ca.pem", "cert.pem", "key.pem"}, []string(files[docker.DockerEndpoint])))
+
+	cmd = newImportCommand(cli)
+	cmd.SetArgs([]string{"--from-machine", "stopped", "stopped"})
+	assert.Check(t, is.Error(cmd.Execute(), `docker-machine host "stopped" has no IP address, it may not be running`))
+	cmd = newImportCommand(cli)
+	cmd.SetArgs([]string{"--from-machine", "missing", "missing"})
+	assert.Check(t, is.Error(cmd.Execute(), `docker-machine host "missing" does not exist`))
+}
+
+func TestImportArgs(t *testing.T) {
+	testCases := []struct {
+		args          []string
+		expectedError string
+	}{
+		{
+			args:          []string{"imported"},
+			expectedError: "a file to import must be specified, or one of --from-env or --from-machine",
+		},
+		{
+			args:          []string{"--from-env", "--from-machine", "dev", "imported"},
+			expectedError: "conflicting options: either specify --from-env or --from-machine, not both",
+		},
+		{
+			args:          []string{"--from-env", "imported", "file.tar"},
+			expectedError: "a file can't be imported with --from-env or --from-machine",
+		},
+	}
+	for _, tc := range testCases {
+		cli, cleanup := makeFakeCli(t)
+		cmd := newImportCommand(cli)
+		cmd.SetArgs(tc.args)
+		cmd.SetOutput(ioutil.Discard)
+		assert.Check(t, is.Error(cmd.Execute(), tc.expectedError))
+		cleanup()
+	}
+}
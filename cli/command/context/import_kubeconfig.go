@@ -0,0 +1,90 @@
+package context
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/context/kubernetes"
+	"github.com/pkg/errors"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// kubeconfigImportOptions are the options of the import of a Kubeconfig file
+type kubeconfigImportOptions struct {
+	kubeContext string
+	cluster     string
+	user        string
+	namespace   string
+	allContexts bool
+	kubeconfig  string
+}
+
+// invalidContextNameChars matches the characters of the name of a Kubernetes
+// context not allowed in the name of a Docker context
+var invalidContextNameChars = regexp.MustCompile("[^a-zA-Z0-9_.+-]+")
+
+// importKubeconfig creates a context with the Kubernetes endpoint of a
+// context of the Kubeconfig file, or a context for each of its contexts. The
+// Docker endpoint of the contexts is the current Docker endpoint.
+func importKubeconfig(dockerCli command.Cli, name string, opts kubeconfigImportOptions) error {
+	if !opts.allContexts {
+		return importKubeContext(dockerCli, name, opts)
+	}
+	if opts.kubeContext != "" {
+		return errors.New("conflicting options: either specify --kube-context or --all-kube-contexts, not both")
+	}
+	config, err := clientcmd.LoadFromFile(opts.kubeconfig)
+	if err != nil {
+		return err
+	}
+	if len(config.Contexts) == 0 {
+		return errors.Errorf("no contexts in %s", opts.kubeconfig)
+	}
+	var kubeContexts []string
+	for kubeContext := range config.Contexts {
+		kubeContexts = append(kubeContexts, kubeContext)
+	}
+	sort.Strings(kubeContexts)
+	var errs []string
+	for _, kubeContext := range kubeContexts {
+		contextOpts := opts
+		contextOpts.kubeContext = kubeContext
+		contextName := name + "-" + strings.Trim(invalidContextNameChars.ReplaceAllString(kubeContext, "-"), "-")
+		if err := importKubeContext(dockerCli, contextName, contextOpts); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", kubeContext, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Errorf("failed to import Kubernetes contexts:\n%s", strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+func importKubeContext(dockerCli command.Cli, name string, opts kubeconfigImportOptions) error {
+	ep, err := kubernetes.FromKubeConfigOverrides(opts.kubeconfig, &clientcmd.ConfigOverrides{
+		CurrentContext: opts.kubeContext,
+		Context: clientcmdapi.Context{
+			Cluster:   opts.cluster,
+			AuthInfo:  opts.user,
+			Namespace: opts.namespace,
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "unable to create kubernetes endpoint config")
+	}
+	description := "Imported from " + opts.kubeconfig
+	if opts.kubeContext != "" {
+		description = fmt.Sprintf("Imported from Kubernetes context %q of %s", opts.kubeContext, opts.kubeconfig)
+	}
+	return importEndpoints(dockerCli, &CreateOptions{
+		Name:                     name,
+		Description:              description,
+		DefaultStackOrchestrator: string(command.OrchestratorKubernetes),
+		Docker:                   map[string]string{keyFromCurrent: "true"},
+		kubernetesEndpoint:       &ep,
+	})
+}
@@ -0,0 +1,156 @@
+package context
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/context/docker"
+	"github.com/docker/cli/cli/context/kubernetes"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+	"gotest.tools/fs"
+)
+
+func writeTestKubeconfig(t *testing.T) *fs.Dir {
+	t.Helper()
+	dir := fs.NewDir(t, t.Name())
+	writeTestTLSFiles(t, dir.Path())
+	kubeconfig := `apiVersion: v1
+kind: Config
+clusters:
+- name: prod
+  cluster:
+    server: https://prod.example.com:6443
+    certificate-authority: ` + dir.Join("ca.pem") + `
+- name: staging
+  cluster:
+    server: https://staging.example.com:6443
+    insecure-skip-tls-verify: true
+users:
+- name: admin
+  user:
+    client-certificate: ` + dir.Join("cert.pem") + `
+    client-key: ` + dir.Join("key.pem") + `
+- name: viewer
+  user:
+    token: secret
+contexts:
+- name: prod-admin
+  context:
+    cluster: prod
+    user: admin
+    namespace: production
+- name: staging/viewer
+  context:
+    cluster: staging
+    user: viewer
+current-context: prod-admin
+`
+	assert.NilError(t, ioutil.WriteFile(dir.Join("config"), []byte(kubeconfig), 0600))
+	return dir
+}
+
+func TestImportKubeconfig(t *testing.T) {
+	dir := writeTestKubeconfig(t)
+	defer dir.Remove()
+	cli, cleanup := makeFakeCli(t)
+	defer cleanup()
+
+	cmd := newImportCommand(cli)
+	cmd.SetArgs([]string{"--kubeconfig", "prod", dir.Join("config")})
+	assert.NilError(t, cmd.Execute())
+	assert.Check(t, is.Equal("prod\n", cli.OutBuffer().String()))
+
+	meta, err := cli.ContextStore().GetContextMetadata("prod")
+	assert.NilError(t, err)
+	dockerContext, err := command.GetDockerContext(meta)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(command.OrchestratorKubernetes, dockerContext.StackOrchestrator))
+	_, err = docker.EndpointFromContext(meta)
+	assert.NilError(t, err)
+	kubeEP := kubernetes.EndpointFromContext(meta)
+	assert.Assert(t, kubeEP != nil)
+	assert.Check(t, is.Equal("https://prod.example.com:6443", kubeEP.Host))
+	assert.Check(t, is.Equal("production", kubeEP.DefaultNamespace))
+	files, err := cli.ContextStore().ListContextTLSFiles("prod")
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual([]string{"ca.pem", "cert.pem", "key.pem"}, []string(files[kubernetes.KubernetesEndpoint])))
+}
+
+func TestImportKubeconfigOverrides(t *testing.T) {
+	dir := writeTestKubeconfig(t)
+	defer dir.Remove()
+	cli, cleanup := makeFakeCli(t)
+	defer cleanup()
+
+	cmd := newImportCommand(cli)
+	cmd.SetArgs([]string{"--kubeconfig", "--kube-context", "prod-admin", "--kube-cluster", "staging", "--kube-user", "viewer", "--kube-namespace", "test", "staging", dir.Join("config")})
+	assert.NilError(t, cmd.Execute())
+	meta, err := cli.ContextStore().GetContextMetadata("staging")
+	assert.NilError(t, err)
+	kubeEP := kubernetes.EndpointFromContext(meta)
+	assert.Assert(t, kubeEP != nil)
+	assert.Check(t, is.Equal("https://staging.example.com:6443", kubeEP.Host))
+	assert.Check(t, kubeEP.SkipTLSVerify)
+	assert.Check(t, is.Equal("test", kubeEP.DefaultNamespace))
+	files, err := cli.ContextStore().ListContextTLSFiles("staging")
+	assert.NilError(t, err)
+	assert.Check(t, is.Len(files[kubernetes.KubernetesEndpoint], 0))
+}
+
+func TestImportAllKubeContexts(t *testing.T) {
+	dir := writeTestKubeconfig(t)
+	defer dir.Remove()
+	cli, cleanup := makeFakeCli(t)
+	defer cleanup()
+
+	cmd := newImportCommand(cli)
+	cmd.SetArgs([]string{"--kubeconfig", "--all-kube-contexts", "k8s", dir.Join("config")})
+	assert.NilError(t, cmd.Execute())
+	assert.Check(t, is.Equal("k8s-prod-admin\nk8s-staging-viewer\n", cli.OutBuffer().String()))
+	meta, err := cli.ContextStore().GetContextMetadata("k8s-staging-viewer")
+	assert.NilError(t, err)
+	kubeEP := kubernetes.EndpointFromContext(meta)
+	assert.Assert(t, kubeEP != nil)
+	assert.Check(t, is.Equal("https://staging.example.com:6443", kubeEP.Host))
+	dockerContext, err := command.GetDockerContext(meta)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(`Imported from Kubernetes context "staging/viewer" of `+dir.Join("config"), dockerContext.Description))
+}
+
+func TestImportKubeconfigArgs(t *testing.T) {
+	testCases := []struct {
+		args          []string
+		expectedError string
+	}{
+		{
+			args:          []string{"--kube-context", "prod", "imported", "config"},
+			expectedError: "--kube-context, --kube-cluster, --kube-user, --kube-namespace and --all-kube-contexts require --kubeconfig",
+		},
+		{
+			args:          []string{"--kubeconfig", "imported"},
+			expectedError: "a Kubeconfig file to import must be specified",
+		},
+		{
+			args:          []string{"--kubeconfig", "imported", "-"},
+			expectedError: "a Kubeconfig file can't be imported from stdin",
+		},
+		{
+			args:          []string{"--kubeconfig", "--from-env", "imported", "config"},
+			expectedError: "conflicting options: --kubeconfig can't be used with --from-env or --from-machine",
+		},
+		{
+			args:          []string{"--kubeconfig", "--all-kube-contexts", "--kube-context", "prod", "imported", "config"},
+			expectedError: "conflicting options: either specify --kube-context or --all-kube-contexts, not both",
+		},
+	}
+	for _, tc := range testCases {
+		cli, cleanup := makeFakeCli(t)
+		cmd := newImportCommand(cli)
+		cmd.SetArgs(tc.args)
+		cmd.SetOutput(ioutil.Discard)
+		assert.Check(t, is.Error(cmd.Execute(), tc.expectedError))
+		cleanup()
+	}
+}
@@ -2,6 +2,7 @@ package context
 
 import (
 	"errors"
+	"time"
 
 	"github.com/docker/cli/cli/command"
 	"github.com/docker/cli/cli/command/inspect"
@@ -10,8 +11,10 @@ import (
 )
 
 type inspectOptions struct {
-	format string
-	refs   []string
+	format       string
+	refs         []string
+	check        bool
+	checkTimeout time.Duration
 }
 
 // newInspectCommand creates a new cobra.Command for `docker image inspect`
@@ -35,10 +38,16 @@ func newInspectCommand(dockerCli command.Cli) *cobra.Command {
 
 	flags := cmd.Flags()
 	flags.StringVarP(&opts.format, "format", "f", "", "Format the output using the given Go template")
+	flags.BoolVar(&opts.check, "check", false, "Check whether the endpoints of the contexts are reachable")
+	flags.DurationVar(&opts.checkTimeout, "check-timeout", defaultCheckTimeout, "Timeout of the check of each endpoint")
 	return cmd
 }
 
 func runInspect(dockerCli command.Cli, opts inspectOptions) error {
+	var statuses map[string]*contextStatus
+	if opts.check {
+		statuses = checkContexts(dockerCli, opts.refs, opts.checkTimeout)
+	}
 	getRefFunc := func(ref string) (interface{}, []byte, error) {
 		if ref == "default" {
 			return nil, nil, errors.New(`context "default" cannot be inspected`)
@@ -55,6 +64,7 @@ func runInspect(dockerCli command.Cli, opts inspectOptions) error {
 			ContextMetadata: c,
 			TLSMaterial:     tlsListing,
 			Storage:         dockerCli.ContextStore().GetContextStorageInfo(ref),
+			Status:          statuses[ref],
 		}, nil, nil
 	}
 	return inspect.Inspect(dockerCli.Out(), opts.refs, opts.format, getRefFunc)
@@ -64,4 +74,5 @@ type contextWithTLSListing struct {
 	store.ContextMetadata
 	TLSMaterial map[string]store.EndpointFiles
 	Storage     store.ContextStorageInfo
+	Status      *contextStatus `json:",omitempty"`
 }
@@ -3,6 +3,7 @@ package context
 import (
 	"fmt"
 	"sort"
+	"time"
 
 	"github.com/docker/cli/cli"
 	"github.com/docker/cli/cli/command"
@@ -15,8 +16,10 @@ import (
 )
 
 type listOptions struct {
-	format string
-	quiet  bool
+	format       string
+	quiet        bool
+	check        bool
+	checkTimeout time.Duration
 }
 
 func newListCommand(dockerCli command.Cli) *cobra.Command {
@@ -34,6 +37,8 @@ func newListCommand(dockerCli command.Cli) *cobra.Command {
 	flags := cmd.Flags()
 	flags.StringVar(&opts.format, "format", "", "Pretty-print contexts using a Go template")
 	flags.BoolVarP(&opts.quiet, "quiet", "q", false, "Only show context names")
+	flags.BoolVar(&opts.check, "check", false, "Check whether the endpoints of the contexts are reachable")
+	flags.DurationVar(&opts.checkTimeout, "check-timeout", defaultCheckTimeout, "Timeout of the check of each endpoint")
 	return cmd
 }
 
@@ -41,6 +46,9 @@ func runList(dockerCli command.Cli, opts *listOptions) error {
 	if opts.format == "" {
 		opts.format = formatter.TableFormatKey
 	}
+	if opts.quiet {
+		opts.check = false
+	}
 	curContext := dockerCli.CurrentContext()
 	contextMap, err := dockerCli.ContextStore().ListContexts()
 	if err != nil {
@@ -97,13 +105,65 @@ func runList(dockerCli command.Cli, opts *listOptions) error {
 	sort.Slice(contexts, func(i, j int) bool {
 		return sortorder.NaturalLess(contexts[i].Name, contexts[j].Name)
 	})
-	return format(dockerCli, opts, contexts)
+	if opts.check {
+		checkListedContexts(dockerCli, opts.checkTimeout, contexts)
+	}
+	if err := format(dockerCli, opts, contexts); err != nil {
+		return err
+	}
+	if opts.format == formatter.TableFormatKey && !opts.quiet {
+		printContextSource(dockerCli)
+	}
+	return nil
+}
+
+// printContextSource tells what selected the current context, when it isn't
+// the one set with "docker context use"
+func printContextSource(dockerCli command.Cli) {
+	source := command.CurrentContextSource(dockerCli)
+	if source == "" || dockerCli.CurrentContext() == dockerCli.ConfigFile().CurrentContext {
+		return
+	}
+	name := dockerCli.CurrentContext()
+	if name == "" {
+		name = "default"
+	}
+	fmt.Fprintf(dockerCli.Err(), "Current context is %q, selected by %s\n", name, source)
+}
+
+// checkListedContexts checks the endpoints of the listed contexts, and sets
+// their status.
+func checkListedContexts(dockerCli command.Cli, timeout time.Duration, contexts []*formatter.ClientContext) {
+	var names []string
+	for _, c := range contexts {
+		if c.Name != "default" {
+			names = append(names, c.Name)
+		}
+	}
+	statuses := checkContexts(dockerCli, names, timeout)
+	for _, c := range contexts {
+		status, ok := statuses[c.Name]
+		if !ok {
+			if !c.Current {
+				// the endpoints of the default context are only known if
+				// it is the current context
+				continue
+			}
+			status = checkDefaultContext(dockerCli, timeout)
+		}
+		c.DockerStatus = status.Docker.String()
+		c.KubernetesStatus = status.Kubernetes.String()
+	}
 }
 
 func format(dockerCli command.Cli, opts *listOptions, contexts []*formatter.ClientContext) error {
+	contextFormat := formatter.NewClientContextFormat(opts.format, opts.quiet)
+	if opts.check && opts.format == formatter.TableFormatKey {
+		contextFormat = formatter.ClientContextCheckTableFormat
+	}
 	contextCtx := formatter.Context{
 		Output: dockerCli.Out(),
-		Format: formatter.NewClientContextFormat(opts.format, opts.quiet),
+		Format: contextFormat,
 	}
 	return formatter.ClientContextWrite(contextCtx, contexts)
 }
@@ -6,6 +6,7 @@ import (
 	"github.com/docker/cli/cli/command"
 	"github.com/docker/cli/cli/context/docker"
 	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
 	"gotest.tools/env"
 	"gotest.tools/golden"
 )
@@ -60,3 +61,25 @@ func TestListQuiet(t *testing.T) {
 	assert.NilError(t, runList(cli, &listOptions{quiet: true}))
 	golden.Assert(t, cli.OutBuffer().String(), "quiet-list.golden")
 }
+
+func TestListContextSource(t *testing.T) {
+	cli, cleanup := makeFakeCli(t)
+	defer cleanup()
+	createTestContextWithKubeAndSwarm(t, cli, "project", "all")
+	createTestContextWithKubeAndSwarm(t, cli, "global", "all")
+	cli.ConfigFile().CurrentContext = "global"
+	cli.SetCurrentContext("global")
+	cli.SetCurrentContextSource("the currentContext of config.json")
+	cli.ErrBuffer().Reset()
+	assert.NilError(t, runList(cli, &listOptions{}))
+	assert.Check(t, is.Equal("", cli.ErrBuffer().String()))
+
+	cli.SetCurrentContext("project")
+	cli.SetCurrentContextSource("the /home/me/project/.docker-context file")
+	assert.NilError(t, runList(cli, &listOptions{}))
+	assert.Check(t, is.Equal("Current context is \"project\", selected by the /home/me/project/.docker-context file\n", cli.ErrBuffer().String()))
+
+	cli.ErrBuffer().Reset()
+	assert.NilError(t, runList(cli, &listOptions{quiet: true}))
+	assert.Check(t, is.Equal("", cli.ErrBuffer().String()))
+}
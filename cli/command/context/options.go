@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/connhelper/ssh"
 	"github.com/docker/cli/cli/context"
 	"github.com/docker/cli/cli/context/docker"
 	"github.com/docker/cli/cli/context/kubernetes"
@@ -24,6 +25,11 @@ const (
 	keyCert          = "cert"
 	keyKey           = "key"
 	keySkipTLSVerify = "skip-tls-verify"
+	keySSHIdentity   = "ssh-identity"
+	keySSHJump       = "ssh-jump"
+	keySSHOptions    = "ssh-options"
+	keySSHTransport  = "ssh-transport"
+	keyConnHelper    = "connection-helper"
 	keyKubeconfig    = "config-file"
 	keyKubecontext   = "context-override"
 	keyKubenamespace = "namespace-override"
@@ -42,6 +48,11 @@ var (
 		keyCert:          {},
 		keyKey:           {},
 		keySkipTLSVerify: {},
+		keySSHIdentity:   {},
+		keySSHJump:       {},
+		keySSHOptions:    {},
+		keySSHTransport:  {},
+		keyConnHelper:    {},
 	}
 	allowedKubernetesConfigKeys = map[string]struct{}{
 		keyFromCurrent:   {},
@@ -74,6 +85,26 @@ var (
 			name:        keySkipTLSVerify,
 			description: "Skip TLS certificate validation",
 		},
+		{
+			name:        keySSHIdentity,
+			description: "Path to the private key of ssh:// hosts",
+		},
+		{
+			name:        keySSHJump,
+			description: "Comma-separated list of jump hosts to ssh:// hosts",
+		},
+		{
+			name:        keySSHOptions,
+			description: "Semicolon-separated list of KEY=VALUE ssh options",
+		},
+		{
+			name:        keySSHTransport,
+			description: "Connect to ssh:// hosts with the \"system\" ssh binary (default) or the \"builtin\" client",
+		},
+		{
+			name:        keyConnHelper,
+			description: "Name of the connection helper of the host in the connectionHelpers of the configuration file",
+		},
 	}
 	kubernetesConfigKeysDescriptions = []configKeyDescription{
 		{
@@ -142,6 +173,14 @@ func getDockerEndpoint(dockerCli command.Cli, config map[string]string) (docker.
 			SkipTLSVerify: skipTLSVerify,
 		},
 		TLSData: tlsData,
+		Connection: docker.ConnectionMeta{
+			SSH:              sshOptions(config),
+			ConnectionHelper: config[keyConnHelper],
+		},
+		ConnectionHelpers: dockerCli.ConfigFile().ConnectionHelpers,
+	}
+	if ep.Connection.SSH != nil && !strings.HasPrefix(ep.Host, "ssh://") {
+		return docker.Endpoint{}, errors.Errorf("%s, %s, %s and %s are only supported with ssh:// hosts", keySSHIdentity, keySSHJump, keySSHOptions, keySSHTransport)
 	}
 	// try to resolve a docker client, validating the configuration
 	opts, err := ep.ClientOpts()
@@ -154,12 +193,44 @@ func getDockerEndpoint(dockerCli command.Cli, config map[string]string) (docker.
 	return ep, nil
 }
 
-func getDockerEndpointMetadataAndTLS(dockerCli command.Cli, config map[string]string) (docker.EndpointMeta, *store.EndpointTLSData, error) {
+// sshOptions returns the options of the connection to ssh:// hosts set in the
+// docker endpoint config, if any
+func sshOptions(config map[string]string) *ssh.Options {
+	opts := ssh.Options{
+		IdentityFile: config[keySSHIdentity],
+		JumpHosts:    splitNonEmpty(config[keySSHJump], ","),
+		Config:       splitNonEmpty(config[keySSHOptions], ";"),
+		Transport:    config[keySSHTransport],
+	}
+	if opts.IdentityFile == "" && opts.JumpHosts == nil && opts.Config == nil && opts.Transport == "" {
+		return nil
+	}
+	return &opts
+}
+
+func splitNonEmpty(value, sep string) []string {
+	var result []string
+	for _, v := range strings.Split(value, sep) {
+		if v = strings.TrimSpace(v); v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// getDockerEndpointMetadataAndTLS returns the docker endpoint of the config,
+// the options of the connection to its host, nil if there are none, and its
+// TLS material
+func getDockerEndpointMetadataAndTLS(dockerCli command.Cli, config map[string]string) (docker.EndpointMeta, *docker.ConnectionMeta, *store.EndpointTLSData, error) {
 	ep, err := getDockerEndpoint(dockerCli, config)
 	if err != nil {
-		return docker.EndpointMeta{}, nil, err
+		return docker.EndpointMeta{}, nil, nil, err
+	}
+	var conn *docker.ConnectionMeta
+	if !ep.Connection.IsZero() {
+		conn = &ep.Connection
 	}
-	return ep.EndpointMeta, ep.TLSData.ToStoreTLSData(), nil
+	return ep.EndpointMeta, conn, ep.TLSData.ToStoreTLSData(), nil
 }
 
 func getKubernetesEndpoint(dockerCli command.Cli, config map[string]string) (*kubernetes.Endpoint, error) {
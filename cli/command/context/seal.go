@@ -0,0 +1,131 @@
+package context
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/docker/pkg/term"
+	"github.com/pkg/errors"
+)
+
+// passphraseEnvVar is the environment variable holding the passphrase of
+// encrypted context archives, used instead of prompting for it
+const passphraseEnvVar = "DOCKER_CONTEXT_PASSPHRASE"
+
+// loadPrivateKey loads a PEM encoded RSA or ECDSA private key
+func loadPrivateKey(path string) (crypto.Signer, error) {
+	block, err := readPEMFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var key interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, errors.Errorf("%s: unsupported private key type %q", path, block.Type)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: invalid private key", path)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.Errorf("%s: unsupported private key", path)
+	}
+	return signer, nil
+}
+
+// loadPublicKey loads a PEM encoded RSA or ECDSA public key, or the public key
+// of a PEM encoded certificate
+func loadPublicKey(path string) (crypto.PublicKey, error) {
+	block, err := readPEMFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var key crypto.PublicKey
+	switch block.Type {
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		var cert *x509.Certificate
+		if cert, err = x509.ParseCertificate(block.Bytes); err == nil {
+			key = cert.PublicKey
+		}
+	default:
+		return nil, errors.Errorf("%s: unsupported public key type %q", path, block.Type)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: invalid public key", path)
+	}
+	return key, nil
+}
+
+func readPEMFile(path string) (*pem.Block, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.Errorf("%s: no PEM data found", path)
+	}
+	return block, nil
+}
+
+// readPassphrase returns the passphrase of an encrypted context archive, from
+// the DOCKER_CONTEXT_PASSPHRASE environment variable, or prompting for it
+// (twice if confirm is set)
+func readPassphrase(dockerCli command.Cli, confirm bool) ([]byte, error) {
+	if passphrase := os.Getenv(passphraseEnvVar); passphrase != "" {
+		return []byte(passphrase), nil
+	}
+	if !dockerCli.In().IsTerminal() {
+		return nil, errors.Errorf("cannot prompt for the passphrase from a non TTY device, set %s instead", passphraseEnvVar)
+	}
+	oldState, err := term.SaveState(dockerCli.In().FD())
+	if err != nil {
+		return nil, err
+	}
+	defer term.RestoreTerminal(dockerCli.In().FD(), oldState)
+	term.DisableEcho(dockerCli.In().FD(), oldState)
+
+	reader := bufio.NewReader(dockerCli.In())
+	passphrase, err := promptPassphrase(dockerCli, reader, "Passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+	if len(passphrase) == 0 {
+		return nil, errors.New("passphrase required")
+	}
+	if confirm {
+		repeated, err := promptPassphrase(dockerCli, reader, "Repeat passphrase: ")
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(passphrase, repeated) {
+			return nil, errors.New("passphrases do not match")
+		}
+	}
+	return passphrase, nil
+}
+
+func promptPassphrase(dockerCli command.Cli, reader *bufio.Reader, prompt string) ([]byte, error) {
+	fmt.Fprint(dockerCli.Err(), prompt)
+	line, _, err := reader.ReadLine()
+	fmt.Fprintln(dockerCli.Err())
+	// the line is only valid until the next read
+	return append([]byte(nil), line...), err
+}
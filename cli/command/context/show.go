@@ -0,0 +1,35 @@
+package context
+
+import (
+	"fmt"
+
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/spf13/cobra"
+)
+
+func newShowCommand(dockerCli command.Cli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Print the name of the current context",
+		Args:  cli.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runShow(dockerCli)
+		},
+	}
+	return cmd
+}
+
+// runShow prints the name of the current context, and what selected it on
+// the error stream
+func runShow(dockerCli command.Cli) error {
+	name := dockerCli.CurrentContext()
+	if name == "" {
+		name = "default"
+	}
+	fmt.Fprintln(dockerCli.Out(), name)
+	if source := command.CurrentContextSource(dockerCli); source != "" {
+		fmt.Fprintf(dockerCli.Err(), "Selected by %s\n", source)
+	}
+	return nil
+}
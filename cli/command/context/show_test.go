@@ -0,0 +1,23 @@
+package context
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+func TestShow(t *testing.T) {
+	cli, cleanup := makeFakeCli(t)
+	defer cleanup()
+	assert.NilError(t, runShow(cli))
+	assert.Check(t, is.Equal("default\n", cli.OutBuffer().String()))
+	assert.Check(t, is.Equal("", cli.ErrBuffer().String()))
+
+	cli.OutBuffer().Reset()
+	cli.SetCurrentContext("project")
+	cli.SetCurrentContextSource("the /home/me/project/.docker-context file")
+	assert.NilError(t, runShow(cli))
+	assert.Check(t, is.Equal("project\n", cli.OutBuffer().String()))
+	assert.Check(t, is.Equal("Selected by the /home/me/project/.docker-context file\n", cli.ErrBuffer().String()))
+}
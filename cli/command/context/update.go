@@ -19,6 +19,7 @@ type UpdateOptions struct {
 	Name                     string
 	Description              string
 	DefaultStackOrchestrator string
+	ContentTrustPolicy       string
 	Docker                   map[string]string
 	Kubernetes               map[string]string
 }
@@ -61,6 +62,7 @@ func newUpdateCommand(dockerCli command.Cli) *cobra.Command {
 		&opts.DefaultStackOrchestrator,
 		"default-stack-orchestrator", "",
 		"Default orchestrator for stack operations to use with this context (swarm|kubernetes|all)")
+	flags.StringVar(&opts.ContentTrustPolicy, "content-trust-policy", "", "Path of the content trust policy file enforced with this context")
 	flags.StringToStringVar(&opts.Docker, "docker", nil, "set the docker endpoint")
 	flags.StringToStringVar(&opts.Kubernetes, "kubernetes", nil, "set the kubernetes endpoint")
 	return cmd
@@ -90,17 +92,27 @@ func RunUpdate(cli command.Cli, o *UpdateOptions) error {
 	if o.Description != "" {
 		dockerContext.Description = o.Description
 	}
+	if o.ContentTrustPolicy != "" {
+		if dockerContext.ContentTrustPolicy, err = absContentTrustPolicy(o.ContentTrustPolicy); err != nil {
+			return err
+		}
+	}
 
 	c.Metadata = dockerContext
 
 	tlsDataToReset := make(map[string]*store.EndpointTLSData)
 
 	if o.Docker != nil {
-		dockerEP, dockerTLS, err := getDockerEndpointMetadataAndTLS(cli, o.Docker)
+		dockerEP, dockerConn, dockerTLS, err := getDockerEndpointMetadataAndTLS(cli, o.Docker)
 		if err != nil {
 			return errors.Wrap(err, "unable to create docker endpoint config")
 		}
 		c.Endpoints[docker.DockerEndpoint] = dockerEP
+		if dockerConn != nil {
+			c.Endpoints[docker.ConnectionEndpoint] = *dockerConn
+		} else {
+			delete(c.Endpoints, docker.ConnectionEndpoint)
+		}
 		tlsDataToReset[docker.DockerEndpoint] = dockerTLS
 	}
 	if o.Kubernetes != nil {
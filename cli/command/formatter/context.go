@@ -3,10 +3,15 @@ package formatter
 const (
 	// ClientContextTableFormat is the default client context format
 	ClientContextTableFormat = "table {{.Name}}{{if .Current}} *{{end}}\t{{.Description}}\t{{.DockerEndpoint}}\t{{.KubernetesEndpoint}}\t{{.StackOrchestrator}}"
+	// ClientContextCheckTableFormat is the default client context format,
+	// when the endpoints of the contexts are checked
+	ClientContextCheckTableFormat = ClientContextTableFormat + "\t{{.DockerStatus}}\t{{.KubernetesStatus}}"
 
 	dockerEndpointHeader     = "DOCKER ENDPOINT"
 	kubernetesEndpointHeader = "KUBERNETES ENDPOINT"
 	stackOrchestrastorHeader = "ORCHESTRATOR"
+	dockerStatusHeader       = "DOCKER STATUS"
+	kubernetesStatusHeader   = "KUBERNETES STATUS"
 	quietContextFormat       = "{{.Name}}"
 )
 
@@ -29,6 +34,8 @@ type ClientContext struct {
 	KubernetesEndpoint string
 	StackOrchestrator  string
 	Current            bool
+	DockerStatus       string
+	KubernetesStatus   string
 }
 
 // ClientContextWrite writes formatted contexts using the Context
@@ -57,6 +64,8 @@ func newClientContextContext() *clientContextContext {
 		"DockerEndpoint":     dockerEndpointHeader,
 		"KubernetesEndpoint": kubernetesEndpointHeader,
 		"StackOrchestrator":  stackOrchestrastorHeader,
+		"DockerStatus":       dockerStatusHeader,
+		"KubernetesStatus":   kubernetesStatusHeader,
 	}
 	return &ctx
 }
@@ -88,3 +97,11 @@ func (c *clientContextContext) KubernetesEndpoint() string {
 func (c *clientContextContext) StackOrchestrator() string {
 	return c.c.StackOrchestrator
 }
+
+func (c *clientContextContext) DockerStatus() string {
+	return c.c.DockerStatus
+}
+
+func (c *clientContextContext) KubernetesStatus() string {
+	return c.c.KubernetesStatus
+}
@@ -16,6 +16,7 @@ import (
 	"strings"
 
 	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli-plugins/hooks"
 	"github.com/docker/cli/cli/command"
 	"github.com/docker/cli/cli/command/image/build"
 	"github.com/docker/cli/opts"
@@ -116,6 +117,7 @@ func NewBuildCommand(dockerCli command.Cli) *cobra.Command {
 
 	flags.VarP(&options.tags, "tag", "t", "Name and optionally a tag in the 'name:tag' format")
 	flags.Var(&options.buildArgs, "build-arg", "Set build-time variables")
+	flags.SetAnnotation("build-arg", hooks.FlagAnnotationSensitive, nil)
 	flags.Var(options.ulimits, "ulimit", "Ulimit options")
 	flags.StringVarP(&options.dockerfileName, "file", "f", "", "Name of the Dockerfile (Default is 'PATH/Dockerfile')")
 	flags.VarP(&options.memory, "memory", "m", "Memory limit")
@@ -311,10 +313,17 @@ func runBuild(dockerCli command.Cli, options buildOptions) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	trustPolicy, err := command.ContentTrustPolicy(dockerCli)
+	if err != nil {
+		return err
+	}
 	var resolvedTags []*resolvedTag
-	if !options.untrusted {
+	if !options.untrusted || trustPolicy != nil {
 		translator := func(ctx context.Context, ref reference.NamedTagged) (reference.Canonical, error) {
-			return TrustedReference(ctx, dockerCli, ref, nil)
+			if !command.ContentTrustVerified(trustPolicy, ref, options.untrusted) {
+				return nil, nil
+			}
+			return TrustedReferenceWithPolicy(ctx, dockerCli, ref, nil, trustPolicy)
 		}
 		// if there is a tar wrapper, the dockerfile needs to be replaced inside it
 		if buildCtx != nil {
@@ -465,7 +474,7 @@ func runBuild(dockerCli command.Cli, options buildOptions) error {
 			return err
 		}
 	}
-	if !options.untrusted {
+	if !options.untrusted || trustPolicy != nil {
 		// Since the build was successful, now we must tag any of the resolved
 		// images from the above Dockerfile rewrite.
 		for _, resolved := range resolvedTags {
@@ -507,7 +516,7 @@ type resolvedTag struct {
 // rewriteDockerfileFromForContentTrust rewrites the given Dockerfile by resolving images in
 // "FROM <image>" instructions to a digest reference. `translator` is a
 // function that takes a repository name and tag reference and returns a
-// trusted digest reference.
+// trusted digest reference, or nil if the image must not be resolved.
 // This should be called *only* when content trust is enabled
 func rewriteDockerfileFromForContentTrust(ctx context.Context, dockerfile io.Reader, translator translatorFunc) (newDockerfile []byte, resolvedTags []*resolvedTag, err error) {
 	scanner := bufio.NewScanner(dockerfile)
@@ -531,6 +540,10 @@ func rewriteDockerfileFromForContentTrust(ctx context.Context, dockerfile io.Rea
 				if err != nil {
 					return nil, nil, err
 				}
+				if trustedRef == nil {
+					fmt.Fprintln(buf, line)
+					continue
+				}
 
 				line = dockerfileFromLinePattern.ReplaceAllLiteralString(line, fmt.Sprintf("FROM %s", reference.FamiliarString(trustedRef)))
 				resolvedTags = append(resolvedTags, &resolvedTag{
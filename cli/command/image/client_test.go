@@ -7,10 +7,14 @@ import (
 	"strings"
 	"time"
 
+	registryclient "github.com/docker/cli/cli/registry/client"
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/reference"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/client"
+	"github.com/opencontainers/go-digest"
 )
 
 type fakeClient struct {
@@ -122,3 +126,39 @@ func (cli *fakeClient) ImageBuild(ctx context.Context, context io.Reader, option
 	}
 	return types.ImageBuildResponse{Body: ioutil.NopCloser(strings.NewReader(""))}, nil
 }
+
+type fakeRegistryClient struct {
+	registryclient.ContentClient
+	getRawManifestFunc func(ctx context.Context, ref reference.Named) (distribution.Manifest, error)
+	putManifestFunc    func(ctx context.Context, ref reference.Named, mf distribution.Manifest) (digest.Digest, error)
+	copyBlobFunc       func(ctx context.Context, source reference.Canonical, target reference.Named) error
+	getBlobFunc        func(ctx context.Context, ref reference.Canonical) ([]byte, error)
+}
+
+func (c *fakeRegistryClient) GetRawManifest(ctx context.Context, ref reference.Named) (distribution.Manifest, error) {
+	if c.getRawManifestFunc != nil {
+		return c.getRawManifestFunc(ctx, ref)
+	}
+	return nil, nil
+}
+
+func (c *fakeRegistryClient) PutManifest(ctx context.Context, ref reference.Named, mf distribution.Manifest) (digest.Digest, error) {
+	if c.putManifestFunc != nil {
+		return c.putManifestFunc(ctx, ref, mf)
+	}
+	return digest.Digest(""), nil
+}
+
+func (c *fakeRegistryClient) CopyBlob(ctx context.Context, source reference.Canonical, target reference.Named) error {
+	if c.copyBlobFunc != nil {
+		return c.copyBlobFunc(ctx, source, target)
+	}
+	return nil
+}
+
+func (c *fakeRegistryClient) GetBlob(ctx context.Context, ref reference.Canonical) ([]byte, error) {
+	if c.getBlobFunc != nil {
+		return c.getBlobFunc(ctx, ref)
+	}
+	return nil, nil
+}
@@ -0,0 +1,163 @@
+package image
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	registryclient "github.com/docker/cli/cli/registry/client"
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/manifest/manifestlist"
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/docker/distribution/reference"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type copyOptions struct {
+	source   string
+	target   string
+	insecure bool
+}
+
+// newCopyCommand creates a new `docker image copy` command
+func newCopyCommand(dockerCli command.Cli) *cobra.Command {
+	var opts copyOptions
+
+	cmd := &cobra.Command{
+		Use:   "copy [OPTIONS] SOURCE_IMAGE[:TAG|@DIGEST] TARGET_IMAGE[:TAG]",
+		Short: "Copy an image or a manifest list between registries without pulling it",
+		Args:  cli.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.source = args[0]
+			opts.target = args[1]
+			return runCopy(dockerCli, opts)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVar(&opts.insecure, "insecure", false, "Allow communication with insecure registries")
+
+	return cmd
+}
+
+func runCopy(dockerCli command.Cli, opts copyOptions) error {
+	sourceRef, targetRef, err := parseCopyReferences(opts.source, opts.target)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	rclient, err := registryclient.AsContentClient(dockerCli.RegistryClient(opts.insecure))
+	if err != nil {
+		return err
+	}
+
+	manifest, err := rclient.GetRawManifest(ctx, sourceRef)
+	if err != nil {
+		return err
+	}
+
+	switch v := manifest.(type) {
+	case *manifestlist.DeserializedManifestList:
+		for _, desc := range v.Manifests {
+			if err := copyPlatformManifest(ctx, dockerCli, rclient, sourceRef, targetRef, desc); err != nil {
+				return err
+			}
+		}
+	case *schema2.DeserializedManifest:
+		if err := copyBlobs(ctx, rclient, sourceRef, targetRef, v); err != nil {
+			return err
+		}
+	default:
+		return errors.Errorf("unsupported manifest format for %s: %T", sourceRef, manifest)
+	}
+
+	dgst, err := rclient.PutManifest(ctx, targetRef, manifest)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(dockerCli.Out(), "%s: digest: %s\n", reference.FamiliarString(targetRef), dgst)
+	return nil
+}
+
+// parseCopyReferences normalizes the source and target references. The
+// target inherits the tag of the source when none is specified.
+func parseCopyReferences(source, target string) (reference.Named, reference.Named, error) {
+	sourceRef, err := reference.ParseNormalizedNamed(source)
+	if err != nil {
+		return nil, nil, err
+	}
+	sourceRef = reference.TagNameOnly(sourceRef)
+
+	targetRef, err := reference.ParseNormalizedNamed(target)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, isDigested := targetRef.(reference.Canonical); isDigested {
+		return nil, nil, errors.Errorf("target %s must not reference a digest", target)
+	}
+	if _, isTagged := targetRef.(reference.NamedTagged); !isTagged {
+		tag := "latest"
+		if tagged, ok := sourceRef.(reference.NamedTagged); ok {
+			tag = tagged.Tag()
+		}
+		if targetRef, err = reference.WithTag(targetRef, tag); err != nil {
+			return nil, nil, err
+		}
+	}
+	return sourceRef, targetRef, nil
+}
+
+// copyPlatformManifest copies a manifest referenced by a manifest list, and
+// its blobs, to the target repository, by digest.
+func copyPlatformManifest(ctx context.Context, dockerCli command.Cli, rclient registryclient.ContentClient, sourceRef, targetRef reference.Named, desc manifestlist.ManifestDescriptor) error {
+	sourceDigested, err := reference.WithDigest(reference.TrimNamed(sourceRef), desc.Digest)
+	if err != nil {
+		return err
+	}
+	targetDigested, err := reference.WithDigest(reference.TrimNamed(targetRef), desc.Digest)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := rclient.GetRawManifest(ctx, sourceDigested)
+	if err != nil {
+		return err
+	}
+	v, ok := manifest.(*schema2.DeserializedManifest)
+	if !ok {
+		return errors.Errorf("unsupported manifest format for %s: %T", sourceDigested, manifest)
+	}
+	if err := copyBlobs(ctx, rclient, sourceRef, targetRef, v); err != nil {
+		return err
+	}
+	if _, err := rclient.PutManifest(ctx, targetDigested, manifest); err != nil {
+		return err
+	}
+	fmt.Fprintf(dockerCli.Out(), "Copied %s/%s manifest %s\n", desc.Platform.OS, desc.Platform.Architecture, desc.Digest)
+	return nil
+}
+
+// copyBlobs copies the config and layers of an image manifest to the target
+// repository. Foreign layers are not hosted on the registry and are skipped.
+func copyBlobs(ctx context.Context, rclient registryclient.ContentClient, sourceRef, targetRef reference.Named, manifest *schema2.DeserializedManifest) error {
+	for _, desc := range manifest.References() {
+		if desc.MediaType == schema2.MediaTypeForeignLayer {
+			continue
+		}
+		if err := copyBlob(ctx, rclient, sourceRef, targetRef, desc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyBlob(ctx context.Context, rclient registryclient.ContentClient, sourceRef, targetRef reference.Named, desc distribution.Descriptor) error {
+	canonical, err := reference.WithDigest(reference.TrimNamed(sourceRef), desc.Digest)
+	if err != nil {
+		return err
+	}
+	return rclient.CopyBlob(ctx, canonical, reference.TrimNamed(targetRef))
+}
@@ -0,0 +1,176 @@
+package image
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/docker/cli/internal/test"
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/manifest/manifestlist"
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/docker/distribution/reference"
+	"github.com/opencontainers/go-digest"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+func TestNewCopyCommandErrors(t *testing.T) {
+	testCases := []struct {
+		name          string
+		args          []string
+		expectedError string
+	}{
+		{
+			name:          "wrong-args",
+			args:          []string{"image"},
+			expectedError: "requires exactly 2 arguments.",
+		},
+		{
+			name:          "invalid-source",
+			args:          []string{"UPPERCASE", "target"},
+			expectedError: "repository name must be lowercase",
+		},
+		{
+			name:          "digested-target",
+			args:          []string{"source", "target@sha256:" + digest.FromString("target").Hex()},
+			expectedError: "must not reference a digest",
+		},
+	}
+	for _, tc := range testCases {
+		cli := test.NewFakeCli(&fakeClient{})
+		cli.SetRegistryClient(&fakeRegistryClient{})
+		cmd := newCopyCommand(cli)
+		cmd.SetOutput(ioutil.Discard)
+		cmd.SetArgs(tc.args)
+		assert.ErrorContains(t, cmd.Execute(), tc.expectedError, tc.name)
+	}
+}
+
+func TestParseCopyReferences(t *testing.T) {
+	testCases := []struct {
+		source, target                 string
+		expectedSource, expectedTarget string
+	}{
+		{
+			source:         "alpine",
+			target:         "registry.example.com/alpine",
+			expectedSource: "docker.io/library/alpine:latest",
+			expectedTarget: "registry.example.com/alpine:latest",
+		},
+		{
+			source:         "staging.example.com/app:1.2",
+			target:         "prod.example.com/app",
+			expectedSource: "staging.example.com/app:1.2",
+			expectedTarget: "prod.example.com/app:1.2",
+		},
+		{
+			source:         "staging.example.com/app:1.2",
+			target:         "prod.example.com/app:stable",
+			expectedSource: "staging.example.com/app:1.2",
+			expectedTarget: "prod.example.com/app:stable",
+		},
+	}
+	for _, tc := range testCases {
+		sourceRef, targetRef, err := parseCopyReferences(tc.source, tc.target)
+		assert.NilError(t, err)
+		assert.Check(t, is.Equal(tc.expectedSource, sourceRef.String()))
+		assert.Check(t, is.Equal(tc.expectedTarget, targetRef.String()))
+	}
+}
+
+func newTestImageManifest(t *testing.T, layer string) *schema2.DeserializedManifest {
+	manifest, err := schema2.FromStruct(schema2.Manifest{
+		Versioned: schema2.SchemaVersion,
+		Config: distribution.Descriptor{
+			MediaType: schema2.MediaTypeImageConfig,
+			Digest:    digest.FromString(layer + "-config"),
+		},
+		Layers: []distribution.Descriptor{
+			{MediaType: schema2.MediaTypeLayer, Digest: digest.FromString(layer)},
+			{MediaType: schema2.MediaTypeForeignLayer, Digest: digest.FromString(layer + "-foreign")},
+		},
+	})
+	assert.NilError(t, err)
+	return manifest
+}
+
+func TestCopyImage(t *testing.T) {
+	manifest := newTestImageManifest(t, "layer")
+	var copied []string
+	var pushed []string
+	registryClient := &fakeRegistryClient{
+		getRawManifestFunc: func(_ context.Context, ref reference.Named) (distribution.Manifest, error) {
+			assert.Check(t, is.Equal("staging.example.com/app:1.2", ref.String()))
+			return manifest, nil
+		},
+		copyBlobFunc: func(_ context.Context, source reference.Canonical, target reference.Named) error {
+			assert.Check(t, is.Equal("prod.example.com/app", target.String()))
+			copied = append(copied, source.String())
+			return nil
+		},
+		putManifestFunc: func(_ context.Context, ref reference.Named, _ distribution.Manifest) (digest.Digest, error) {
+			pushed = append(pushed, ref.String())
+			return digest.FromString("manifest"), nil
+		},
+	}
+	cli := test.NewFakeCli(&fakeClient{})
+	cli.SetRegistryClient(registryClient)
+	cmd := newCopyCommand(cli)
+	cmd.SetArgs([]string{"staging.example.com/app:1.2", "prod.example.com/app"})
+	assert.NilError(t, cmd.Execute())
+
+	assert.Check(t, is.DeepEqual([]string{
+		"staging.example.com/app@" + digest.FromString("layer-config").String(),
+		"staging.example.com/app@" + digest.FromString("layer").String(),
+	}, copied))
+	assert.Check(t, is.DeepEqual([]string{"prod.example.com/app:1.2"}, pushed))
+	assert.Check(t, is.Equal("prod.example.com/app:1.2: digest: "+digest.FromString("manifest").String()+"\n", cli.OutBuffer().String()))
+}
+
+func TestCopyManifestList(t *testing.T) {
+	amd64 := newTestImageManifest(t, "amd64")
+	arm64 := newTestImageManifest(t, "arm64")
+	manifests := map[digest.Digest]distribution.Manifest{}
+	var descriptors []manifestlist.ManifestDescriptor
+	for arch, m := range map[string]*schema2.DeserializedManifest{"amd64": amd64, "arm64": arm64} {
+		_, payload, err := m.Payload()
+		assert.NilError(t, err)
+		dgst := digest.FromBytes(payload)
+		manifests[dgst] = m
+		descriptors = append(descriptors, manifestlist.ManifestDescriptor{
+			Descriptor: distribution.Descriptor{Digest: dgst, MediaType: schema2.MediaTypeManifest},
+			Platform:   manifestlist.PlatformSpec{OS: "linux", Architecture: arch},
+		})
+	}
+	list, err := manifestlist.FromDescriptors(descriptors)
+	assert.NilError(t, err)
+
+	var copied int
+	var pushed []string
+	registryClient := &fakeRegistryClient{
+		getRawManifestFunc: func(_ context.Context, ref reference.Named) (distribution.Manifest, error) {
+			if digested, ok := ref.(reference.Canonical); ok {
+				return manifests[digested.Digest()], nil
+			}
+			return list, nil
+		},
+		copyBlobFunc: func(_ context.Context, _ reference.Canonical, _ reference.Named) error {
+			copied++
+			return nil
+		},
+		putManifestFunc: func(_ context.Context, ref reference.Named, _ distribution.Manifest) (digest.Digest, error) {
+			pushed = append(pushed, ref.String())
+			return digest.FromString(ref.String()), nil
+		},
+	}
+	cli := test.NewFakeCli(&fakeClient{})
+	cli.SetRegistryClient(registryClient)
+	cmd := newCopyCommand(cli)
+	cmd.SetArgs([]string{"example.com/app:1.2", "example.com/promoted/app:1.2"})
+	assert.NilError(t, cmd.Execute())
+
+	assert.Check(t, is.Equal(4, copied))
+	assert.Check(t, is.Len(pushed, 3))
+	assert.Check(t, is.Equal("example.com/promoted/app:1.2", pushed[2]))
+}
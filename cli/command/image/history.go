@@ -3,9 +3,13 @@ package image
 import (
 	"context"
 
+	"github.com/containerd/containerd/platforms"
 	"github.com/docker/cli/cli"
 	"github.com/docker/cli/cli/command"
 	"github.com/docker/cli/cli/command/formatter"
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/api/types/image"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
@@ -16,6 +20,10 @@ type historyOptions struct {
 	quiet   bool
 	noTrunc bool
 	format  string
+
+	remote   bool
+	insecure bool
+	platform string
 }
 
 // NewHistoryCommand creates a new `docker history` command
@@ -38,14 +46,28 @@ func NewHistoryCommand(dockerCli command.Cli) *cobra.Command {
 	flags.BoolVarP(&opts.quiet, "quiet", "q", false, "Only show numeric IDs")
 	flags.BoolVar(&opts.noTrunc, "no-trunc", false, "Don't truncate output")
 	flags.StringVar(&opts.format, "format", "", "Pretty-print images using a Go template")
+	flags.BoolVar(&opts.remote, "remote", false, "Show the history of the image in its registry, without pulling it")
+	flags.BoolVar(&opts.insecure, "insecure", false, "Allow communication with an insecure registry, with --remote")
+	flags.StringVar(&opts.platform, "platform", "", "Platform of a multi-platform image, with --remote")
 
 	return cmd
 }
 
 func runHistory(dockerCli command.Cli, opts historyOptions) error {
+	if !opts.remote && opts.platform != "" {
+		return errors.New("--platform requires --remote")
+	}
 	ctx := context.Background()
 
-	history, err := dockerCli.Client().ImageHistory(ctx, opts.image)
+	var (
+		history []image.HistoryResponseItem
+		err     error
+	)
+	if opts.remote {
+		history, err = getRemoteHistory(ctx, dockerCli, opts)
+	} else {
+		history, err = dockerCli.Client().ImageHistory(ctx, opts.image)
+	}
 	if err != nil {
 		return err
 	}
@@ -62,3 +84,43 @@ func runHistory(dockerCli command.Cli, opts historyOptions) error {
 	}
 	return HistoryWrite(historyCtx, opts.human, history)
 }
+
+// getRemoteHistory returns the history of an image in its registry. For a
+// manifest list, the image matching the requested platform is used, or the
+// one matching the client platform by default.
+func getRemoteHistory(ctx context.Context, dockerCli command.Cli, opts historyOptions) ([]image.HistoryResponseItem, error) {
+	namedRef, err := reference.ParseNormalizedNamed(opts.image)
+	if err != nil {
+		return nil, err
+	}
+	namedRef = reference.TagNameOnly(namedRef)
+
+	matcher := platforms.Default()
+	if opts.platform != "" {
+		p, err := platforms.Parse(opts.platform)
+		if err != nil {
+			return nil, err
+		}
+		matcher = platforms.Only(p)
+	}
+
+	images, err := fetchRemoteImages(ctx, dockerCli, namedRef, opts.insecure)
+	if err != nil {
+		return nil, err
+	}
+	for _, img := range images {
+		// the image of a single-platform tag is used whatever its platform,
+		// unless a platform is requested
+		if (len(images) == 1 && opts.platform == "") || matcher.Match(img.platform) {
+			return img.history(reference.FamiliarString(namedRef)), nil
+		}
+	}
+	return nil, errors.Errorf("no image found for platform %s in %s", platformString(opts.platform), reference.FamiliarString(namedRef))
+}
+
+func platformString(platform string) string {
+	if platform == "" {
+		return platforms.DefaultString()
+	}
+	return platform
+}
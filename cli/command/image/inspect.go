@@ -2,16 +2,21 @@ package image
 
 import (
 	"context"
+	"strings"
 
 	"github.com/docker/cli/cli"
 	"github.com/docker/cli/cli/command"
 	"github.com/docker/cli/cli/command/inspect"
+	"github.com/docker/distribution/reference"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
 type inspectOptions struct {
-	format string
-	refs   []string
+	format   string
+	refs     []string
+	remote   bool
+	insecure bool
 }
 
 // newInspectCommand creates a new cobra.Command for `docker image inspect`
@@ -30,10 +35,15 @@ func newInspectCommand(dockerCli command.Cli) *cobra.Command {
 
 	flags := cmd.Flags()
 	flags.StringVarP(&opts.format, "format", "f", "", "Format the output using the given Go template")
+	flags.BoolVar(&opts.remote, "remote", false, "Inspect the image in its registry, for each platform, without pulling it")
+	flags.BoolVar(&opts.insecure, "insecure", false, "Allow communication with an insecure registry, with --remote")
 	return cmd
 }
 
 func runInspect(dockerCli command.Cli, opts inspectOptions) error {
+	if opts.remote {
+		return runRemoteInspect(dockerCli, opts)
+	}
 	client := dockerCli.Client()
 	ctx := context.Background()
 
@@ -42,3 +52,51 @@ func runInspect(dockerCli command.Cli, opts inspectOptions) error {
 	}
 	return inspect.Inspect(dockerCli.Out(), opts.refs, opts.format, getRefFunc)
 }
+
+// runRemoteInspect inspects images in their registry. A reference to a
+// manifest list produces one element per platform.
+func runRemoteInspect(dockerCli command.Cli, opts inspectOptions) error {
+	inspector, err := inspect.NewTemplateInspectorFromString(dockerCli.Out(), opts.format)
+	if err != nil {
+		return cli.StatusError{StatusCode: 64, Status: err.Error()}
+	}
+
+	ctx := context.Background()
+	var inspectErrs []string
+	for _, ref := range opts.refs {
+		if err := inspectRemoteImage(ctx, dockerCli, inspector, ref, opts.insecure); err != nil {
+			inspectErrs = append(inspectErrs, err.Error())
+		}
+	}
+
+	if err := inspector.Flush(); err != nil {
+		logrus.Errorf("%s\n", err)
+	}
+
+	if len(inspectErrs) != 0 {
+		return cli.StatusError{
+			StatusCode: 1,
+			Status:     strings.Join(inspectErrs, "\n"),
+		}
+	}
+	return nil
+}
+
+func inspectRemoteImage(ctx context.Context, dockerCli command.Cli, inspector inspect.Inspector, ref string, insecure bool) error {
+	namedRef, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return err
+	}
+	namedRef = reference.TagNameOnly(namedRef)
+
+	images, err := fetchRemoteImages(ctx, dockerCli, namedRef, insecure)
+	if err != nil {
+		return err
+	}
+	for _, img := range images {
+		if err := inspector.Inspect(img.inspect(namedRef), nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
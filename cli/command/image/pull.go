@@ -68,10 +68,14 @@ func RunPull(cli command.Cli, opts PullOptions) error {
 		return err
 	}
 
+	policy, err := command.ContentTrustPolicy(cli)
+	if err != nil {
+		return err
+	}
 	// Check if reference has a digest
 	_, isCanonical := distributionRef.(reference.Canonical)
-	if !opts.untrusted && !isCanonical {
-		err = trustedPull(ctx, cli, imgRefAndAuth, opts)
+	if command.ContentTrustVerified(policy, distributionRef, opts.untrusted) && !isCanonical {
+		err = trustedPull(ctx, cli, imgRefAndAuth, opts, policy)
 	} else {
 		err = imagePullPrivileged(ctx, cli, imgRefAndAuth, opts)
 	}
@@ -12,6 +12,7 @@ import (
 	"github.com/docker/docker/api/types"
 	"gotest.tools/assert"
 	is "gotest.tools/assert/cmp"
+	"gotest.tools/fs"
 	"gotest.tools/golden"
 )
 
@@ -128,3 +129,65 @@ func TestNewPullCommandWithContentTrustErrors(t *testing.T) {
 		assert.ErrorContains(t, err, tc.expectedError)
 	}
 }
+
+func TestNewPullCommandWithContentTrustPolicy(t *testing.T) {
+	dir := fs.NewDir(t, t.Name(), fs.WithFile("policy.json", `{
+	"Rules": [
+		{"Repository": "docker.io/library/*", "Signers": ["alice"]},
+		{"Repository": "**", "Insecure": true}
+	]
+}`))
+	defer dir.Remove()
+
+	testCases := []struct {
+		name          string
+		args          []string
+		contentTrust  bool
+		expectedRef   string
+		expectedError string
+	}{
+		{
+			name:          "not-signed-by-required-signer",
+			args:          []string{"image:green"},
+			expectedError: "image:green is not signed by alice, as required by the content trust policy",
+		},
+		{
+			name:          "disable-content-trust-ignored",
+			args:          []string{"--disable-content-trust", "image:green"},
+			expectedError: "image:green is not signed by alice, as required by the content trust policy",
+		},
+		{
+			name:         "insecure-allowed",
+			args:         []string{"example.com/image:tag"},
+			contentTrust: true,
+			expectedRef:  "example.com/image:tag",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var pulled string
+			var ops []func(*test.FakeCli)
+			if tc.contentTrust {
+				ops = append(ops, test.EnableContentTrust)
+			}
+			cli := test.NewFakeCli(&fakeClient{
+				imagePullFunc: func(ref string, options types.ImagePullOptions) (io.ReadCloser, error) {
+					pulled = ref
+					return ioutil.NopCloser(strings.NewReader("")), nil
+				},
+			}, ops...)
+			cli.ConfigFile().ContentTrustPolicy = dir.Join("policy.json")
+			cli.SetNotaryClient(notary.GetLoadedNotaryRepository)
+			cmd := NewPullCommand(cli)
+			cmd.SetOutput(ioutil.Discard)
+			cmd.SetArgs(tc.args)
+			err := cmd.Execute()
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Check(t, is.Equal(tc.expectedRef, pulled))
+		})
+	}
+}
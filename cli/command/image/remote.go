@@ -0,0 +1,218 @@
+package image
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/docker/cli/cli/command"
+	registryclient "github.com/docker/cli/cli/registry/client"
+	"github.com/docker/distribution/manifest/manifestlist"
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	digest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+)
+
+// remoteImage is an image fetched from a registry, for one platform
+type remoteImage struct {
+	ref      reference.Canonical
+	platform ocispec.Platform
+	manifest *schema2.DeserializedManifest
+	config   remoteImageConfig
+}
+
+// remoteImageConfig holds the fields of an image config blob which are
+// reported by `inspect --remote` and `history --remote`
+type remoteImageConfig struct {
+	Created       time.Time         `json:"created"`
+	Author        string            `json:"author,omitempty"`
+	Architecture  string            `json:"architecture"`
+	OS            string            `json:"os"`
+	OSVersion     string            `json:"os.version,omitempty"`
+	Variant       string            `json:"variant,omitempty"`
+	DockerVersion string            `json:"docker_version,omitempty"`
+	Config        *container.Config `json:"config,omitempty"`
+	RootFS        struct {
+		Type    string          `json:"type"`
+		DiffIDs []digest.Digest `json:"diff_ids"`
+	} `json:"rootfs"`
+	History []ocispec.History `json:"history,omitempty"`
+}
+
+// RemoteImageInspect is the result of inspecting an image in a registry. It
+// has the fields of a local image inspect, completed with the history of the
+// image and its platform variant.
+type RemoteImageInspect struct {
+	types.ImageInspect
+	Variant string `json:",omitempty"`
+	History []image.HistoryResponseItem
+}
+
+// fetchRemoteImages fetches the manifest of an image, or all the manifests
+// of a manifest list, along with their configs
+func fetchRemoteImages(ctx context.Context, dockerCli command.Cli, namedRef reference.Named, insecure bool) ([]remoteImage, error) {
+	rclient, err := registryclient.AsContentClient(dockerCli.RegistryClient(insecure))
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := rclient.GetRawManifest(ctx, namedRef)
+	if err != nil {
+		return nil, err
+	}
+
+	var descriptors []manifestlist.ManifestDescriptor
+	switch v := manifest.(type) {
+	case *manifestlist.DeserializedManifestList:
+		descriptors = v.Manifests
+	case *schema2.DeserializedManifest:
+		_, payload, err := v.Payload()
+		if err != nil {
+			return nil, err
+		}
+		img, err := fetchRemoteImage(ctx, dockerCli, namedRef, digest.FromBytes(payload), v, insecure)
+		if err != nil {
+			return nil, err
+		}
+		return []remoteImage{img}, nil
+	default:
+		return nil, errors.Errorf("unsupported manifest format for %s: %T", namedRef, manifest)
+	}
+
+	images := make([]remoteImage, 0, len(descriptors))
+	for _, desc := range descriptors {
+		img, err := fetchRemoteImage(ctx, dockerCli, namedRef, desc.Digest, nil, insecure)
+		if err != nil {
+			return nil, err
+		}
+		if img.platform.OS == "" {
+			img.platform.OS = desc.Platform.OS
+			img.platform.Architecture = desc.Platform.Architecture
+		}
+		if img.platform.Variant == "" {
+			img.platform.Variant = desc.Platform.Variant
+		}
+		images = append(images, img)
+	}
+	return images, nil
+}
+
+// fetchRemoteImage fetches the config of an image. The manifest is fetched
+// too, unless it is already known.
+func fetchRemoteImage(ctx context.Context, dockerCli command.Cli, namedRef reference.Named, dgst digest.Digest, manifest *schema2.DeserializedManifest, insecure bool) (remoteImage, error) {
+	rclient, err := registryclient.AsContentClient(dockerCli.RegistryClient(insecure))
+	if err != nil {
+		return remoteImage{}, err
+	}
+	ref, err := reference.WithDigest(reference.TrimNamed(namedRef), dgst)
+	if err != nil {
+		return remoteImage{}, err
+	}
+	if manifest == nil {
+		m, err := rclient.GetRawManifest(ctx, ref)
+		if err != nil {
+			return remoteImage{}, err
+		}
+		var ok bool
+		if manifest, ok = m.(*schema2.DeserializedManifest); !ok {
+			return remoteImage{}, errors.Errorf("unsupported manifest format for %s: %T", ref, m)
+		}
+	}
+
+	configRef, err := reference.WithDigest(reference.TrimNamed(namedRef), manifest.Config.Digest)
+	if err != nil {
+		return remoteImage{}, err
+	}
+	configJSON, err := rclient.GetBlob(ctx, configRef)
+	if err != nil {
+		return remoteImage{}, err
+	}
+	img := remoteImage{ref: ref, manifest: manifest}
+	if err := json.Unmarshal(configJSON, &img.config); err != nil {
+		return remoteImage{}, errors.Wrapf(err, "invalid image config for %s", ref)
+	}
+	img.platform = ocispec.Platform{
+		OS:           img.config.OS,
+		Architecture: img.config.Architecture,
+		OSVersion:    img.config.OSVersion,
+		Variant:      img.config.Variant,
+	}
+	return img, nil
+}
+
+// compressedSize returns the size of the layers of the image, as stored in
+// the registry
+func (img remoteImage) compressedSize() int64 {
+	var size int64
+	for _, layer := range img.manifest.Layers {
+		size += layer.Size
+	}
+	return size
+}
+
+// history builds the history of the image, most recent first, the same way
+// the daemon does for a local image. Layers have no ID in a registry, so only
+// the top-most entry is identified, by the image ID.
+func (img remoteImage) history(tag string) []image.HistoryResponseItem {
+	var (
+		history []image.HistoryResponseItem
+		layer   int
+	)
+	for _, h := range img.config.History {
+		item := image.HistoryResponseItem{
+			ID:        "<missing>",
+			CreatedBy: h.CreatedBy,
+			Comment:   h.Comment,
+		}
+		if h.Created != nil {
+			item.Created = h.Created.Unix()
+		}
+		if !h.EmptyLayer && layer < len(img.manifest.Layers) {
+			item.Size = img.manifest.Layers[layer].Size
+			layer++
+		}
+		history = append([]image.HistoryResponseItem{item}, history...)
+	}
+	if len(history) > 0 {
+		history[0].ID = img.manifest.Config.Digest.String()
+		if tag != "" {
+			history[0].Tags = []string{tag}
+		}
+	}
+	return history
+}
+
+// inspect returns the inspect representation of the image
+func (img remoteImage) inspect(namedRef reference.Named) RemoteImageInspect {
+	var repoTags []string
+	if tagged, ok := namedRef.(reference.NamedTagged); ok {
+		repoTags = []string{reference.FamiliarString(tagged)}
+	}
+	rootFS := types.RootFS{Type: img.config.RootFS.Type}
+	for _, diffID := range img.config.RootFS.DiffIDs {
+		rootFS.Layers = append(rootFS.Layers, diffID.String())
+	}
+	return RemoteImageInspect{
+		ImageInspect: types.ImageInspect{
+			ID:            img.manifest.Config.Digest.String(),
+			RepoTags:      repoTags,
+			RepoDigests:   []string{reference.FamiliarString(img.ref)},
+			Created:       img.config.Created.Format(time.RFC3339Nano),
+			DockerVersion: img.config.DockerVersion,
+			Author:        img.config.Author,
+			Config:        img.config.Config,
+			Architecture:  img.platform.Architecture,
+			Os:            img.platform.OS,
+			OsVersion:     img.platform.OSVersion,
+			Size:          img.compressedSize(),
+			VirtualSize:   img.compressedSize(),
+			RootFS:        rootFS,
+		},
+		Variant: img.platform.Variant,
+		History: img.history(""),
+	}
+}
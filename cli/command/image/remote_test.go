@@ -0,0 +1,172 @@
+package image
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/docker/cli/internal/test"
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/manifest/manifestlist"
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/docker/distribution/reference"
+	"github.com/opencontainers/go-digest"
+	"gotest.tools/assert"
+	"gotest.tools/golden"
+	"gotest.tools/skip"
+)
+
+const remoteConfigTemplate = `{
+	"architecture": %q,
+	"os": "linux",
+	"created": "2019-03-01T10:00:00Z",
+	"docker_version": "18.09.2",
+	"config": {
+		"Env": ["PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"],
+		"Entrypoint": ["/entrypoint.sh"],
+		"Cmd": ["serve"],
+		"ExposedPorts": {"8080/tcp": {}},
+		"Labels": {"maintainer": "team@example.com"}
+	},
+	"rootfs": {"type": "layers", "diff_ids": ["sha256:5dacd731af1b0386ead06c8b1feff9f65d9e0bdfec032d2cd0bc03690698feda", "sha256:91b57c5b4d5ed32dbb3e3e1db7ac8cdfc8b6c3c5e9ac8c3ce5dea5ae6e9ec1f8"]},
+	"history": [
+		{"created": "2019-03-01T09:00:00Z", "created_by": "/bin/sh -c #(nop) ADD file:abc in / "},
+		{"created": "2019-03-01T09:00:01Z", "created_by": "/bin/sh -c #(nop)  CMD [\"/bin/sh\"]", "empty_layer": true},
+		{"created": "2019-03-01T10:00:00Z", "created_by": "/bin/sh -c apk add --no-cache curl", "comment": "dependencies"}
+	]
+}`
+
+// newFakeRemoteRegistryClient returns a registry client serving a manifest
+// list for linux/amd64 and linux/arm64 images.
+func newFakeRemoteRegistryClient(t *testing.T) *fakeRegistryClient {
+	blobs := map[digest.Digest][]byte{}
+	manifests := map[digest.Digest]distribution.Manifest{}
+	var descriptors []manifestlist.ManifestDescriptor
+	for i, arch := range []string{"amd64", "arm64"} {
+		config := []byte(fmt.Sprintf(remoteConfigTemplate, arch))
+		configDigest := digest.FromBytes(config)
+		blobs[configDigest] = config
+		manifest, err := schema2.FromStruct(schema2.Manifest{
+			Versioned: schema2.SchemaVersion,
+			Config: distribution.Descriptor{
+				MediaType: schema2.MediaTypeImageConfig,
+				Digest:    configDigest,
+				Size:      int64(len(config)),
+			},
+			Layers: []distribution.Descriptor{
+				{MediaType: schema2.MediaTypeLayer, Digest: digest.FromString(arch + "-base"), Size: 2000000 + int64(i)},
+				{MediaType: schema2.MediaTypeLayer, Digest: digest.FromString(arch + "-curl"), Size: 1500000},
+			},
+		})
+		assert.NilError(t, err)
+		_, payload, err := manifest.Payload()
+		assert.NilError(t, err)
+		manifests[digest.FromBytes(payload)] = manifest
+		descriptors = append(descriptors, manifestlist.ManifestDescriptor{
+			Descriptor: distribution.Descriptor{MediaType: schema2.MediaTypeManifest, Digest: digest.FromBytes(payload), Size: int64(len(payload))},
+			Platform:   manifestlist.PlatformSpec{OS: "linux", Architecture: arch},
+		})
+	}
+	list, err := manifestlist.FromDescriptors(descriptors)
+	assert.NilError(t, err)
+
+	return &fakeRegistryClient{
+		getRawManifestFunc: func(_ context.Context, ref reference.Named) (distribution.Manifest, error) {
+			if digested, ok := ref.(reference.Canonical); ok {
+				return manifests[digested.Digest()], nil
+			}
+			return list, nil
+		},
+		getBlobFunc: func(_ context.Context, ref reference.Canonical) ([]byte, error) {
+			return blobs[ref.Digest()], nil
+		},
+	}
+}
+
+func TestRemoteInspect(t *testing.T) {
+	testCases := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "remote",
+			args: []string{"--remote", "example.com/app:1.0"},
+		},
+		{
+			name: "remote-format",
+			args: []string{"--remote", "--format", "{{.Os}}/{{.Architecture}} {{.Size}} {{.Config.Entrypoint}} {{.Config.ExposedPorts}} {{len .History}}", "example.com/app:1.0"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cli := test.NewFakeCli(&fakeClient{})
+			cli.SetRegistryClient(newFakeRemoteRegistryClient(t))
+			cmd := newInspectCommand(cli)
+			cmd.SetOutput(ioutil.Discard)
+			cmd.SetArgs(tc.args)
+			assert.NilError(t, cmd.Execute())
+			golden.Assert(t, cli.OutBuffer().String(), "inspect-command-success."+tc.name+".golden")
+		})
+	}
+}
+
+func TestRemoteHistory(t *testing.T) {
+	skip.If(t, notUTCTimezone, "expected output requires UTC timezone")
+	cli := test.NewFakeCli(&fakeClient{})
+	cli.SetRegistryClient(newFakeRemoteRegistryClient(t))
+	cmd := NewHistoryCommand(cli)
+	cmd.SetOutput(ioutil.Discard)
+	cmd.SetArgs([]string{"--remote", "--platform", "linux/arm64", "--human=false", "example.com/app:1.0"})
+	assert.NilError(t, cmd.Execute())
+	golden.Assert(t, cli.OutBuffer().String(), "history-command-success.remote.golden")
+}
+
+func TestRemoteHistoryErrors(t *testing.T) {
+	testCases := []struct {
+		args          []string
+		expectedError string
+	}{
+		{
+			args:          []string{"--platform", "linux/arm64", "example.com/app:1.0"},
+			expectedError: "--platform requires --remote",
+		},
+		{
+			args:          []string{"--remote", "--platform", "windows/amd64", "example.com/app:1.0"},
+			expectedError: "no image found for platform windows/amd64 in example.com/app:1.0",
+		},
+	}
+	for _, tc := range testCases {
+		cli := test.NewFakeCli(&fakeClient{})
+		cli.SetRegistryClient(newFakeRemoteRegistryClient(t))
+		cmd := NewHistoryCommand(cli)
+		cmd.SetOutput(ioutil.Discard)
+		cmd.SetArgs(tc.args)
+		assert.ErrorContains(t, cmd.Execute(), tc.expectedError)
+	}
+}
+
+func TestRemoteHistorySinglePlatform(t *testing.T) {
+	registryClient := newFakeRemoteRegistryClient(t)
+	getRawManifest := registryClient.getRawManifestFunc
+	// serve the linux/amd64 image instead of the manifest list
+	registryClient.getRawManifestFunc = func(ctx context.Context, ref reference.Named) (distribution.Manifest, error) {
+		list, err := getRawManifest(ctx, ref)
+		assert.NilError(t, err)
+		canonical, err := reference.WithDigest(ref, list.(*manifestlist.DeserializedManifestList).Manifests[0].Digest)
+		assert.NilError(t, err)
+		return getRawManifest(ctx, canonical)
+	}
+	cli := test.NewFakeCli(&fakeClient{})
+	cli.SetRegistryClient(registryClient)
+
+	cmd := NewHistoryCommand(cli)
+	cmd.SetOutput(ioutil.Discard)
+	cmd.SetArgs([]string{"--remote", "example.com/app:1.0"})
+	assert.NilError(t, cmd.Execute())
+
+	cmd = NewHistoryCommand(cli)
+	cmd.SetOutput(ioutil.Discard)
+	cmd.SetArgs([]string{"--remote", "--platform", "linux/arm64", "example.com/app:1.0"})
+	assert.ErrorContains(t, cmd.Execute(), "no image found for platform linux/arm64 in example.com/app:1.0")
+}
@@ -108,9 +108,14 @@ func PushTrustedReference(streams command.Streams, repoInfo *registry.Repository
 	if err != nil {
 		return errors.Wrap(err, "error establishing connection to trust repository")
 	}
+	return PushTrustedTarget(streams, repo, repoInfo.Name.Name(), tag, target)
+}
 
+// PushTrustedTarget signs a target and publishes it to the trust server. The
+// trust data of the repository is initialized if it doesn't exist yet.
+func PushTrustedTarget(streams command.Streams, repo client.Repository, repoName, tag string, target *client.Target) error {
 	// get the latest repository metadata so we can figure out which roles to sign
-	_, err = repo.ListTargets()
+	_, err := repo.ListTargets()
 
 	switch err.(type) {
 	case client.ErrRepoNotInitialized, client.ErrRepositoryNotExist:
@@ -130,15 +135,15 @@ func PushTrustedReference(streams command.Streams, repoInfo *registry.Repository
 
 		// Initialize the notary repository with a remotely managed snapshot key
 		if err := repo.Initialize([]string{rootKeyID}, data.CanonicalSnapshotRole); err != nil {
-			return trust.NotaryError(repoInfo.Name.Name(), err)
+			return trust.NotaryError(repoName, err)
 		}
-		fmt.Fprintf(streams.Out(), "Finished initializing %q\n", repoInfo.Name.Name())
+		fmt.Fprintf(streams.Out(), "Finished initializing %q\n", repoName)
 		err = repo.AddTarget(target, data.CanonicalTargetsRole)
 	case nil:
 		// already initialized and we have successfully downloaded the latest metadata
 		err = AddTargetToAllSignableRoles(repo, target)
 	default:
-		return trust.NotaryError(repoInfo.Name.Name(), err)
+		return trust.NotaryError(repoName, err)
 	}
 
 	if err == nil {
@@ -146,14 +151,28 @@ func PushTrustedReference(streams command.Streams, repoInfo *registry.Repository
 	}
 
 	if err != nil {
-		err = errors.Wrapf(err, "failed to sign %s:%s", repoInfo.Name.Name(), tag)
-		return trust.NotaryError(repoInfo.Name.Name(), err)
+		err = errors.Wrapf(err, "failed to sign %s:%s", repoName, tag)
+		return trust.NotaryError(repoName, err)
 	}
 
-	fmt.Fprintf(streams.Out(), "Successfully signed %s:%s\n", repoInfo.Name.Name(), tag)
+	fmt.Fprintf(streams.Out(), "Successfully signed %s:%s\n", repoName, tag)
 	return nil
 }
 
+// NewTrustedTarget returns the trust target of a tag, for the manifest with
+// the given digest and size
+func NewTrustedTarget(tag string, dgst digest.Digest, size int64) (*client.Target, error) {
+	h, err := hex.DecodeString(dgst.Hex())
+	if err != nil {
+		return nil, err
+	}
+	return &client.Target{
+		Name:   tag,
+		Hashes: data.Hashes{string(dgst.Algorithm()): h},
+		Length: size,
+	}, nil
+}
+
 // AddTargetToAllSignableRoles attempts to add the image target to all the top level delegation roles we can
 // (based on whether we have the signing key and whether the role's path allows
 // us to).
@@ -181,9 +200,10 @@ func imagePushPrivileged(ctx context.Context, cli command.Cli, authConfig types.
 	return cli.Client().ImagePush(ctx, reference.FamiliarString(ref), options)
 }
 
-// trustedPull handles content trust pulling of an image
-func trustedPull(ctx context.Context, cli command.Cli, imgRefAndAuth trust.ImageRefAndAuth, opts PullOptions) error {
-	refs, err := getTrustedPullTargets(cli, imgRefAndAuth)
+// trustedPull handles content trust pulling of an image, the targets must be
+// signed by the signers required by the content trust policy
+func trustedPull(ctx context.Context, cli command.Cli, imgRefAndAuth trust.ImageRefAndAuth, opts PullOptions, policy *trust.Policy) error {
+	refs, err := getTrustedPullTargets(cli, imgRefAndAuth, policy)
 	if err != nil {
 		return err
 	}
@@ -225,7 +245,7 @@ func trustedPull(ctx context.Context, cli command.Cli, imgRefAndAuth trust.Image
 	return nil
 }
 
-func getTrustedPullTargets(cli command.Cli, imgRefAndAuth trust.ImageRefAndAuth) ([]target, error) {
+func getTrustedPullTargets(cli command.Cli, imgRefAndAuth trust.ImageRefAndAuth, policy *trust.Policy) ([]target, error) {
 	notaryRepo, err := cli.NotaryClient(imgRefAndAuth, trust.ActionsPullOnly)
 	if err != nil {
 		return nil, errors.Wrap(err, "error establishing connection to trust repository")
@@ -256,6 +276,11 @@ func getTrustedPullTargets(cli command.Cli, imgRefAndAuth trust.ImageRefAndAuth)
 		if len(refs) == 0 {
 			return nil, trust.NotaryError(ref.Name(), errors.Errorf("No trusted tags for %s", ref.Name()))
 		}
+		for _, r := range refs {
+			if err := checkPolicySigners(policy, notaryRepo, ref, r); err != nil {
+				return nil, err
+			}
+		}
 		return refs, nil
 	}
 
@@ -271,7 +296,36 @@ func getTrustedPullTargets(cli command.Cli, imgRefAndAuth trust.ImageRefAndAuth)
 
 	logrus.Debugf("retrieving target for %s role", t.Role)
 	r, err := convertTarget(t.Target)
-	return []target{r}, err
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPolicySigners(policy, notaryRepo, ref, r); err != nil {
+		return nil, err
+	}
+	return []target{r}, nil
+}
+
+// checkPolicySigners checks that the target of the named repository is signed
+// by the signers required by the content trust policy for the repository.
+func checkPolicySigners(policy *trust.Policy, notaryRepo client.Repository, ref reference.Named, t target) error {
+	rule, ok := policy.Rule(ref.Name())
+	if !ok {
+		return nil
+	}
+	for _, signer := range rule.Signers {
+		signed, err := notaryRepo.GetTargetByName(t.name, data.RoleName("targets/"+signer))
+		if err == nil {
+			var st target
+			if st, err = convertTarget(signed.Target); err == nil && st.digest != t.digest {
+				err = errors.Errorf("signed digest %s doesn't match %s", st.digest, t.digest)
+			}
+		}
+		if err != nil {
+			logrus.Debugf("target %s of %s not signed by %s: %v", t.name, ref.Name(), signer, err)
+			return errors.Errorf("%s:%s is not signed by %s, as required by the content trust policy", reference.FamiliarName(ref), t.name, signer)
+		}
+	}
+	return nil
 }
 
 // imagePullPrivileged pulls the image and displays it to the output
@@ -304,6 +358,17 @@ func imagePullPrivileged(ctx context.Context, cli command.Cli, imgRefAndAuth tru
 
 // TrustedReference returns the canonical trusted reference for an image reference
 func TrustedReference(ctx context.Context, cli command.Cli, ref reference.NamedTagged, rs registry.Service) (reference.Canonical, error) {
+	policy, err := command.ContentTrustPolicy(cli)
+	if err != nil {
+		return nil, err
+	}
+	return TrustedReferenceWithPolicy(ctx, cli, ref, rs, policy)
+}
+
+// TrustedReferenceWithPolicy returns the canonical trusted reference for an
+// image reference, which must be signed by the signers required by the
+// content trust policy, if any
+func TrustedReferenceWithPolicy(ctx context.Context, cli command.Cli, ref reference.NamedTagged, rs registry.Service, policy *trust.Policy) (reference.Canonical, error) {
 	imgRefAndAuth, err := trust.GetImageReferencesAndAuth(ctx, rs, AuthResolver(cli), ref.String())
 	if err != nil {
 		return nil, err
@@ -328,6 +393,9 @@ func TrustedReference(ctx context.Context, cli command.Cli, ref reference.NamedT
 		return nil, err
 
 	}
+	if err := checkPolicySigners(policy, notaryRepo, ref, r); err != nil {
+		return nil, err
+	}
 	return reference.WithDigest(reference.TrimNamed(ref), r.digest)
 }
 
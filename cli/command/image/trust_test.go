@@ -6,11 +6,15 @@ import (
 	"testing"
 
 	"github.com/docker/cli/cli/trust"
+	"github.com/docker/cli/internal/test/notary"
+	"github.com/docker/distribution/reference"
 	registrytypes "github.com/docker/docker/api/types/registry"
 	"github.com/theupdateframework/notary/client"
 	"github.com/theupdateframework/notary/passphrase"
 	"github.com/theupdateframework/notary/trustpinning"
 	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+	"gotest.tools/fs"
 )
 
 func unsetENV() {
@@ -71,3 +75,36 @@ func TestAddTargetToAllSignableRolesError(t *testing.T) {
 	err = AddTargetToAllSignableRoles(notaryRepo, &target)
 	assert.Error(t, err, "client is offline")
 }
+
+func TestCheckPolicySigners(t *testing.T) {
+	dir := fs.NewDir(t, t.Name(), fs.WithFile("policy.json", `{"Rules": [{"Repository": "docker.io/library/*", "Signers": ["alice", "bob"]}]}`))
+	defer dir.Remove()
+	policy, err := trust.LoadPolicy(dir.Join("policy.json"))
+	assert.NilError(t, err)
+	notaryRepo := notary.LoadedNotaryRepository{}
+
+	testCases := []struct {
+		image         string
+		tag           string
+		expectedError string
+	}{
+		{image: "image", tag: "red"},
+		{image: "image", tag: "blue", expectedError: "image:blue is not signed by bob, as required by the content trust policy"},
+		{image: "image", tag: "green", expectedError: "image:green is not signed by alice, as required by the content trust policy"},
+		{image: "example.com/image", tag: "green"},
+	}
+	for _, tc := range testCases {
+		ref, err := reference.ParseNormalizedNamed(tc.image)
+		assert.NilError(t, err)
+		signed, err := notaryRepo.GetTargetByName(tc.tag, trust.ReleasesRole)
+		assert.NilError(t, err)
+		tgt, err := convertTarget(signed.Target)
+		assert.NilError(t, err)
+		err = checkPolicySigners(policy, notaryRepo, ref, tgt)
+		if tc.expectedError == "" {
+			assert.Check(t, is.Nil(err), tc.tag)
+		} else {
+			assert.Check(t, is.Error(err, tc.expectedError))
+		}
+	}
+}
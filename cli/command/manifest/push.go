@@ -8,21 +8,25 @@ import (
 
 	"github.com/docker/cli/cli"
 	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/command/image"
 	"github.com/docker/cli/cli/manifest/types"
 	registryclient "github.com/docker/cli/cli/registry/client"
+	"github.com/docker/cli/cli/trust"
 	"github.com/docker/distribution"
 	"github.com/docker/distribution/manifest/manifestlist"
 	"github.com/docker/distribution/manifest/schema2"
 	"github.com/docker/distribution/reference"
 	"github.com/docker/docker/registry"
+	digest "github.com/opencontainers/go-digest"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
 type pushOpts struct {
-	insecure bool
-	purge    bool
-	target   string
+	insecure  bool
+	purge     bool
+	untrusted bool
+	target    string
 }
 
 type mountRequest struct {
@@ -41,6 +45,7 @@ type pushRequest struct {
 	mountRequests []mountRequest
 	manifestBlobs []manifestBlob
 	insecure      bool
+	sign          bool
 }
 
 func newPushListCommand(dockerCli command.Cli) *cobra.Command {
@@ -59,6 +64,7 @@ func newPushListCommand(dockerCli command.Cli) *cobra.Command {
 	flags := cmd.Flags()
 	flags.BoolVarP(&opts.purge, "purge", "p", false, "Remove the local manifest list after push")
 	flags.BoolVar(&opts.insecure, "insecure", false, "Allow push to an insecure registry")
+	command.AddTrustSigningFlags(flags, &opts.untrusted, dockerCli.ContentTrustEnabled())
 	return cmd
 }
 
@@ -81,6 +87,7 @@ func runPush(dockerCli command.Cli, opts pushOpts) error {
 	if err != nil {
 		return err
 	}
+	pushRequest.sign = !opts.untrusted
 
 	ctx := context.Background()
 	if err := pushList(ctx, dockerCli, pushRequest); err != nil {
@@ -250,9 +257,41 @@ func pushList(ctx context.Context, dockerCli command.Cli, req pushRequest) error
 	}
 
 	fmt.Fprintln(dockerCli.Out(), dgst.String())
+	if req.sign {
+		return signList(ctx, dockerCli, req.targetRef, req.list, dgst)
+	}
 	return nil
 }
 
+// signList signs the manifest list pushed to the tag of targetRef, so that it
+// can be pulled with content trust enabled
+func signList(ctx context.Context, dockerCli command.Cli, targetRef reference.Named, list *manifestlist.DeserializedManifestList, dgst digest.Digest) error {
+	tagged, ok := targetRef.(reference.NamedTagged)
+	if !ok {
+		fmt.Fprintln(dockerCli.Err(), "No tag specified, skipping trust metadata push")
+		return nil
+	}
+	_, payload, err := list.Payload()
+	if err != nil {
+		return err
+	}
+	target, err := image.NewTrustedTarget(tagged.Tag(), dgst, int64(len(payload)))
+	if err != nil {
+		return err
+	}
+	imgRefAndAuth, err := trust.GetImageReferencesAndAuth(ctx, nil, image.AuthResolver(dockerCli), targetRef.String())
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(dockerCli.Out(), "Signing and pushing trust metadata")
+	repo, err := dockerCli.NotaryClient(imgRefAndAuth, trust.ActionsPushAndPull)
+	if err != nil {
+		return errors.Wrap(err, "error establishing connection to trust repository")
+	}
+	return image.PushTrustedTarget(dockerCli, repo, imgRefAndAuth.RepoInfo().Name.Name(), tagged.Tag(), target)
+}
+
 func pushReferences(ctx context.Context, out io.Writer, client registryclient.RegistryClient, mounts []mountRequest) error {
 	for _, mount := range mounts {
 		newDigest, err := client.PutManifest(ctx, mount.ref, mount.manifest)
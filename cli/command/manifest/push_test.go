@@ -2,14 +2,22 @@ package manifest
 
 import (
 	"context"
+	"encoding/hex"
 	"io/ioutil"
+	"strings"
 	"testing"
 
 	manifesttypes "github.com/docker/cli/cli/manifest/types"
+	"github.com/docker/cli/cli/trust"
 	"github.com/docker/cli/internal/test"
+	notaryfake "github.com/docker/cli/internal/test/notary"
+	"github.com/docker/distribution"
 	"github.com/docker/distribution/reference"
+	digest "github.com/opencontainers/go-digest"
 	"github.com/pkg/errors"
+	"github.com/theupdateframework/notary/client"
 	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
 )
 
 func newFakeRegistryClient() *fakeRegistryClient {
@@ -67,3 +75,61 @@ func TestManifestPush(t *testing.T) {
 	err = cmd.Execute()
 	assert.NilError(t, err)
 }
+
+func TestManifestPushSigned(t *testing.T) {
+	store, sCleanup := newTempManifestStore(t)
+	defer sCleanup()
+
+	var pushed distribution.Manifest
+	registry := newFakeRegistryClient()
+	registry.putManifestFunc = func(_ context.Context, _ reference.Named, mf distribution.Manifest) (digest.Digest, error) {
+		pushed = mf
+		_, payload, err := mf.Payload()
+		return digest.FromBytes(payload), err
+	}
+
+	var targets []client.Target
+	cli := test.NewFakeCli(nil, test.EnableContentTrust)
+	cli.SetManifestStore(store)
+	cli.SetRegistryClient(registry)
+	cli.SetNotaryClient(func(imgRefAndAuth trust.ImageRefAndAuth, actions []string) (client.Repository, error) {
+		assert.Check(t, is.Equal("example.com/list", imgRefAndAuth.RepoInfo().Name.Name()))
+		return notaryfake.NewRecordingNotaryRepository(&targets), nil
+	})
+
+	namedRef := ref(t, "alpine:3.0")
+	imageManifest := fullImageManifest(t, namedRef)
+	err := store.Save(ref(t, "list:v1"), namedRef, imageManifest)
+	assert.NilError(t, err)
+
+	cmd := newPushListCommand(cli)
+	cmd.SetArgs([]string{"example.com/list:v1"})
+	assert.NilError(t, cmd.Execute())
+
+	_, payload, err := pushed.Payload()
+	assert.NilError(t, err)
+	assert.Assert(t, is.Len(targets, 1))
+	assert.Check(t, is.Equal("v1", targets[0].Name))
+	assert.Check(t, is.Equal(int64(len(payload)), targets[0].Length))
+	assert.Check(t, is.Equal(digest.FromBytes(payload).Hex(), hex.EncodeToString(targets[0].Hashes["sha256"])))
+	assert.Check(t, is.Contains(cli.OutBuffer().String(), "Successfully signed example.com/list:v1"))
+}
+
+func TestManifestPushDisableContentTrust(t *testing.T) {
+	store, sCleanup := newTempManifestStore(t)
+	defer sCleanup()
+
+	cli := test.NewFakeCli(nil, test.EnableContentTrust)
+	cli.SetManifestStore(store)
+	cli.SetRegistryClient(newFakeRegistryClient())
+
+	namedRef := ref(t, "alpine:3.0")
+	imageManifest := fullImageManifest(t, namedRef)
+	err := store.Save(ref(t, "list:v1"), namedRef, imageManifest)
+	assert.NilError(t, err)
+
+	cmd := newPushListCommand(cli)
+	cmd.SetArgs([]string{"--disable-content-trust", "example.com/list:v1"})
+	assert.NilError(t, cmd.Execute())
+	assert.Check(t, !strings.Contains(cli.OutBuffer().String(), "Signing"))
+}
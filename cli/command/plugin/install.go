@@ -90,8 +90,12 @@ func buildPullConfig(ctx context.Context, dockerCli command.Cli, opts pluginOpti
 
 	remote := ref.String()
 
+	policy, err := command.ContentTrustPolicy(dockerCli)
+	if err != nil {
+		return types.PluginInstallOptions{}, err
+	}
 	_, isCanonical := ref.(reference.Canonical)
-	if !opts.untrusted && !isCanonical {
+	if command.ContentTrustVerified(policy, ref, opts.untrusted) && !isCanonical {
 		ref = reference.TagNameOnly(ref)
 		nt, ok := ref.(reference.NamedTagged)
 		if !ok {
@@ -103,7 +107,7 @@ func buildPullConfig(ctx context.Context, dockerCli command.Cli, opts pluginOpti
 		if err != nil {
 			return types.PluginInstallOptions{}, err
 		}
-		trusted, err := image.TrustedReference(ctx, dockerCli, nt, svc)
+		trusted, err := image.TrustedReferenceWithPolicy(ctx, dockerCli, nt, svc, policy)
 		if err != nil {
 			return types.PluginInstallOptions{}, err
 		}
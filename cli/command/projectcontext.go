@@ -0,0 +1,87 @@
+package command
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/docker/pkg/homedir"
+	"github.com/pkg/errors"
+)
+
+// ProjectContextFile is the name of the file setting the context of the
+// commands run in its directory and its subdirectories
+const ProjectContextFile = ".docker-context"
+
+// findProjectContext returns the context of the project of the working
+// directory, and what sets it: the nearest directory, from the working
+// directory to the root, which has a .docker-context file holding the name of
+// a context, or is mapped to a context by the ProjectContexts of the config
+// file. The file takes precedence over the config file in a same directory.
+// The directories are compared once their symbolic links are resolved.
+//
+// It returns empty strings if no directory sets a context.
+func findProjectContext(config *configfile.ConfigFile) (string, string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", "", nil
+	}
+	dir = evalSymlinks(dir)
+	projectContexts := make(map[string]string)
+	if config != nil {
+		for projectDir, name := range config.ProjectContexts {
+			resolved, err := resolveProjectDir(projectDir)
+			if err != nil {
+				return "", "", errors.Wrapf(err, "invalid projectContexts in %s", config.Filename)
+			}
+			projectContexts[resolved] = name
+		}
+	}
+	for {
+		file := filepath.Join(dir, ProjectContextFile)
+		data, err := ioutil.ReadFile(file)
+		if err == nil {
+			name := strings.TrimSpace(string(data))
+			if name == "" || strings.ContainsAny(name, "\r\n") {
+				return "", "", errors.Errorf("invalid %s file: it must hold the name of a context", file)
+			}
+			return name, fmt.Sprintf("the %s file", file), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", "", errors.Wrapf(err, "failed to read %s", file)
+		}
+		if name, ok := projectContexts[dir]; ok {
+			return name, fmt.Sprintf("the projectContexts of %s for %s", config.Filename, dir), nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", "", nil
+		}
+		dir = parent
+	}
+}
+
+// resolveProjectDir returns the directory of a project mapped to a context by
+// the ProjectContexts of the config file, which must be an absolute path or
+// start with "~/", with its symbolic links resolved
+func resolveProjectDir(dir string) (string, error) {
+	if dir == "~" || strings.HasPrefix(dir, "~/") {
+		dir = filepath.Join(homedir.Get(), dir[1:])
+	}
+	if !filepath.IsAbs(dir) {
+		return "", errors.Errorf("project directory %q is not an absolute path", dir)
+	}
+	return evalSymlinks(filepath.Clean(dir)), nil
+}
+
+// evalSymlinks resolves the symbolic links of a path, which is returned as is
+// if they can't be resolved, for example if it doesn't exist
+func evalSymlinks(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return path
+}
@@ -0,0 +1,120 @@
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/cli/cli/context/store"
+	cliflags "github.com/docker/cli/cli/flags"
+	"github.com/docker/docker/pkg/homedir"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+	"gotest.tools/env"
+	"gotest.tools/fs"
+)
+
+func TestResolveContextNameFromProject(t *testing.T) {
+	environ := env.ToMap(os.Environ())
+	delete(environ, "DOCKER_HOST")
+	delete(environ, "DOCKER_CONTEXT")
+	defer env.PatchAll(t, environ)()
+
+	project := fs.NewDir(t, t.Name(),
+		fs.WithFile(ProjectContextFile, "project\n"),
+		fs.WithDir("sub", fs.WithDir("subsub")),
+		fs.WithDir("mapped"),
+		fs.WithDir("unknown", fs.WithFile(ProjectContextFile, "unknown")),
+		fs.WithDir("default", fs.WithFile(ProjectContextFile, "default")),
+	)
+	defer project.Remove()
+	storeDir := fs.NewDir(t, t.Name())
+	defer storeDir.Remove()
+	s := store.New(storeDir.Path(), defaultContextStoreConfig())
+	for _, name := range []string{"project", "mapped", "global", "flag"} {
+		assert.NilError(t, s.CreateOrUpdateContext(store.ContextMetadata{Name: name}))
+	}
+	config := configfile.New(filepath.Join(storeDir.Path(), "config.json"))
+	config.CurrentContext = "global"
+	config.ProjectContexts = map[string]string{project.Join("mapped"): "mapped"}
+
+	resolve := func(dir string, opts *cliflags.CommonOptions) (string, string, error) {
+		defer env.ChangeWorkingDir(t, dir)()
+		return resolveContextName(opts, config, s)
+	}
+
+	name, source, err := resolve(project.Join("sub", "subsub"), &cliflags.CommonOptions{})
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal("project", name))
+	assert.Check(t, is.Equal("the "+project.Join(ProjectContextFile)+" file", source))
+
+	name, source, err = resolve(project.Join("mapped"), &cliflags.CommonOptions{})
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal("mapped", name))
+	assert.Check(t, is.Equal("the projectContexts of "+config.Filename+" for "+project.Join("mapped"), source))
+
+	name, source, err = resolve(project.Join("default"), &cliflags.CommonOptions{})
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal("", name))
+	assert.Check(t, is.Equal("the "+project.Join("default", ProjectContextFile)+" file", source))
+
+	_, _, err = resolve(project.Join("unknown"), &cliflags.CommonOptions{})
+	assert.Check(t, is.Error(err, `Context "unknown" set by the `+project.Join("unknown", ProjectContextFile)+` file is not found`))
+
+	name, source, err = resolve(project.Path(), &cliflags.CommonOptions{Context: "flag"})
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal("flag", name))
+	assert.Check(t, is.Equal("the --context flag", source))
+
+	defer env.Patch(t, "DOCKER_CONTEXT", "flag")()
+	name, source, err = resolve(project.Path(), &cliflags.CommonOptions{})
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal("flag", name))
+	assert.Check(t, is.Equal("the DOCKER_CONTEXT environment variable", source))
+	os.Unsetenv("DOCKER_CONTEXT")
+
+	// outside of the project
+	name, source, err = resolve(storeDir.Path(), &cliflags.CommonOptions{})
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal("global", name))
+	assert.Check(t, is.Equal("the currentContext of "+config.Filename, source))
+}
+
+func TestFindProjectContextInvalidFile(t *testing.T) {
+	project := fs.NewDir(t, t.Name(), fs.WithFile(ProjectContextFile, "  \n"))
+	defer project.Remove()
+	defer env.ChangeWorkingDir(t, project.Path())()
+
+	_, _, err := findProjectContext(nil)
+	assert.Check(t, is.Error(err, "invalid "+project.Join(ProjectContextFile)+" file: it must hold the name of a context"))
+
+	assert.NilError(t, ioutil.WriteFile(project.Join(ProjectContextFile), []byte("foo\nbar\n"), 0644))
+	_, _, err = findProjectContext(nil)
+	assert.Check(t, is.ErrorContains(err, "it must hold the name of a context"))
+}
+
+func TestFindProjectContextResolvesDirectories(t *testing.T) {
+	home := fs.NewDir(t, t.Name(), fs.WithDir("project"), fs.WithDir("other"))
+	defer home.Remove()
+	defer env.Patch(t, homedir.Key(), home.Path())()
+	assert.NilError(t, os.Symlink(home.Join("project"), home.Join("link")))
+	config := configfile.New(filepath.Join(home.Path(), "config.json"))
+
+	config.ProjectContexts = map[string]string{"~/project": "home"}
+	defer env.ChangeWorkingDir(t, home.Join("link"))()
+	name, source, err := findProjectContext(config)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal("home", name))
+	assert.Check(t, is.Equal("the projectContexts of "+config.Filename+" for "+evalSymlinks(home.Join("project")), source))
+
+	config.ProjectContexts = map[string]string{home.Join("link"): "symlink"}
+	name, _, err = findProjectContext(config)
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal("symlink", name))
+
+	config.ProjectContexts = map[string]string{"project": "relative"}
+	_, _, err = findProjectContext(config)
+	assert.Check(t, is.Error(err, "invalid projectContexts in "+config.Filename+`: project directory "project" is not an absolute path`))
+}
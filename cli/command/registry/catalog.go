@@ -0,0 +1,77 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/command/formatter"
+	registryclient "github.com/docker/cli/cli/registry/client"
+	"github.com/docker/cli/opts"
+	"github.com/spf13/cobra"
+)
+
+type catalogOptions struct {
+	registry string
+	format   string
+	insecure bool
+	filter   opts.FilterOpt
+}
+
+func newCatalogCommand(dockerCli command.Cli) *cobra.Command {
+	options := catalogOptions{filter: opts.NewFilterOpt()}
+
+	cmd := &cobra.Command{
+		Use:     "ls [OPTIONS] REGISTRY",
+		Aliases: []string{"catalog", "list"},
+		Short:   "List the repositories of a registry",
+		Long:    "List the repositories of a registry. The registry must support the /v2/_catalog API.",
+		Args:    cli.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			options.registry = args[0]
+			return runCatalog(dockerCli, options)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.VarP(&options.filter, "filter", "f", "Filter output based on conditions provided")
+	flags.StringVar(&options.format, "format", "", `Pretty-print repositories using a Go template, or "json"`)
+	flags.BoolVar(&options.insecure, "insecure", false, "Allow communication with an insecure registry")
+
+	return cmd
+}
+
+func runCatalog(dockerCli command.Cli, options catalogOptions) error {
+	if err := options.filter.Value().Validate(acceptedNameFilters); err != nil {
+		return err
+	}
+	rclient, err := registryclient.AsContentClient(dockerCli.RegistryClient(options.insecure))
+	if err != nil {
+		return err
+	}
+	repositories, err := rclient.GetCatalog(context.Background(), options.registry)
+	if err != nil {
+		return err
+	}
+	repositories = filterByName(repositories, options.filter.Value())
+	sortNames(repositories, sortByName)
+
+	if options.format == jsonFormatKey {
+		enc := json.NewEncoder(dockerCli.Out())
+		enc.SetIndent("", "    ")
+		return enc.Encode(struct {
+			Registry     string
+			Repositories []string
+		}{
+			Registry:     options.registry,
+			Repositories: repositories,
+		})
+	}
+
+	catalogCtx := formatter.Context{
+		Output: dockerCli.Out(),
+		Format: NewCatalogFormat(options.format),
+	}
+	return CatalogWrite(catalogCtx, options.registry, repositories)
+}
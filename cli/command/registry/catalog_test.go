@@ -0,0 +1,76 @@
+package registry
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/docker/cli/internal/test"
+	"github.com/pkg/errors"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+	"gotest.tools/golden"
+)
+
+func TestCatalogErrors(t *testing.T) {
+	testCases := []struct {
+		args          []string
+		getCatalog    func(context.Context, string) ([]string, error)
+		expectedError string
+	}{
+		{
+			args:          []string{},
+			expectedError: "requires exactly 1 argument",
+		},
+		{
+			args: []string{"registry.example.com"},
+			getCatalog: func(context.Context, string) ([]string, error) {
+				return nil, errors.New("catalog not supported")
+			},
+			expectedError: "catalog not supported",
+		},
+	}
+	for _, tc := range testCases {
+		cli := test.NewFakeCli(nil)
+		cli.SetRegistryClient(&fakeRegistryClient{getCatalogFunc: tc.getCatalog})
+		cmd := newCatalogCommand(cli)
+		cmd.SetArgs(tc.args)
+		cmd.SetOutput(ioutil.Discard)
+		assert.ErrorContains(t, cmd.Execute(), tc.expectedError)
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	testCases := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "simple",
+			args: []string{"registry.example.com"},
+		},
+		{
+			name: "filter",
+			args: []string{"--filter", "name=team/*", "registry.example.com"},
+		},
+		{
+			name: "json",
+			args: []string{"--format", "json", "registry.example.com"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cli := test.NewFakeCli(nil)
+			cli.SetRegistryClient(&fakeRegistryClient{
+				getCatalogFunc: func(_ context.Context, domain string) ([]string, error) {
+					assert.Check(t, is.Equal("registry.example.com", domain))
+					return []string{"team/web", "base/alpine", "team/api"}, nil
+				},
+			})
+			cmd := newCatalogCommand(cli)
+			cmd.SetArgs(tc.args)
+			assert.NilError(t, cmd.Execute())
+			golden.Assert(t, cli.OutBuffer().String(), "catalog-command-success."+tc.name+".golden")
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package registry
+
+import (
+	"context"
+
+	registryclient "github.com/docker/cli/cli/registry/client"
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/reference"
+)
+
+type fakeRegistryClient struct {
+	registryclient.ContentClient
+	getTagsFunc    func(ctx context.Context, ref reference.Named) ([]string, error)
+	getCatalogFunc func(ctx context.Context, domain string) ([]string, error)
+
+	getRawManifestFunc func(ctx context.Context, ref reference.Named) (distribution.Manifest, error)
+	deleteManifestFunc func(ctx context.Context, ref reference.Canonical) error
+}
+
+func (c *fakeRegistryClient) GetTags(ctx context.Context, ref reference.Named) ([]string, error) {
+	if c.getTagsFunc != nil {
+		return c.getTagsFunc(ctx, ref)
+	}
+	return nil, nil
+}
+
+func (c *fakeRegistryClient) GetCatalog(ctx context.Context, domain string) ([]string, error) {
+	if c.getCatalogFunc != nil {
+		return c.getCatalogFunc(ctx, domain)
+	}
+	return nil, nil
+}
+
+func (c *fakeRegistryClient) GetRawManifest(ctx context.Context, ref reference.Named) (distribution.Manifest, error) {
+	if c.getRawManifestFunc != nil {
+		return c.getRawManifestFunc(ctx, ref)
+	}
+	return nil, nil
+}
+
+func (c *fakeRegistryClient) DeleteManifest(ctx context.Context, ref reference.Canonical) error {
+	if c.deleteManifestFunc != nil {
+		return c.deleteManifestFunc(ctx, ref)
+	}
+	return nil
+}
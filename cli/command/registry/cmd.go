@@ -0,0 +1,23 @@
+package registry
+
+import (
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/spf13/cobra"
+)
+
+// NewRegistryCommand returns a cobra command for `registry` subcommands
+func NewRegistryCommand(dockerCli command.Cli) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "registry",
+		Short: "Manage repositories and tags of a registry",
+		Args:  cli.NoArgs,
+		RunE:  command.ShowHelp(dockerCli.Err()),
+	}
+	cmd.AddCommand(
+		newTagsCommand(dockerCli),
+		newCatalogCommand(dockerCli),
+		newRemoveCommand(dockerCli),
+	)
+	return cmd
+}
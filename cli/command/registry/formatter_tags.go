@@ -0,0 +1,105 @@
+package registry
+
+import (
+	"github.com/docker/cli/cli/command/formatter"
+	"github.com/docker/distribution/reference"
+)
+
+const (
+	defaultTagsTableFormat    = "table {{.Tag}}"
+	defaultCatalogTableFormat = "table {{.Repository}}"
+
+	tagHeader        = "TAG"
+	repositoryHeader = "REPOSITORY"
+)
+
+// NewTagsFormat returns a Format for rendering the tags of a repository
+func NewTagsFormat(source string) formatter.Format {
+	switch source {
+	case "", formatter.TableFormatKey:
+		return defaultTagsTableFormat
+	}
+	return formatter.Format(source)
+}
+
+// TagsWrite writes the tags of a repository
+func TagsWrite(ctx formatter.Context, repository reference.Named, tags []string) error {
+	render := func(format func(subContext formatter.SubContext) error) error {
+		for _, tag := range tags {
+			if err := format(&tagContext{repository: repository, tag: tag}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	tagsCtx := tagContext{}
+	tagsCtx.Header = formatter.SubHeaderContext{
+		"Repository": repositoryHeader,
+		"Tag":        tagHeader,
+	}
+	return ctx.Write(&tagsCtx, render)
+}
+
+type tagContext struct {
+	formatter.HeaderContext
+	repository reference.Named
+	tag        string
+}
+
+func (c *tagContext) MarshalJSON() ([]byte, error) {
+	return formatter.MarshalJSON(c)
+}
+
+func (c *tagContext) Repository() string {
+	return reference.FamiliarName(c.repository)
+}
+
+func (c *tagContext) Tag() string {
+	return c.tag
+}
+
+// NewCatalogFormat returns a Format for rendering the repositories of a
+// registry
+func NewCatalogFormat(source string) formatter.Format {
+	switch source {
+	case "", formatter.TableFormatKey:
+		return defaultCatalogTableFormat
+	}
+	return formatter.Format(source)
+}
+
+// CatalogWrite writes the repositories of a registry
+func CatalogWrite(ctx formatter.Context, registry string, repositories []string) error {
+	render := func(format func(subContext formatter.SubContext) error) error {
+		for _, repository := range repositories {
+			if err := format(&repositoryContext{registry: registry, repository: repository}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	catalogCtx := repositoryContext{}
+	catalogCtx.Header = formatter.SubHeaderContext{
+		"Registry":   "REGISTRY",
+		"Repository": repositoryHeader,
+	}
+	return ctx.Write(&catalogCtx, render)
+}
+
+type repositoryContext struct {
+	formatter.HeaderContext
+	registry   string
+	repository string
+}
+
+func (c *repositoryContext) MarshalJSON() ([]byte, error) {
+	return formatter.MarshalJSON(c)
+}
+
+func (c *repositoryContext) Registry() string {
+	return c.registry
+}
+
+func (c *repositoryContext) Repository() string {
+	return c.repository
+}
@@ -0,0 +1,122 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	registryclient "github.com/docker/cli/cli/registry/client"
+	"github.com/docker/distribution/reference"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type removeOptions struct {
+	refs     []string
+	force    bool
+	dryRun   bool
+	insecure bool
+}
+
+func newRemoveCommand(dockerCli command.Cli) *cobra.Command {
+	var options removeOptions
+
+	cmd := &cobra.Command{
+		Use:     "rm [OPTIONS] REPOSITORY:TAG|REPOSITORY@DIGEST [REPOSITORY:TAG|REPOSITORY@DIGEST...]",
+		Aliases: []string{"remove"},
+		Short:   "Delete one or more manifests from a registry",
+		Args:    cli.RequiresMinArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			options.refs = args
+			return runRemove(dockerCli, options)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVarP(&options.force, "force", "f", false, "Do not prompt for confirmation")
+	flags.BoolVar(&options.dryRun, "dry-run", false, "Only show the manifests which would be deleted")
+	flags.BoolVar(&options.insecure, "insecure", false, "Allow communication with an insecure registry")
+
+	return cmd
+}
+
+const removeWarning = `WARNING! This will delete the following manifests from their registry:
+  - %s
+All the tags referencing these manifests will be deleted.
+Are you sure you want to continue?`
+
+func runRemove(dockerCli command.Cli, options removeOptions) error {
+	ctx := context.Background()
+	rclient, err := registryclient.AsContentClient(dockerCli.RegistryClient(options.insecure))
+	if err != nil {
+		return err
+	}
+
+	var refs []reference.Canonical
+	for _, ref := range options.refs {
+		canonical, err := resolveDigest(ctx, rclient, ref)
+		if err != nil {
+			return err
+		}
+		refs = append(refs, canonical)
+	}
+
+	if options.dryRun {
+		for _, ref := range refs {
+			fmt.Fprintf(dockerCli.Out(), "Would delete: %s\n", reference.FamiliarString(ref))
+		}
+		return nil
+	}
+
+	if !options.force {
+		names := make([]string, 0, len(refs))
+		for _, ref := range refs {
+			names = append(names, reference.FamiliarString(ref))
+		}
+		warning := fmt.Sprintf(removeWarning, strings.Join(names, "\n  - "))
+		if !command.PromptForConfirmation(dockerCli.In(), dockerCli.Out(), warning) {
+			return nil
+		}
+	}
+
+	var errs []string
+	for _, ref := range refs {
+		if err := rclient.DeleteManifest(ctx, ref); err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		fmt.Fprintf(dockerCli.Out(), "Deleted: %s\n", reference.FamiliarString(ref))
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// resolveDigest returns the reference by digest of the manifest a tag points
+// to. References by digest are returned as is.
+func resolveDigest(ctx context.Context, rclient registryclient.ContentClient, ref string) (reference.Canonical, error) {
+	namedRef, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return nil, err
+	}
+	if canonical, ok := namedRef.(reference.Canonical); ok {
+		return canonical, nil
+	}
+	if reference.IsNameOnly(namedRef) {
+		return nil, errors.Errorf("%s must reference a tag or a digest", ref)
+	}
+
+	manifest, err := rclient.GetRawManifest(ctx, namedRef)
+	if err != nil {
+		return nil, err
+	}
+	_, payload, err := manifest.Payload()
+	if err != nil {
+		return nil, err
+	}
+	return reference.WithDigest(reference.TrimNamed(namedRef), digest.FromBytes(payload))
+}
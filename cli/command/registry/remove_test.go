@@ -0,0 +1,148 @@
+package registry
+
+import (
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	registryclient "github.com/docker/cli/cli/registry/client"
+	"github.com/docker/cli/cli/streams"
+	"github.com/docker/cli/internal/test"
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/docker/distribution/reference"
+	digest "github.com/opencontainers/go-digest"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+func newFakeRemoveClient(t *testing.T, deleted *[]string) (*fakeRegistryClient, digest.Digest) {
+	manifest, err := schema2.FromStruct(schema2.Manifest{
+		Versioned: schema2.SchemaVersion,
+		Config: distribution.Descriptor{
+			MediaType: schema2.MediaTypeImageConfig,
+			Digest:    digest.FromString("config"),
+		},
+	})
+	assert.NilError(t, err)
+	_, payload, err := manifest.Payload()
+	assert.NilError(t, err)
+
+	return &fakeRegistryClient{
+		getRawManifestFunc: func(_ context.Context, ref reference.Named) (distribution.Manifest, error) {
+			assert.Check(t, is.Equal("example.com/app:1.2", ref.String()))
+			return manifest, nil
+		},
+		deleteManifestFunc: func(_ context.Context, ref reference.Canonical) error {
+			*deleted = append(*deleted, ref.String())
+			return nil
+		},
+	}, digest.FromBytes(payload)
+}
+
+func TestRemoveErrors(t *testing.T) {
+	testCases := []struct {
+		args          []string
+		expectedError string
+	}{
+		{
+			args:          []string{},
+			expectedError: "requires at least 1 argument",
+		},
+		{
+			args:          []string{"example.com/app"},
+			expectedError: "must reference a tag or a digest",
+		},
+		{
+			args:          []string{"Example.com/App:1.2"},
+			expectedError: "must be lowercase",
+		},
+	}
+	for _, tc := range testCases {
+		cli := test.NewFakeCli(nil)
+		cli.SetRegistryClient(&fakeRegistryClient{})
+		cmd := newRemoveCommand(cli)
+		cmd.SetArgs(tc.args)
+		cmd.SetOutput(ioutil.Discard)
+		assert.ErrorContains(t, cmd.Execute(), tc.expectedError)
+	}
+}
+
+func TestRemoveTag(t *testing.T) {
+	var deleted []string
+	registryClient, dgst := newFakeRemoveClient(t, &deleted)
+	cli := test.NewFakeCli(nil)
+	cli.SetRegistryClient(registryClient)
+	cmd := newRemoveCommand(cli)
+	cmd.SetArgs([]string{"--force", "example.com/app:1.2"})
+	assert.NilError(t, cmd.Execute())
+	assert.Check(t, is.DeepEqual([]string{"example.com/app@" + dgst.String()}, deleted))
+	assert.Check(t, is.Equal("Deleted: example.com/app@"+dgst.String()+"\n", cli.OutBuffer().String()))
+}
+
+func TestRemoveDigestDoesNotResolve(t *testing.T) {
+	var deleted []string
+	dgst := digest.FromString("manifest")
+	cli := test.NewFakeCli(nil)
+	cli.SetRegistryClient(&fakeRegistryClient{
+		getRawManifestFunc: func(_ context.Context, ref reference.Named) (distribution.Manifest, error) {
+			t.Fatal("digest should not be resolved")
+			return nil, nil
+		},
+		deleteManifestFunc: func(_ context.Context, ref reference.Canonical) error {
+			deleted = append(deleted, ref.String())
+			return nil
+		},
+	})
+	cmd := newRemoveCommand(cli)
+	cmd.SetArgs([]string{"-f", "example.com/app@" + dgst.String()})
+	assert.NilError(t, cmd.Execute())
+	assert.Check(t, is.DeepEqual([]string{"example.com/app@" + dgst.String()}, deleted))
+}
+
+func TestRemoveDryRun(t *testing.T) {
+	var deleted []string
+	registryClient, dgst := newFakeRemoveClient(t, &deleted)
+	cli := test.NewFakeCli(nil)
+	cli.SetRegistryClient(registryClient)
+	cmd := newRemoveCommand(cli)
+	cmd.SetArgs([]string{"--dry-run", "example.com/app:1.2"})
+	assert.NilError(t, cmd.Execute())
+	assert.Check(t, is.Len(deleted, 0))
+	assert.Check(t, is.Equal("Would delete: example.com/app@"+dgst.String()+"\n", cli.OutBuffer().String()))
+}
+
+func TestRemovePrompt(t *testing.T) {
+	for _, input := range []string{"n", "y"} {
+		var deleted []string
+		registryClient, _ := newFakeRemoveClient(t, &deleted)
+		cli := test.NewFakeCli(nil)
+		cli.SetRegistryClient(registryClient)
+		cli.SetIn(streams.NewIn(ioutil.NopCloser(strings.NewReader(input))))
+		cmd := newRemoveCommand(cli)
+		cmd.SetArgs([]string{"example.com/app:1.2"})
+		assert.NilError(t, cmd.Execute())
+		assert.Check(t, is.Contains(cli.OutBuffer().String(), "All the tags referencing these manifests will be deleted."))
+		if input == "y" {
+			assert.Check(t, is.Len(deleted, 1))
+		} else {
+			assert.Check(t, is.Len(deleted, 0))
+		}
+	}
+}
+
+func TestRemoveDeleteDisabled(t *testing.T) {
+	var deleted []string
+	registryClient, _ := newFakeRemoveClient(t, &deleted)
+	registryClient.deleteManifestFunc = func(_ context.Context, ref reference.Canonical) error {
+		return registryclient.ErrDeleteDisabled{Registry: reference.Domain(ref)}
+	}
+	cli := test.NewFakeCli(nil)
+	cli.SetRegistryClient(registryClient)
+	cmd := newRemoveCommand(cli)
+	cmd.SetArgs([]string{"-f", "example.com/app:1.2"})
+	cmd.SetOutput(ioutil.Discard)
+	assert.ErrorContains(t, cmd.Execute(), "registry example.com does not allow deleting manifests")
+	assert.Check(t, is.Equal("", cli.OutBuffer().String()))
+}
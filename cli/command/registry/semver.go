@@ -0,0 +1,119 @@
+package registry
+
+import (
+	"strconv"
+	"strings"
+)
+
+// semver is a version loosely following https://semver.org. Tags often omit
+// the minor or patch number (e.g. "3.9"), and are sometimes prefixed with a
+// "v", so both are accepted. Build metadata is ignored.
+type semver struct {
+	numbers    [3]uint64
+	prerelease []string
+}
+
+func parseSemver(tag string) (semver, bool) {
+	var v semver
+	s := strings.TrimPrefix(tag, "v")
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		s = s[:i]
+	}
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		if i == len(s)-1 {
+			return v, false
+		}
+		v.prerelease = strings.Split(s[i+1:], ".")
+		s = s[:i]
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) > len(v.numbers) {
+		return v, false
+	}
+	for i, part := range parts {
+		n, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return v, false
+		}
+		v.numbers[i] = n
+	}
+	return v, true
+}
+
+// compare returns -1, 0 or 1 when v is respectively lower than, equal to or
+// greater than other
+func (v semver) compare(other semver) int {
+	for i := range v.numbers {
+		if v.numbers[i] != other.numbers[i] {
+			if v.numbers[i] < other.numbers[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	// a release has a higher precedence than its pre-releases
+	switch {
+	case len(v.prerelease) == 0 && len(other.prerelease) == 0:
+		return 0
+	case len(v.prerelease) == 0:
+		return 1
+	case len(other.prerelease) == 0:
+		return -1
+	}
+	for i := 0; i < len(v.prerelease) && i < len(other.prerelease); i++ {
+		if c := compareIdentifiers(v.prerelease[i], other.prerelease[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInts(len(v.prerelease), len(other.prerelease))
+}
+
+func compareIdentifiers(a, b string) int {
+	na, errA := strconv.ParseUint(a, 10, 64)
+	nb, errB := strconv.ParseUint(b, 10, 64)
+	switch {
+	case errA == nil && errB == nil:
+		if na == nb {
+			return 0
+		}
+		if na < nb {
+			return -1
+		}
+		return 1
+	case errA == nil:
+		// numeric identifiers have a lower precedence
+		return -1
+	case errB == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// lessSemver orders semantic versions by precedence, followed by the other
+// tags in lexical order
+func lessSemver(a, b string) bool {
+	va, okA := parseSemver(a)
+	vb, okB := parseSemver(b)
+	switch {
+	case okA && okB:
+		if c := va.compare(vb); c != 0 {
+			return c < 0
+		}
+		return a < b
+	case okA:
+		return true
+	case okB:
+		return false
+	}
+	return a < b
+}
@@ -0,0 +1,136 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+	"sort"
+
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/command/formatter"
+	"github.com/docker/cli/opts"
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	sortByName   = "name"
+	sortBySemver = "semver"
+
+	// jsonFormatKey is the format printing the whole result as a JSON document
+	jsonFormatKey = "json"
+)
+
+type tagsOptions struct {
+	repository string
+	format     string
+	sort       string
+	insecure   bool
+	filter     opts.FilterOpt
+}
+
+func newTagsCommand(dockerCli command.Cli) *cobra.Command {
+	options := tagsOptions{filter: opts.NewFilterOpt()}
+
+	cmd := &cobra.Command{
+		Use:   "tags [OPTIONS] REPOSITORY",
+		Short: "List the tags of a repository",
+		Args:  cli.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			options.repository = args[0]
+			return runTags(dockerCli, options)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.VarP(&options.filter, "filter", "f", "Filter output based on conditions provided")
+	flags.StringVar(&options.sort, "sort", sortByName, `Sort tags by "name" or "semver"`)
+	flags.StringVar(&options.format, "format", "", `Pretty-print tags using a Go template, or "json"`)
+	flags.BoolVar(&options.insecure, "insecure", false, "Allow communication with an insecure registry")
+
+	return cmd
+}
+
+func runTags(dockerCli command.Cli, options tagsOptions) error {
+	if err := validateSort(options.sort); err != nil {
+		return err
+	}
+	if err := options.filter.Value().Validate(acceptedNameFilters); err != nil {
+		return err
+	}
+	namedRef, err := reference.ParseNormalizedNamed(options.repository)
+	if err != nil {
+		return err
+	}
+	if !reference.IsNameOnly(namedRef) {
+		return errors.Errorf("%s must be a repository name, without tag or digest", options.repository)
+	}
+
+	tags, err := dockerCli.RegistryClient(options.insecure).GetTags(context.Background(), namedRef)
+	if err != nil {
+		return err
+	}
+	tags = filterByName(tags, options.filter.Value())
+	sortNames(tags, options.sort)
+
+	if options.format == jsonFormatKey {
+		enc := json.NewEncoder(dockerCli.Out())
+		enc.SetIndent("", "    ")
+		return enc.Encode(struct {
+			Repository string
+			Tags       []string
+		}{
+			Repository: namedRef.Name(),
+			Tags:       tags,
+		})
+	}
+
+	tagsCtx := formatter.Context{
+		Output: dockerCli.Out(),
+		Format: NewTagsFormat(options.format),
+	}
+	return TagsWrite(tagsCtx, namedRef, tags)
+}
+
+var acceptedNameFilters = map[string]bool{
+	"name": true,
+}
+
+func validateSort(value string) error {
+	switch value {
+	case sortByName, sortBySemver:
+		return nil
+	}
+	return errors.Errorf("invalid sort %q: must be %q or %q", value, sortByName, sortBySemver)
+}
+
+// filterByName keeps the names matching any of the "name" filter patterns
+func filterByName(names []string, filter filters.Args) []string {
+	patterns := filter.Get("name")
+	if len(patterns) == 0 {
+		return names
+	}
+	var result []string
+	for _, name := range names {
+		for _, pattern := range patterns {
+			if matched, _ := path.Match(pattern, name); matched {
+				result = append(result, name)
+				break
+			}
+		}
+	}
+	return result
+}
+
+func sortNames(names []string, by string) {
+	if by != sortBySemver {
+		sort.Strings(names)
+		return
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		return lessSemver(names[i], names[j])
+	})
+}
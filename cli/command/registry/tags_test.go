@@ -0,0 +1,111 @@
+package registry
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/docker/cli/internal/test"
+	"github.com/docker/distribution/reference"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+	"gotest.tools/golden"
+)
+
+func newFakeTagsClient(tags ...string) *fakeRegistryClient {
+	return &fakeRegistryClient{
+		getTagsFunc: func(_ context.Context, ref reference.Named) ([]string, error) {
+			return tags, nil
+		},
+	}
+}
+
+func TestTagsErrors(t *testing.T) {
+	testCases := []struct {
+		args          []string
+		expectedError string
+	}{
+		{
+			args:          []string{},
+			expectedError: "requires exactly 1 argument",
+		},
+		{
+			args:          []string{"alpine:3.9"},
+			expectedError: "must be a repository name, without tag or digest",
+		},
+		{
+			args:          []string{"--sort", "date", "alpine"},
+			expectedError: `invalid sort "date"`,
+		},
+		{
+			args:          []string{"--filter", "stars=3", "alpine"},
+			expectedError: "Invalid filter 'stars'",
+		},
+	}
+	for _, tc := range testCases {
+		cli := test.NewFakeCli(nil)
+		cli.SetRegistryClient(newFakeTagsClient())
+		cmd := newTagsCommand(cli)
+		cmd.SetArgs(tc.args)
+		cmd.SetOutput(ioutil.Discard)
+		assert.ErrorContains(t, cmd.Execute(), tc.expectedError)
+	}
+}
+
+func TestTags(t *testing.T) {
+	tags := []string{"latest", "v1.10.0", "1.2", "1.9.1", "1.10.0-rc.1", "1.10.0-beta", "edge"}
+	testCases := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "simple",
+			args: []string{"example.com/app"},
+		},
+		{
+			name: "semver",
+			args: []string{"--sort", "semver", "example.com/app"},
+		},
+		{
+			name: "filter",
+			args: []string{"--filter", "name=1.*", "--filter", "name=latest", "example.com/app"},
+		},
+		{
+			name: "format",
+			args: []string{"--format", "{{.Repository}}:{{.Tag}}", "--filter", "name=v*", "example.com/app"},
+		},
+		{
+			name: "json",
+			args: []string{"--format", "json", "--sort", "semver", "--filter", "name=1.10*", "example.com/app"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cli := test.NewFakeCli(nil)
+			cli.SetRegistryClient(newFakeTagsClient(tags...))
+			cmd := newTagsCommand(cli)
+			cmd.SetArgs(tc.args)
+			assert.NilError(t, cmd.Execute())
+			golden.Assert(t, cli.OutBuffer().String(), "tags-command-success."+tc.name+".golden")
+		})
+	}
+}
+
+func TestLessSemver(t *testing.T) {
+	tags := []string{"edge", "2", "1.0.0", "1.0.0-alpha.beta", "1.0.0-alpha", "1.0.0-beta.11", "1.0.0-beta.2", "1.0.0-alpha.1", "v0.9", "1.0.0-rc.1", "1.0.0-beta", "abc"}
+	sortNames(tags, sortBySemver)
+	assert.Check(t, is.DeepEqual([]string{
+		"v0.9",
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"2",
+		"abc",
+		"edge",
+	}, tags))
+}
@@ -119,12 +119,17 @@ func prettyPrintClientInfo(dockerCli command.Cli, info clientInfo) error {
 	if len(info.Plugins) > 0 {
 		fmt.Fprintln(dockerCli.Out(), " Plugins:")
 		for _, p := range info.Plugins {
-			if p.Err == nil {
+			if p.Err == nil || pluginmanager.IsIncompatible(p.Err) {
 				var version string
 				if p.Version != "" {
 					version = ", " + p.Version
 				}
 				fmt.Fprintf(dockerCli.Out(), "  %s: %s (%s%s)\n", p.Name, p.ShortDescription, p.Vendor, version)
+				if p.Err != nil {
+					fmt.Fprintf(dockerCli.Out(), "   Incompatible: %s\n", p.Err)
+				}
+			} else if pluginmanager.IsUnverified(p.Err) {
+				info.Warnings = append(info.Warnings, fmt.Sprintf("WARNING: Plugin %q is not verified: %s", p.Path, p.Err))
 			} else {
 				info.Warnings = append(info.Warnings, fmt.Sprintf("WARNING: Plugin %q is not valid: %s", p.Path, p.Err))
 			}
@@ -218,6 +218,23 @@ var samplePluginsInfo = []pluginmanager.Plugin{
 		Path: "/path/to/docker-badplugin",
 		Err:  pluginmanager.NewPluginError("something wrong"),
 	},
+	{
+		Name: "futureplugin",
+		Path: "/path/to/docker-futureplugin",
+		Metadata: pluginmanager.Metadata{
+			SchemaVersion:    "0.2.0",
+			ShortDescription: "this plugin requires a newer daemon",
+			Vendor:           "ACME Corp",
+			Version:          "2.0.0",
+			Capabilities:     &pluginmanager.Capabilities{MinAPIVersion: "9.99"},
+		},
+		Err: pluginmanager.NewIncompatiblePluginError("plugin requires API version 9.99, but the Docker daemon API version is 1.40"),
+	},
+	{
+		Name: "unverifiedplugin",
+		Path: "/path/to/docker-unverifiedplugin",
+		Err:  pluginmanager.NewUnverifiedPluginError("plugin digest is not trusted and plugin is not signed"),
+	},
 }
 
 func TestPrettyPrintInfo(t *testing.T) {
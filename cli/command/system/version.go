@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/docker/cli/cli"
+	pluginmanager "github.com/docker/cli/cli-plugins/manager"
 	"github.com/docker/cli/cli/command"
 	kubecontext "github.com/docker/cli/cli/context/kubernetes"
 	"github.com/docker/cli/cli/version"
@@ -32,6 +33,12 @@ Client:{{if ne .Platform.Name ""}} {{.Platform.Name}}{{end}}
  Built:	{{.BuildTime}}
  OS/Arch:	{{.Os}}/{{.Arch}}
  Experimental:	{{.Experimental}}
+{{- if .Plugins}}
+ Plugins:
+ {{- range .Plugins}}
+  {{.Name}}:	{{if .Version}}{{.Version}} {{end}}({{.Vendor}}){{if .Incompatible}}, incompatible: {{.Incompatible}}{{end}}
+ {{- end}}
+{{- end}}
 {{- end}}
 
 {{- if .ServerOK}}{{with .Server}}
@@ -80,6 +87,17 @@ type clientVersion struct {
 	Arch              string
 	BuildTime         string `json:",omitempty"`
 	Experimental      bool
+	PluginAPIVersion  string          `json:",omitempty"`
+	Plugins           []pluginVersion `json:",omitempty"`
+}
+
+// pluginVersion is the version of a CLI plugin, and the reason it is
+// incompatible with the CLI or the daemon, if it is
+type pluginVersion struct {
+	Name         string
+	Version      string `json:",omitempty"`
+	Vendor       string
+	Incompatible string `json:",omitempty"`
 }
 
 type kubernetesVersion struct {
@@ -102,7 +120,7 @@ func NewVersionCommand(dockerCli command.Cli) *cobra.Command {
 		Short: "Show the Docker version information",
 		Args:  cli.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runVersion(dockerCli, &opts)
+			return runVersion(dockerCli, cmd.Root(), &opts)
 		},
 	}
 
@@ -122,7 +140,7 @@ func reformatDate(buildTime string) string {
 	return buildTime
 }
 
-func runVersion(dockerCli command.Cli, opts *versionOptions) error {
+func runVersion(dockerCli command.Cli, rootCmd *cobra.Command, opts *versionOptions) error {
 	var err error
 	tmpl, err := newVersionTemplate(opts.format)
 	if err != nil {
@@ -146,6 +164,8 @@ func runVersion(dockerCli command.Cli, opts *versionOptions) error {
 			Os:                runtime.GOOS,
 			Arch:              runtime.GOARCH,
 			Experimental:      dockerCli.ClientInfo().HasExperimental,
+			PluginAPIVersion:  pluginmanager.PluginAPIVersion,
+			Plugins:           getPluginVersions(dockerCli, rootCmd),
 		},
 	}
 
@@ -206,6 +226,28 @@ func runVersion(dockerCli command.Cli, opts *versionOptions) error {
 	return err
 }
 
+// getPluginVersions returns the versions of the valid CLI plugins, compatible
+// or not
+func getPluginVersions(dockerCli command.Cli, rootCmd *cobra.Command) []pluginVersion {
+	plugins, err := pluginmanager.ListPlugins(dockerCli, rootCmd)
+	if err != nil {
+		logrus.Debugf("failed to list the CLI plugins: %v", err)
+		return nil
+	}
+	var versions []pluginVersion
+	for _, p := range plugins {
+		if p.Err != nil && !pluginmanager.IsIncompatible(p.Err) {
+			continue
+		}
+		v := pluginVersion{Name: p.Name, Version: p.Version, Vendor: p.Vendor}
+		if p.Err != nil {
+			v.Incompatible = p.Err.Error()
+		}
+		versions = append(versions, v)
+	}
+	return versions
+}
+
 func prettyPrintVersion(dockerCli command.Cli, vd versionInfo, tmpl *template.Template) error {
 	t := tabwriter.NewWriter(dockerCli.Out(), 20, 1, 1, ' ', 0)
 	err := tmpl.Execute(t, vd)
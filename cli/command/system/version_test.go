@@ -42,6 +42,11 @@ func TestVersionAlign(t *testing.T) {
 			Arch:              "amd64",
 			BuildTime:         "Wed May 30 22:21:05 2018",
 			Experimental:      true,
+			PluginAPIVersion:  "1.2",
+			Plugins: []pluginVersion{
+				{Name: "helloworld", Version: "0.1.0", Vendor: "Docker Inc."},
+				{Name: "scan", Vendor: "ACME Corp", Incompatible: "plugin requires API version 1.40, but the Docker daemon API version is 1.30"},
+			},
 		},
 		Server: &types.Version{},
 	}
@@ -1,6 +1,12 @@
 package command
 
 import (
+	"path/filepath"
+
+	cliconfig "github.com/docker/cli/cli/config"
+	"github.com/docker/cli/cli/context/store"
+	"github.com/docker/cli/cli/trust"
+	"github.com/docker/distribution/reference"
 	"github.com/spf13/pflag"
 )
 
@@ -13,3 +19,50 @@ func AddTrustVerificationFlags(fs *pflag.FlagSet, v *bool, trusted bool) {
 func AddTrustSigningFlags(fs *pflag.FlagSet, v *bool, trusted bool) {
 	fs.BoolVar(v, "disable-content-trust", !trusted, "Skip image signing")
 }
+
+// ContentTrustPolicy returns the content trust policy enforced by the cli, or
+// nil if there is none. The path of the policy file is read from the metadata
+// of the current context, then from the config file. Relative paths are
+// relative to the config directory.
+//
+// The policy file is read on every call: commands load the policy once, and
+// pass it to the functions checking content trust.
+func ContentTrustPolicy(cli Cli) (*trust.Policy, error) {
+	var policyFile string
+	if currentContext := cli.CurrentContext(); currentContext != "" && cli.ContextStore() != nil {
+		ctxRaw, err := cli.ContextStore().GetContextMetadata(currentContext)
+		if err != nil && !store.IsErrContextDoesNotExist(err) {
+			return nil, err
+		}
+		if err == nil {
+			ctxMeta, err := GetDockerContext(ctxRaw)
+			if err != nil {
+				return nil, err
+			}
+			policyFile = ctxMeta.ContentTrustPolicy
+		}
+	}
+	if policyFile == "" && cli.ConfigFile() != nil {
+		policyFile = cli.ConfigFile().ContentTrustPolicy
+	}
+	if policyFile == "" {
+		return nil, nil
+	}
+	if !filepath.IsAbs(policyFile) {
+		policyFile = filepath.Join(cliconfig.Dir(), policyFile)
+	}
+	return trust.LoadPolicy(policyFile)
+}
+
+// ContentTrustVerified returns whether the content trust of the named
+// repository must be verified, given the value of the --disable-content-trust
+// flag. The content trust policy, which may be nil, requires or skips the
+// verification for the repositories it matches, the flag decides for the
+// others.
+func ContentTrustVerified(policy *trust.Policy, name reference.Named, untrusted bool) bool {
+	rule, ok := policy.Rule(name.Name())
+	if !ok {
+		return !untrusted
+	}
+	return !rule.Insecure
+}
@@ -20,6 +20,8 @@ func NewTrustCommand(dockerCli command.Cli) *cobra.Command {
 		newTrustKeyCommand(dockerCli),
 		newTrustSignerCommand(dockerCli),
 		newInspectCommand(dockerCli),
+		newExportCommand(dockerCli),
+		newVerifyCommand(dockerCli),
 	)
 	return cmd
 }
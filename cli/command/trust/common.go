@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/docker/cli/cli/command"
 	"github.com/docker/cli/cli/command/image"
@@ -47,6 +48,9 @@ type trustSigner struct {
 // trustKey contains information about trusted keys
 type trustKey struct {
 	ID string `json:",omitempty"`
+	// Expiry is the expiry date of the certificate of the key, if the key
+	// has a certificate. It is only set in trust reports.
+	Expiry *time.Time `json:",omitempty"`
 }
 
 // lookupTrustInfo returns processed signature and role information about a notary repository.
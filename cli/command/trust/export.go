@@ -0,0 +1,126 @@
+package trust
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/command/image"
+	registryclient "github.com/docker/cli/cli/registry/client"
+	"github.com/docker/cli/cli/trust"
+	"github.com/docker/distribution/reference"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/theupdateframework/notary/tuf/data"
+)
+
+type exportOptions struct {
+	remote string
+	output string
+}
+
+func newExportCommand(dockerCli command.Cli) *cobra.Command {
+	var options exportOptions
+	cmd := &cobra.Command{
+		Use:   "export [OPTIONS] REPOSITORY",
+		Short: "Export the trust data of a repository to verify it offline",
+		Args:  cli.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			options.remote = args[0]
+			return runExport(dockerCli, options)
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVarP(&options.output, "output", "o", "", "Write to a file, instead of STDOUT")
+	return cmd
+}
+
+func runExport(dockerCli command.Cli, options exportOptions) error {
+	if err := command.ValidateOutputPath(options.output); err != nil {
+		return errors.Wrap(err, "failed to export trust data")
+	}
+
+	ctx := context.Background()
+	imgRefAndAuth, err := trust.GetImageReferencesAndAuth(ctx, nil, image.AuthResolver(dockerCli), options.remote)
+	if err != nil {
+		return err
+	}
+	if imgRefAndAuth.Tag() != "" || imgRefAndAuth.Digest() != "" {
+		return errors.Errorf("invalid repository %s: the trust data of all the signed tags is exported", options.remote)
+	}
+	notaryRepo, err := dockerCli.NotaryClient(imgRefAndAuth, trust.ActionsPullOnly)
+	if err != nil {
+		return trust.NotaryError(imgRefAndAuth.Reference().Name(), err)
+	}
+	// listing the targets updates the metadata cached by the notary client
+	targets, err := notaryRepo.ListTargets(trust.ReleasesRole, data.CanonicalTargetsRole)
+	if err != nil {
+		return trust.NotaryError(imgRefAndAuth.Reference().Name(), err)
+	}
+
+	gun := imgRefAndAuth.RepoInfo().Name.Name()
+	bundle, err := trust.GetCachedBundle(gun)
+	if err != nil {
+		return err
+	}
+
+	bundle.Manifests = make(map[string][]byte)
+	registryClient := dockerCli.RegistryClient(!imgRefAndAuth.RepoInfo().Index.Secure)
+	for _, tgt := range targets {
+		if tgt.Role != trust.ReleasesRole && tgt.Role != data.CanonicalTargetsRole {
+			continue
+		}
+		if _, ok := bundle.Manifests[tgt.Name]; ok {
+			continue
+		}
+		tagged, err := reference.WithTag(imgRefAndAuth.RepoInfo().Name, tgt.Name)
+		if err != nil {
+			return err
+		}
+		manifest, err := getSignedManifest(ctx, registryClient, tagged, tgt.Hashes["sha256"])
+		if err != nil {
+			fmt.Fprintf(dockerCli.Err(), "WARNING: Not exporting the manifest of %s: %v\n", reference.FamiliarString(tagged), err)
+			continue
+		}
+		bundle.Manifests[tgt.Name] = manifest
+	}
+
+	content, err := json.Marshal(bundle)
+	if err != nil {
+		return err
+	}
+	if options.output == "" {
+		_, err = dockerCli.Out().Write(content)
+		return err
+	}
+	if err := command.CopyToFile(options.output, bytes.NewReader(content)); err != nil {
+		return err
+	}
+	fmt.Fprintf(dockerCli.Out(), "Exported the trust data of %s to %s\n", reference.FamiliarName(imgRefAndAuth.RepoInfo().Name), options.output)
+	return nil
+}
+
+// getSignedManifest returns the image manifest of the tagged reference, if its
+// digest matches the signed sha256 hash.
+func getSignedManifest(ctx context.Context, registryClient registryclient.RegistryClient, tagged reference.NamedTagged, signedHash []byte) ([]byte, error) {
+	manifest, err := registryClient.GetManifest(ctx, tagged)
+	if err != nil {
+		return nil, err
+	}
+	if manifest.SchemaV2Manifest == nil {
+		return nil, errors.New("unsupported manifest type")
+	}
+	_, payload, err := manifest.SchemaV2Manifest.Payload()
+	if err != nil {
+		return nil, err
+	}
+	if dgst := digest.FromBytes(payload); dgst.Hex() != hex.EncodeToString(signedHash) {
+		return nil, errors.Errorf("manifest digest %s doesn't match the signed digest", dgst)
+	}
+	return payload, nil
+}
@@ -0,0 +1,186 @@
+package trust
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/docker/cli/cli/config"
+	manifesttypes "github.com/docker/cli/cli/manifest/types"
+	registryclient "github.com/docker/cli/cli/registry/client"
+	"github.com/docker/cli/cli/trust"
+	"github.com/docker/cli/internal/test"
+	notaryfake "github.com/docker/cli/internal/test/notary"
+	"github.com/docker/distribution/reference"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	"github.com/theupdateframework/notary/client"
+	"github.com/theupdateframework/notary/tuf/data"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+	"gotest.tools/fs"
+)
+
+// exportNotaryRepository is a LoadedNotaryRepository with a tag signed with
+// a real digest
+type exportNotaryRepository struct {
+	notaryfake.LoadedNotaryRepository
+	targets []*client.TargetWithRole
+}
+
+func (r exportNotaryRepository) ListTargets(roles ...data.RoleName) ([]*client.TargetWithRole, error) {
+	return r.targets, nil
+}
+
+type exportRegistryClient struct {
+	registryclient.RegistryClient
+	manifests map[string]manifesttypes.ImageManifest
+}
+
+func (c exportRegistryClient) GetManifest(ctx context.Context, ref reference.Named) (manifesttypes.ImageManifest, error) {
+	manifest, ok := c.manifests[reference.FamiliarString(ref)]
+	if !ok {
+		return manifesttypes.ImageManifest{}, errors.Errorf("manifest unknown")
+	}
+	return manifest, nil
+}
+
+func TestTrustExport(t *testing.T) {
+	dir := fs.NewDir(t, t.Name())
+	defer dir.Remove()
+	defer config.SetDir(config.Dir())
+	config.SetDir(dir.Path())
+
+	manifest := newTestManifest(t)
+	_, payload, err := manifest.Payload()
+	assert.NilError(t, err)
+	signedDigest := digest.FromBytes(payload)
+	metadata := newSignedMetadata(t, verifyGUN, map[string]digest.Digest{"latest": signedDigest}, time.Now().Add(time.Hour))
+	writeCachedMetadataFiles(t, verifyGUN, metadata)
+
+	signedHash, err := hex.DecodeString(signedDigest.Hex())
+	assert.NilError(t, err)
+	targets := []*client.TargetWithRole{
+		{Target: client.Target{Name: "latest", Hashes: data.Hashes{"sha256": signedHash}}, Role: trust.ReleasesRole},
+		{Target: client.Target{Name: "moved", Hashes: data.Hashes{"sha256": signedHash}}, Role: trust.ReleasesRole},
+		{Target: client.Target{Name: "missing", Hashes: data.Hashes{"sha256": signedHash}}, Role: trust.ReleasesRole},
+	}
+	cli := test.NewFakeCli(&fakeClient{})
+	cli.SetNotaryClient(func(imgRefAndAuth trust.ImageRefAndAuth, actions []string) (client.Repository, error) {
+		return exportNotaryRepository{targets: targets}, nil
+	})
+	cli.SetRegistryClient(exportRegistryClient{manifests: map[string]manifesttypes.ImageManifest{
+		"signed-repo:latest": {SchemaV2Manifest: manifest},
+		"signed-repo:moved":  {SchemaV2Manifest: newTestManifestWithConfig(t, `{"os": "windows"}`)},
+	}})
+	cmd := newExportCommand(cli)
+	cmd.SetArgs([]string{"-o", dir.Join("bundle.json"), "signed-repo"})
+	cmd.SetOutput(ioutil.Discard)
+	assert.NilError(t, cmd.Execute())
+	assert.Check(t, is.Equal("Exported the trust data of signed-repo to "+dir.Join("bundle.json")+"\n", cli.OutBuffer().String()))
+	assert.Check(t, is.Contains(cli.ErrBuffer().String(), "WARNING: Not exporting the manifest of signed-repo:moved: manifest digest"))
+	assert.Check(t, is.Contains(cli.ErrBuffer().String(), "WARNING: Not exporting the manifest of signed-repo:missing: manifest unknown"))
+
+	content, err := ioutil.ReadFile(dir.Join("bundle.json"))
+	assert.NilError(t, err)
+	var bundle trust.Bundle
+	assert.NilError(t, json.Unmarshal(content, &bundle))
+	assert.Check(t, is.Equal(verifyGUN, bundle.GUN))
+	assert.Check(t, is.DeepEqual(metadata, bundle.Metadata))
+	assert.Check(t, is.DeepEqual(map[string][]byte{"latest": payload}, bundle.Manifests))
+}
+
+func TestTrustExportInsecureRegistry(t *testing.T) {
+	dir := fs.NewDir(t, t.Name())
+	defer dir.Remove()
+	defer config.SetDir(config.Dir())
+	config.SetDir(dir.Path())
+
+	const gun = "127.0.0.1:5000/signed-repo"
+	manifest := newTestManifest(t)
+	_, payload, err := manifest.Payload()
+	assert.NilError(t, err)
+	signedDigest := digest.FromBytes(payload)
+	writeCachedMetadataFiles(t, gun, newSignedMetadata(t, gun, map[string]digest.Digest{"latest": signedDigest}, time.Now().Add(time.Hour)))
+	signedHash, err := hex.DecodeString(signedDigest.Hex())
+	assert.NilError(t, err)
+
+	var insecureRegistries []bool
+	cli := test.NewFakeCli(&fakeClient{})
+	cli.SetNotaryClient(func(imgRefAndAuth trust.ImageRefAndAuth, actions []string) (client.Repository, error) {
+		return exportNotaryRepository{targets: []*client.TargetWithRole{
+			{Target: client.Target{Name: "latest", Hashes: data.Hashes{"sha256": signedHash}}, Role: trust.ReleasesRole},
+		}}, nil
+	})
+	cli.SetRegistryClientFunc(func(insecure bool) registryclient.RegistryClient {
+		insecureRegistries = append(insecureRegistries, insecure)
+		return exportRegistryClient{manifests: map[string]manifesttypes.ImageManifest{
+			gun + ":latest": {SchemaV2Manifest: manifest},
+		}}
+	})
+	cmd := newExportCommand(cli)
+	cmd.SetArgs([]string{"-o", dir.Join("bundle.json"), gun})
+	cmd.SetOutput(ioutil.Discard)
+	assert.NilError(t, cmd.Execute())
+	assert.Check(t, is.DeepEqual([]bool{true}, insecureRegistries))
+	assert.Check(t, is.Equal("", cli.ErrBuffer().String()))
+}
+
+func TestTrustExportErrors(t *testing.T) {
+	dir := fs.NewDir(t, t.Name())
+	defer dir.Remove()
+	defer config.SetDir(config.Dir())
+	config.SetDir(dir.Path())
+
+	testCases := []struct {
+		name          string
+		args          []string
+		notaryFunc    test.NotaryClientFuncType
+		expectedError string
+	}{
+		{
+			name:          "tagged",
+			args:          []string{"signed-repo:latest"},
+			notaryFunc:    notaryfake.GetLoadedNotaryRepository,
+			expectedError: "invalid repository signed-repo:latest: the trust data of all the signed tags is exported",
+		},
+		{
+			name:          "offline",
+			args:          []string{"signed-repo"},
+			notaryFunc:    notaryfake.GetOfflineNotaryRepository,
+			expectedError: "client is offline",
+		},
+		{
+			name:          "not-cached",
+			args:          []string{"signed-repo"},
+			notaryFunc:    notaryfake.GetLoadedNotaryRepository,
+			expectedError: "no cached trust data for docker.io/library/signed-repo",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cli := test.NewFakeCli(&fakeClient{})
+			cli.SetNotaryClient(tc.notaryFunc)
+			cmd := newExportCommand(cli)
+			cmd.SetArgs(tc.args)
+			cmd.SetOutput(ioutil.Discard)
+			assert.ErrorContains(t, cmd.Execute(), tc.expectedError)
+		})
+	}
+}
+
+// writeCachedMetadataFiles writes metadata in the cache of the notary client
+func writeCachedMetadataFiles(t *testing.T, gun string, metadata map[data.RoleName][]byte) {
+	t.Helper()
+	dir := filepath.Join(trust.GetTrustDirectory(), "tuf", filepath.FromSlash(gun), "metadata")
+	for role, content := range metadata {
+		path := filepath.Join(dir, filepath.FromSlash(role.String())+".json")
+		assert.NilError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NilError(t, ioutil.WriteFile(path, content, 0644))
+	}
+}
@@ -4,10 +4,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
+	"time"
 
 	"github.com/docker/cli/cli"
 	"github.com/docker/cli/cli/command"
 	"github.com/docker/cli/cli/command/inspect"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/theupdateframework/notary/tuf/data"
 )
@@ -16,15 +18,24 @@ type inspectOptions struct {
 	remotes []string
 	// FIXME(n4ss): this is consistent with `docker service inspect` but we should provide
 	// a `--format` flag too. (format and pretty-print should be exclusive)
-	prettyPrint bool
+	prettyPrint   bool
+	fromFile      string
+	report        bool
+	expiryWarning time.Duration
 }
 
 func newInspectCommand(dockerCli command.Cli) *cobra.Command {
 	options := inspectOptions{}
 	cmd := &cobra.Command{
-		Use:   "inspect IMAGE[:TAG] [IMAGE[:TAG]...]",
+		Use:   "inspect [OPTIONS] IMAGE[:TAG] [IMAGE[:TAG]...]",
 		Short: "Return low-level information about keys and signatures",
-		Args:  cli.RequiresMinArgs(1),
+		Args: func(cmd *cobra.Command, args []string) error {
+			// the images may all be listed in a file
+			if options.fromFile != "" {
+				return nil
+			}
+			return cli.RequiresMinArgs(1)(cmd, args)
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			options.remotes = args
 
@@ -34,11 +45,30 @@ func newInspectCommand(dockerCli command.Cli) *cobra.Command {
 
 	flags := cmd.Flags()
 	flags.BoolVar(&options.prettyPrint, "pretty", false, "Print the information in a human friendly format")
+	flags.StringVar(&options.fromFile, "from-file", "", "Read the images to inspect from a file, one per line (\"-\" for stdin)")
+	flags.BoolVar(&options.report, "report", false, "Print a consolidated report of all the images, with warnings")
+	flags.DurationVar(&options.expiryWarning, "expiry-warning", 30*24*time.Hour, "Warn about the keys expiring within this duration in the report")
 
 	return cmd
 }
 
 func runInspect(dockerCli command.Cli, opts inspectOptions) error {
+	if opts.fromFile != "" {
+		remotes, err := readRemotesFromFile(dockerCli, opts.fromFile)
+		if err != nil {
+			return err
+		}
+		opts.remotes = append(opts.remotes, remotes...)
+		if len(opts.remotes) == 0 {
+			return errors.Errorf("no images to inspect in %s", opts.fromFile)
+		}
+	}
+	if opts.report {
+		if opts.prettyPrint {
+			return errors.New("--pretty and --report can't be used together")
+		}
+		return runReport(dockerCli, opts)
+	}
 	if opts.prettyPrint {
 		var err error
 
@@ -64,10 +94,18 @@ func runInspect(dockerCli command.Cli, opts inspectOptions) error {
 }
 
 func getRepoTrustInfo(cli command.Cli, remote string) ([]byte, error) {
-	signatureRows, adminRolesWithSigs, delegationRoles, err := lookupTrustInfo(cli, remote)
+	repo, err := getTrustRepo(cli, remote)
 	if err != nil {
 		return []byte{}, err
 	}
+	return json.Marshal(repo)
+}
+
+func getTrustRepo(cli command.Cli, remote string) (trustRepo, error) {
+	signatureRows, adminRolesWithSigs, delegationRoles, err := lookupTrustInfo(cli, remote)
+	if err != nil {
+		return trustRepo{}, err
+	}
 	// process the signatures to include repo admin if signed by the base targets role
 	for idx, sig := range signatureRows {
 		if len(sig.Signers) == 0 {
@@ -106,10 +144,10 @@ func getRepoTrustInfo(cli command.Cli, remote string) ([]byte, error) {
 	}
 	sort.Slice(adminList, func(i, j int) bool { return adminList[i].Name > adminList[j].Name })
 
-	return json.Marshal(trustRepo{
+	return trustRepo{
 		Name:               remote,
 		SignedTags:         signatureRows,
 		Signers:            signerList,
 		AdministrativeKeys: adminList,
-	})
+	}, nil
 }
@@ -0,0 +1,200 @@
+package trust
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/trust"
+	"github.com/docker/distribution/reference"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/theupdateframework/notary/tuf/data"
+	tufutils "github.com/theupdateframework/notary/tuf/utils"
+)
+
+// trustReport is the consolidated report of the trust information of several
+// repositories
+type trustReport struct {
+	Repositories []trustReportRepo
+	Warnings     []string `json:",omitempty"`
+}
+
+// trustReportRepo is the trust information of a repository in a trustReport,
+// or the reason why it could not be looked up
+type trustReportRepo struct {
+	Name string
+	*trustRepo
+	Error string `json:",omitempty"`
+}
+
+// readRemotesFromFile returns the images listed in the named file, or in the
+// standard input if name is "-". Empty lines and comments starting with "#"
+// are ignored.
+func readRemotesFromFile(dockerCli command.Cli, name string) ([]string, error) {
+	var in io.Reader = dockerCli.In()
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		in = f
+	}
+	var remotes []string
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		remotes = append(remotes, line)
+	}
+	return remotes, scanner.Err()
+}
+
+func runReport(dockerCli command.Cli, opts inspectOptions) error {
+	report := trustReport{Repositories: []trustReportRepo{}}
+	now := time.Now()
+	var failed int
+	for _, remote := range opts.remotes {
+		repo, err := getTrustRepo(dockerCli, remote)
+		if err != nil {
+			failed++
+			report.Repositories = append(report.Repositories, trustReportRepo{
+				Name:  remote,
+				Error: err.Error(),
+			})
+			continue
+		}
+		setKeyExpiries(repo, getCachedKeyExpiries(remote))
+		report.Warnings = append(report.Warnings, getTrustWarnings(repo, now, opts.expiryWarning)...)
+		report.Repositories = append(report.Repositories, trustReportRepo{Name: remote, trustRepo: &repo})
+	}
+
+	out, err := json.MarshalIndent(report, "", "    ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(dockerCli.Out(), string(out))
+	for _, warning := range report.Warnings {
+		fmt.Fprintln(dockerCli.Err(), "WARNING:", warning)
+	}
+	if failed > 0 {
+		return errors.Errorf("failed to look up the trust information of %d of %d repositories", failed, len(opts.remotes))
+	}
+	return nil
+}
+
+// getTrustWarnings returns the warnings about the tags of repo which are only
+// signed with the repository key, and about the keys which expire before
+// now+expiryWarning.
+func getTrustWarnings(repo trustRepo, now time.Time, expiryWarning time.Duration) []string {
+	var warnings []string
+	var adminOnly int
+	for _, tag := range repo.SignedTags {
+		if len(tag.Signers) == 1 && tag.Signers[0] == releasedRoleName {
+			adminOnly++
+		}
+	}
+	if adminOnly > 0 {
+		warnings = append(warnings, fmt.Sprintf("%s: %d of %d signed tags are only signed with the repository key", repo.Name, adminOnly, len(repo.SignedTags)))
+	}
+
+	for _, signers := range [][]trustSigner{repo.Signers, repo.AdministrativeKeys} {
+		for _, signer := range signers {
+			for _, key := range signer.Keys {
+				switch {
+				case key.Expiry == nil:
+				case key.Expiry.Before(now):
+					warnings = append(warnings, fmt.Sprintf("%s: key %s of %s expired on %s", repo.Name, key.ID, signer.Name, key.Expiry.Format(time.RFC3339)))
+				case key.Expiry.Before(now.Add(expiryWarning)):
+					warnings = append(warnings, fmt.Sprintf("%s: key %s of %s expires on %s", repo.Name, key.ID, signer.Name, key.Expiry.Format(time.RFC3339)))
+				}
+			}
+		}
+	}
+	return warnings
+}
+
+func setKeyExpiries(repo trustRepo, expiries map[string]time.Time) {
+	for _, signers := range [][]trustSigner{repo.Signers, repo.AdministrativeKeys} {
+		for _, signer := range signers {
+			for i, key := range signer.Keys {
+				if expiry, ok := expiries[key.ID]; ok {
+					signer.Keys[i].Expiry = &expiry
+				}
+			}
+		}
+	}
+}
+
+// cachedMetadata holds the keys of the root and targets metadata of a
+// repository
+type cachedMetadata struct {
+	Signed struct {
+		Keys        data.Keys `json:"keys"`
+		Delegations struct {
+			Keys data.Keys `json:"keys"`
+		} `json:"delegations"`
+	} `json:"signed"`
+}
+
+// getCachedKeyExpiries returns the expiry date of the certificates of the keys
+// of the repository, indexed by key ID. The keys are read from the metadata
+// of the repository cached in the trust directory by the notary client, the
+// notary client doesn't give access to them.
+func getCachedKeyExpiries(remote string) map[string]time.Time {
+	expiries := make(map[string]time.Time)
+	ref, err := reference.ParseNormalizedNamed(remote)
+	if err != nil {
+		return expiries
+	}
+	dir := filepath.Join(trust.GetTrustDirectory(), "tuf", filepath.FromSlash(ref.Name()), "metadata")
+	for _, role := range []data.RoleName{data.CanonicalRootRole, data.CanonicalTargetsRole} {
+		content, err := ioutil.ReadFile(filepath.Join(dir, role.String()+".json"))
+		if err != nil {
+			logrus.Debugf("no cached %s metadata for %s: %v", role, remote, err)
+			continue
+		}
+		var meta cachedMetadata
+		if err := json.Unmarshal(content, &meta); err != nil {
+			logrus.Debugf("invalid cached %s metadata for %s: %v", role, remote, err)
+			continue
+		}
+		for _, keys := range []data.Keys{meta.Signed.Keys, meta.Signed.Delegations.Keys} {
+			for keyID, key := range keys {
+				addKeyExpiry(expiries, keyID, key)
+			}
+		}
+	}
+	return expiries
+}
+
+// addKeyExpiry adds the expiry date of key, if it is a certificate, under
+// its ID and under its canonical ID, which is the ID of delegation keys.
+func addKeyExpiry(expiries map[string]time.Time, keyID string, key data.PublicKey) {
+	if key == nil {
+		return
+	}
+	switch key.Algorithm() {
+	case data.ECDSAx509Key, data.RSAx509Key:
+	default:
+		return
+	}
+	cert, err := tufutils.LoadCertFromPEM(key.Public())
+	if err != nil {
+		return
+	}
+	expiries[keyID] = cert.NotAfter
+	if canonicalID, err := tufutils.CanonicalKeyID(key); err == nil {
+		expiries[canonicalID] = cert.NotAfter
+	}
+}
@@ -0,0 +1,154 @@
+package trust
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/cli/cli/config"
+	"github.com/docker/cli/cli/streams"
+	"github.com/docker/cli/cli/trust"
+	"github.com/docker/cli/internal/test"
+	"github.com/docker/cli/internal/test/notary"
+	"github.com/theupdateframework/notary/cryptoservice"
+	"github.com/theupdateframework/notary/tuf/data"
+	tufutils "github.com/theupdateframework/notary/tuf/utils"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+	"gotest.tools/fs"
+	"gotest.tools/golden"
+)
+
+func TestTrustInspectReport(t *testing.T) {
+	dir := fs.NewDir(t, t.Name())
+	defer dir.Remove()
+	defer config.SetDir(config.Dir())
+	config.SetDir(dir.Path())
+
+	now := time.Now().UTC().Truncate(time.Second)
+	rootExpiry := now.Add(10 * 24 * time.Hour)
+	aliceExpiry := now.Add(-24 * time.Hour)
+	writeCachedMetadata(t, "docker.io/library/signed-repo", data.CanonicalRootRole, map[string]interface{}{
+		"keys": map[string]data.PublicKey{"rootID": newCertKey(t, rootExpiry)},
+	})
+	writeCachedMetadata(t, "docker.io/library/signed-repo", data.CanonicalTargetsRole, map[string]interface{}{
+		"delegations": map[string]interface{}{
+			"keys": map[string]data.PublicKey{
+				"A": newCertKey(t, aliceExpiry),
+				"B": newCertKey(t, now.Add(365*24*time.Hour)),
+			},
+		},
+	})
+
+	cli := test.NewFakeCli(&fakeClient{})
+	cli.SetNotaryClient(notary.GetLoadedNotaryRepository)
+	cli.SetIn(streams.NewIn(ioutil.NopCloser(strings.NewReader("# repositories to audit\nsigned-repo\n\n"))))
+	cmd := newInspectCommand(cli)
+	cmd.SetArgs([]string{"--report", "--from-file", "-"})
+	assert.NilError(t, cmd.Execute())
+
+	var report struct {
+		Repositories []struct {
+			trustRepo
+			Error string
+		}
+		Warnings []string
+	}
+	assert.NilError(t, json.Unmarshal(cli.OutBuffer().Bytes(), &report))
+	assert.Assert(t, is.Len(report.Repositories, 1))
+	repo := report.Repositories[0]
+	assert.Check(t, is.Equal("signed-repo", repo.Name))
+	assert.Check(t, is.Equal("", repo.Error))
+	assert.Check(t, is.Len(repo.SignedTags, 3))
+	for _, signer := range append(repo.Signers, repo.AdministrativeKeys...) {
+		for _, key := range signer.Keys {
+			switch key.ID {
+			case "rootID":
+				assert.Check(t, is.DeepEqual(&rootExpiry, key.Expiry))
+			case "A":
+				assert.Check(t, is.DeepEqual(&aliceExpiry, key.Expiry))
+			case "targetsID":
+				assert.Check(t, is.Nil(key.Expiry))
+			}
+		}
+	}
+
+	expectedWarnings := []string{
+		"signed-repo: 1 of 3 signed tags are only signed with the repository key",
+		"signed-repo: key A of alice expired on " + aliceExpiry.Format(time.RFC3339),
+		"signed-repo: key rootID of Root expires on " + rootExpiry.Format(time.RFC3339),
+	}
+	assert.Check(t, is.DeepEqual(expectedWarnings, report.Warnings))
+	for _, warning := range expectedWarnings {
+		assert.Check(t, is.Contains(cli.ErrBuffer().String(), "WARNING: "+warning+"\n"))
+	}
+}
+
+func TestTrustInspectReportErrors(t *testing.T) {
+	cli := test.NewFakeCli(&fakeClient{})
+	cli.SetNotaryClient(notary.GetOfflineNotaryRepository)
+	cmd := newInspectCommand(cli)
+	cmd.SetArgs([]string{"--report", "nonexistent-reg-name.io/image", "nonexistent-reg-name.io/image:tag"})
+	cmd.SetOutput(ioutil.Discard)
+	assert.ErrorContains(t, cmd.Execute(), "failed to look up the trust information of 2 of 2 repositories")
+	golden.Assert(t, cli.OutBuffer().String(), "trust-inspect-report-offline.golden")
+}
+
+func TestTrustInspectFromFileErrors(t *testing.T) {
+	testCases := []struct {
+		name          string
+		args          []string
+		stdin         string
+		expectedError string
+	}{
+		{
+			name:          "pretty-report",
+			args:          []string{"--pretty", "--report", "signed-repo"},
+			expectedError: "--pretty and --report can't be used together",
+		},
+		{
+			name:          "empty-file",
+			args:          []string{"--from-file", "-"},
+			stdin:         "# nothing to inspect\n",
+			expectedError: "no images to inspect in -",
+		},
+		{
+			name:          "missing-file",
+			args:          []string{"--from-file", "/nonexistent/repositories"},
+			expectedError: "/nonexistent/repositories",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cli := test.NewFakeCli(&fakeClient{})
+			cli.SetNotaryClient(notary.GetLoadedNotaryRepository)
+			cli.SetIn(streams.NewIn(ioutil.NopCloser(strings.NewReader(tc.stdin))))
+			cmd := newInspectCommand(cli)
+			cmd.SetArgs(tc.args)
+			cmd.SetOutput(ioutil.Discard)
+			assert.ErrorContains(t, cmd.Execute(), tc.expectedError)
+		})
+	}
+}
+
+func newCertKey(t *testing.T, expiry time.Time) data.PublicKey {
+	t.Helper()
+	privKey, err := tufutils.GenerateKey(data.ECDSAKey)
+	assert.NilError(t, err)
+	cert, err := cryptoservice.GenerateCertificate(privKey, "docker.io/library/signed-repo", expiry.Add(-365*24*time.Hour), expiry)
+	assert.NilError(t, err)
+	return tufutils.CertToKey(cert)
+}
+
+func writeCachedMetadata(t *testing.T, gun string, role data.RoleName, signed map[string]interface{}) {
+	t.Helper()
+	dir := filepath.Join(trust.GetTrustDirectory(), "tuf", filepath.FromSlash(gun), "metadata")
+	assert.NilError(t, os.MkdirAll(dir, 0755))
+	content, err := json.Marshal(map[string]interface{}{"signed": signed})
+	assert.NilError(t, err)
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(dir, role.String()+".json"), content, 0644))
+}
@@ -11,8 +11,12 @@ import (
 	"github.com/docker/cli/cli"
 	"github.com/docker/cli/cli/command"
 	"github.com/docker/cli/cli/command/image"
+	registryclient "github.com/docker/cli/cli/registry/client"
 	"github.com/docker/cli/cli/trust"
+	"github.com/docker/distribution/manifest/manifestlist"
+	digest "github.com/opencontainers/go-digest"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/theupdateframework/notary/client"
 	"github.com/theupdateframework/notary/tuf/data"
@@ -59,12 +63,16 @@ func runSignImage(cli command.Cli, options signOptions) error {
 	}
 	defer clearChangeList(notaryRepo)
 
+	// manifest lists are not stored locally, they are signed from the registry
+	var listTarget *client.Target
+
 	// get the latest repository metadata so we can figure out which roles to sign
 	if _, err = notaryRepo.ListTargets(); err != nil {
 		switch err.(type) {
 		case client.ErrRepoNotInitialized, client.ErrRepositoryNotExist:
-			// before initializing a new repo, check that the image exists locally:
-			if err := checkLocalImageExistence(ctx, cli, imageName); err != nil {
+			// before initializing a new repo, check that the image exists locally,
+			// or is a manifest list in the registry:
+			if listTarget, err = lookupManifestList(ctx, cli, imgRefAndAuth); err != nil {
 				return err
 			}
 
@@ -86,8 +94,14 @@ func runSignImage(cli command.Cli, options signOptions) error {
 		// If the error is nil then the local flag is set
 		case client.ErrNoSuchTarget, client.ErrRepositoryNotExist, nil:
 			// Fail fast if the image doesn't exist locally
-			if err := checkLocalImageExistence(ctx, cli, imageName); err != nil {
-				return err
+			if listTarget == nil {
+				if listTarget, err = lookupManifestList(ctx, cli, imgRefAndAuth); err != nil {
+					return err
+				}
+			}
+			if listTarget != nil {
+				fmt.Fprintf(cli.Err(), "Signing and pushing trust data for manifest list %s, may overwrite remote trust data\n", imageName)
+				return image.PushTrustedTarget(cli, notaryRepo, imgRefAndAuth.RepoInfo().Name.Name(), imgRefAndAuth.Tag(), listTarget)
 			}
 			fmt.Fprintf(cli.Err(), "Signing and pushing trust data for local image %s, may overwrite remote trust data\n", imageName)
 			return image.TrustedPush(ctx, cli, imgRefAndAuth.RepoInfo(), imgRefAndAuth.Reference(), *imgRefAndAuth.AuthConfig(), requestPrivilege)
@@ -133,6 +147,36 @@ func checkLocalImageExistence(ctx context.Context, cli command.Cli, imageName st
 	return err
 }
 
+// lookupManifestList checks that the image exists locally. Otherwise, it
+// looks for a manifest list with the same name in the registry, and returns a
+// target to sign it.
+func lookupManifestList(ctx context.Context, cli command.Cli, imgRefAndAuth trust.ImageRefAndAuth) (*client.Target, error) {
+	localErr := checkLocalImageExistence(ctx, cli, imgRefAndAuth.Name())
+	if localErr == nil {
+		return nil, nil
+	}
+	insecure := !imgRefAndAuth.RepoInfo().Index.Secure
+	rclient, err := registryclient.AsContentClient(cli.RegistryClient(insecure))
+	if err != nil {
+		logrus.Debugf("failed to look up manifest list for %s: %s", imgRefAndAuth.Name(), err)
+		return nil, localErr
+	}
+	manifest, err := rclient.GetRawManifest(ctx, imgRefAndAuth.Reference())
+	if err != nil {
+		logrus.Debugf("failed to fetch manifest list for %s: %s", imgRefAndAuth.Name(), err)
+		return nil, localErr
+	}
+	list, ok := manifest.(*manifestlist.DeserializedManifestList)
+	if !ok {
+		return nil, localErr
+	}
+	_, payload, err := list.Payload()
+	if err != nil {
+		return nil, err
+	}
+	return image.NewTrustedTarget(imgRefAndAuth.Tag(), digest.FromBytes(payload), int64(len(payload)))
+}
+
 func createTarget(notaryRepo client.Repository, tag string) (client.Target, error) {
 	target := &client.Target{}
 	var err error
@@ -2,6 +2,8 @@ package trust
 
 import (
 	"bytes"
+	"context"
+	"encoding/hex"
 	"encoding/json"
 	"io/ioutil"
 	"os"
@@ -9,9 +11,17 @@ import (
 	"testing"
 
 	"github.com/docker/cli/cli/config"
+	registryclient "github.com/docker/cli/cli/registry/client"
 	"github.com/docker/cli/cli/trust"
 	"github.com/docker/cli/internal/test"
 	notaryfake "github.com/docker/cli/internal/test/notary"
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/manifest/manifestlist"
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/api/types"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
 	"github.com/theupdateframework/notary"
 	"github.com/theupdateframework/notary/client"
 	"github.com/theupdateframework/notary/client/changelist"
@@ -307,3 +317,82 @@ func TestSignCommandLocalFlag(t *testing.T) {
 	assert.ErrorContains(t, cmd.Execute(), "error contacting notary server: dial tcp: lookup reg-name.io")
 
 }
+
+type fakeMissingImageClient struct {
+	fakeClient
+}
+
+func (c *fakeMissingImageClient) ImageInspectWithRaw(ctx context.Context, imageID string) (types.ImageInspect, []byte, error) {
+	return types.ImageInspect{}, nil, errors.Errorf("No such image: %s", imageID)
+}
+
+type fakeRegistryClient struct {
+	registryclient.ContentClient
+	manifest distribution.Manifest
+}
+
+func (c *fakeRegistryClient) GetRawManifest(ctx context.Context, ref reference.Named) (distribution.Manifest, error) {
+	if c.manifest == nil {
+		return nil, errors.Errorf("manifest unknown: %s", ref)
+	}
+	return c.manifest, nil
+}
+
+func TestSignCommandManifestList(t *testing.T) {
+	list, err := manifestlist.FromDescriptors([]manifestlist.ManifestDescriptor{
+		{
+			Descriptor: distribution.Descriptor{MediaType: schema2.MediaTypeManifest, Digest: digest.FromString("amd64"), Size: 42},
+			Platform:   manifestlist.PlatformSpec{OS: "linux", Architecture: "amd64"},
+		},
+	})
+	assert.NilError(t, err)
+	_, payload, err := list.Payload()
+	assert.NilError(t, err)
+
+	var targets []client.Target
+	cli := test.NewFakeCli(&fakeMissingImageClient{})
+	cli.SetRegistryClient(&fakeRegistryClient{manifest: list})
+	cli.SetNotaryClient(func(imgRefAndAuth trust.ImageRefAndAuth, actions []string) (client.Repository, error) {
+		return notaryfake.NewRecordingNotaryRepository(&targets), nil
+	})
+	cmd := newSignCommand(cli)
+	cmd.SetArgs([]string{"example.com/multiarch:v1"})
+	assert.NilError(t, cmd.Execute())
+
+	assert.Assert(t, is.Len(targets, 1))
+	assert.Check(t, is.Equal("v1", targets[0].Name))
+	assert.Check(t, is.Equal(int64(len(payload)), targets[0].Length))
+	assert.Check(t, is.Equal(digest.FromBytes(payload).Hex(), hex.EncodeToString(targets[0].Hashes[notary.SHA256])))
+	assert.Check(t, is.Contains(cli.OutBuffer().String(), "Successfully signed example.com/multiarch:v1"))
+}
+
+func TestSignCommandManifestListInsecureRegistry(t *testing.T) {
+	list, err := manifestlist.FromDescriptors(nil)
+	assert.NilError(t, err)
+
+	var insecureRegistries []bool
+	cli := test.NewFakeCli(&fakeMissingImageClient{})
+	cli.SetRegistryClientFunc(func(insecure bool) registryclient.RegistryClient {
+		insecureRegistries = append(insecureRegistries, insecure)
+		return &fakeRegistryClient{manifest: list}
+	})
+	cli.SetNotaryClient(func(imgRefAndAuth trust.ImageRefAndAuth, actions []string) (client.Repository, error) {
+		return notaryfake.NewRecordingNotaryRepository(&[]client.Target{}), nil
+	})
+	for _, ref := range []string{"example.com/multiarch:v1", "127.0.0.1:5000/multiarch:v1"} {
+		cmd := newSignCommand(cli)
+		cmd.SetArgs([]string{ref})
+		assert.NilError(t, cmd.Execute())
+	}
+	assert.Check(t, is.DeepEqual([]bool{false, true}, insecureRegistries))
+}
+
+func TestSignCommandMissingImage(t *testing.T) {
+	cli := test.NewFakeCli(&fakeMissingImageClient{})
+	cli.SetRegistryClient(&fakeRegistryClient{})
+	cli.SetNotaryClient(notaryfake.GetEmptyTargetsNotaryRepository)
+	cmd := newSignCommand(cli)
+	cmd.SetArgs([]string{"example.com/image:v1"})
+	cmd.SetOutput(ioutil.Discard)
+	assert.ErrorContains(t, cmd.Execute(), "No such image: example.com/image:v1")
+}
@@ -17,6 +17,7 @@ func newTrustSignerCommand(dockerCli command.Cli) *cobra.Command {
 	cmd.AddCommand(
 		newSignerAddCommand(dockerCli),
 		newSignerRemoveCommand(dockerCli),
+		newSignerRotateCommand(dockerCli),
 	)
 	return cmd
 }
@@ -0,0 +1,200 @@
+package trust
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/command/image"
+	"github.com/docker/cli/cli/trust"
+	"github.com/docker/cli/opts"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/theupdateframework/notary/client"
+	"github.com/theupdateframework/notary/tuf/data"
+)
+
+type signerRotateOptions struct {
+	keys     opts.ListOpts
+	signer   string
+	repos    []string
+	fromFile string
+	resign   bool
+}
+
+func newSignerRotateCommand(dockerCli command.Cli) *cobra.Command {
+	var options signerRotateOptions
+	cmd := &cobra.Command{
+		Use:   "rotate [OPTIONS] NAME [REPOSITORY...]",
+		Short: "Rotate the key of a signer",
+		Long:  "Rotate the key of a signer in the repositories given as arguments or read from a file. The repositories where the signer is present are not discovered: they must all be listed.",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if options.fromFile != "" {
+				return cli.RequiresMinArgs(1)(cmd, args)
+			}
+			return cli.RequiresMinArgs(2)(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			options.signer = args[0]
+			options.repos = args[1:]
+			return rotateSigner(dockerCli, options)
+		},
+	}
+	flags := cmd.Flags()
+	options.keys = opts.NewListOpts(nil)
+	flags.Var(&options.keys, "key", "Path to the signer's new public key file")
+	flags.StringVar(&options.fromFile, "from-file", "", "Read the repositories from a file, one per line (\"-\" for stdin)")
+	flags.BoolVar(&options.resign, "resign", true, "Re-sign the tags currently signed by the signer with the new key, the old keys are only removed if they are re-signed unless set to false")
+	return cmd
+}
+
+func rotateSigner(cli command.Cli, options signerRotateOptions) error {
+	signerName := options.signer
+	if !validSignerName(signerName) {
+		return fmt.Errorf("signer name \"%s\" must start with lowercase alphanumeric characters and can include \"-\" or \"_\" after the first character", signerName)
+	}
+	if signerName == "releases" {
+		return fmt.Errorf("releases is a reserved keyword, please use a different signer name")
+	}
+
+	if options.keys.Len() == 0 {
+		return fmt.Errorf("path to a public key must be provided using the `--key` flag")
+	}
+	signerPubKeys, err := ingestPublicKeys(options.keys.GetAll())
+	if err != nil {
+		return err
+	}
+
+	repos := options.repos
+	if options.fromFile != "" {
+		fileRepos, err := readRemotesFromFile(cli, options.fromFile)
+		if err != nil {
+			return err
+		}
+		repos = append(repos, fileRepos...)
+	}
+	if len(repos) == 0 {
+		return fmt.Errorf("no repositories to rotate the key of signer %s in", signerName)
+	}
+
+	var errRepos []string
+	for _, repoName := range repos {
+		fmt.Fprintf(cli.Out(), "Rotating the key of signer \"%s\" in %s...\n", signerName, repoName)
+		rotated, err := rotateSignerInRepo(cli, signerName, repoName, signerPubKeys, options.resign)
+		switch {
+		case err != nil:
+			fmt.Fprintln(cli.Err(), err.Error()+"\n")
+			errRepos = append(errRepos, repoName)
+		case !rotated:
+			fmt.Fprintf(cli.Out(), "No signer %s for repository %s, skipping\n\n", signerName, repoName)
+		default:
+			fmt.Fprintf(cli.Out(), "Successfully rotated the key of signer %s in %s\n\n", signerName, repoName)
+		}
+	}
+	if len(errRepos) > 0 {
+		return fmt.Errorf("Failed to rotate the key of signer in: %s", strings.Join(errRepos, ", "))
+	}
+	return nil
+}
+
+// rotateSignerInRepo replaces the keys of the signer in the named repository
+// by signerPubKeys, and returns whether the signer is present in the
+// repository. The tags signed by the signer are re-signed with the new keys
+// if resign is set, and nothing is published if they can't be. Otherwise
+// they remain signed with the old keys, which no longer validate.
+func rotateSignerInRepo(cli command.Cli, signerName, repoName string, signerPubKeys []data.PublicKey, resign bool) (bool, error) {
+	ctx := context.Background()
+	imgRefAndAuth, err := trust.GetImageReferencesAndAuth(ctx, nil, image.AuthResolver(cli), repoName)
+	if err != nil {
+		return false, err
+	}
+
+	notaryRepo, err := cli.NotaryClient(imgRefAndAuth, trust.ActionsPushAndPull)
+	if err != nil {
+		return false, trust.NotaryError(imgRefAndAuth.Reference().Name(), err)
+	}
+	delegationRoles, err := notaryRepo.GetDelegationRoles()
+	if err != nil {
+		return false, errors.Wrapf(err, "error retrieving signers for %s", repoName)
+	}
+	signerDelegation := data.RoleName("targets/" + signerName)
+	var role data.Role
+	for _, delRole := range delegationRoles {
+		if delRole.Name == signerDelegation {
+			role = delRole
+			break
+		}
+	}
+	if role.Name == "" {
+		return false, nil
+	}
+
+	var targets []*client.TargetWithRole
+	if resign {
+		if targets, err = notaryRepo.ListTargets(signerDelegation); err != nil {
+			return false, trust.NotaryError(repoName, err)
+		}
+	}
+
+	if err := publishSignerRotation(cli, notaryRepo, signerName, repoName, role, signerPubKeys, targets); err != nil {
+		// discard the staged changes, so that a later publication doesn't
+		// remove the old keys without re-signing the tags
+		if clearErr := clearChangeList(notaryRepo); clearErr != nil {
+			return false, errors.Wrapf(err, "failed to clear the staged changes (%v)", clearErr)
+		}
+		return false, err
+	}
+	if !resign {
+		fmt.Fprintf(cli.Err(), "WARNING: The tags of %s signed by %s no longer validate, they must be re-signed with the new key\n", repoName, signerName)
+	}
+	return true, nil
+}
+
+// publishSignerRotation stages the new keys of the signer, the removal of its
+// old keys and the re-signing of targets, then publishes them all at once.
+func publishSignerRotation(cli command.Cli, notaryRepo client.Repository, signerName, repoName string, role data.Role, signerPubKeys []data.PublicKey, targets []*client.TargetWithRole) error {
+	if err := addStagedSigner(notaryRepo, role.Name, signerPubKeys); err != nil {
+		return errors.Wrapf(err, "could not add the new key of signer %s to %s", signerName, repoName)
+	}
+	oldKeyIDs := oldSignerKeyIDs(role.KeyIDs, signerPubKeys)
+	if len(oldKeyIDs) > 0 {
+		if err := notaryRepo.RemoveDelegationKeys(role.Name, oldKeyIDs); err != nil {
+			return errors.Wrapf(err, "could not remove the old key of signer %s from %s", signerName, repoName)
+		}
+		if err := notaryRepo.RemoveDelegationKeys(releasesRoleTUFName, oldKeyIDs); err != nil {
+			return errors.Wrapf(err, "could not remove the old key of signer %s from %s", signerName, repoName)
+		}
+	}
+
+	for _, target := range targets {
+		fmt.Fprintf(cli.Out(), "Re-signing %s:%s\n", repoName, target.Name)
+		if err := notaryRepo.AddTarget(&target.Target, role.Name, releasesRoleTUFName); err != nil {
+			return errors.Wrapf(err, "failed to re-sign %s:%s", repoName, target.Name)
+		}
+	}
+	if err := notaryRepo.Publish(); err != nil {
+		return trust.NotaryError(repoName, err)
+	}
+	return nil
+}
+
+// oldSignerKeyIDs returns the IDs of keyIDs which are not the IDs of one of
+// the new keys.
+func oldSignerKeyIDs(keyIDs []string, newKeys []data.PublicKey) []string {
+	var oldKeyIDs []string
+	for _, keyID := range keyIDs {
+		isNew := false
+		for _, key := range newKeys {
+			if key.ID() == keyID {
+				isNew = true
+				break
+			}
+		}
+		if !isNew {
+			oldKeyIDs = append(oldKeyIDs, keyID)
+		}
+	}
+	return oldKeyIDs
+}
@@ -0,0 +1,219 @@
+package trust
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/docker/cli/cli/config"
+	"github.com/docker/cli/cli/streams"
+	"github.com/docker/cli/cli/trust"
+	"github.com/docker/cli/internal/test"
+	notaryfake "github.com/docker/cli/internal/test/notary"
+	"github.com/pkg/errors"
+	"github.com/theupdateframework/notary"
+	"github.com/theupdateframework/notary/client"
+	"github.com/theupdateframework/notary/client/changelist"
+	"github.com/theupdateframework/notary/tuf/data"
+	tufutils "github.com/theupdateframework/notary/tuf/utils"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+	"gotest.tools/fs"
+)
+
+// rotateNotaryRepository is a LoadedNotaryRepository recording the changes
+// staged by `trust signer rotate`
+type rotateNotaryRepository struct {
+	notaryfake.LoadedNotaryRepository
+	changes    *[]string
+	publishErr error
+}
+
+func (r rotateNotaryRepository) AddDelegationRoleAndKeys(name data.RoleName, delegationKeys []data.PublicKey) error {
+	*r.changes = append(*r.changes, "add keys to "+name.String())
+	return nil
+}
+
+func (r rotateNotaryRepository) RemoveDelegationKeys(name data.RoleName, keyIDs []string) error {
+	*r.changes = append(*r.changes, "remove keys "+strings.Join(keyIDs, ",")+" from "+name.String())
+	return nil
+}
+
+func (r rotateNotaryRepository) AddTarget(target *client.Target, roles ...data.RoleName) error {
+	*r.changes = append(*r.changes, "sign "+target.Name)
+	return nil
+}
+
+func (r rotateNotaryRepository) Publish() error {
+	if r.publishErr != nil {
+		return r.publishErr
+	}
+	*r.changes = append(*r.changes, "publish")
+	return nil
+}
+
+func (r rotateNotaryRepository) GetChangelist() (changelist.Changelist, error) {
+	return recordingChangelist{Changelist: changelist.NewMemChangelist(), changes: r.changes}, nil
+}
+
+// recordingChangelist is a changelist recording when it is cleared
+type recordingChangelist struct {
+	changelist.Changelist
+	changes *[]string
+}
+
+func (c recordingChangelist) Clear(archive string) error {
+	*c.changes = append(*c.changes, "clear")
+	return c.Changelist.Clear(archive)
+}
+
+func newRotateNotaryRepository(changes *[]string) test.NotaryClientFuncType {
+	return func(imgRefAndAuth trust.ImageRefAndAuth, actions []string) (client.Repository, error) {
+		if imgRefAndAuth.Name() == "empty-repo" {
+			return notaryfake.EmptyTargetsNotaryRepository{}, nil
+		}
+		return rotateNotaryRepository{changes: changes}, nil
+	}
+}
+
+func TestTrustSignerRotateErrors(t *testing.T) {
+	testCases := []struct {
+		name          string
+		args          []string
+		expectedError string
+	}{
+		{
+			name:          "not-enough-args",
+			args:          []string{"alice"},
+			expectedError: "requires at least 2 arguments",
+		},
+		{
+			name:          "no-key",
+			args:          []string{"alice", "my-image"},
+			expectedError: "path to a public key must be provided using the `--key` flag",
+		},
+		{
+			name:          "reserved-releases-signer",
+			args:          []string{"releases", "my-image", "--key", "/path/to/key"},
+			expectedError: "releases is a reserved keyword, please use a different signer name",
+		},
+		{
+			name:          "disallowed-chars",
+			args:          []string{"ali/ce", "my-image", "--key", "/path/to/key"},
+			expectedError: "signer name \"ali/ce\" must start with lowercase alphanumeric characters",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cli := test.NewFakeCli(&fakeClient{})
+			cli.SetNotaryClient(notaryfake.GetOfflineNotaryRepository)
+			cmd := newSignerRotateCommand(cli)
+			cmd.SetArgs(tc.args)
+			cmd.SetOutput(ioutil.Discard)
+			assert.ErrorContains(t, cmd.Execute(), tc.expectedError)
+		})
+	}
+}
+
+func TestTrustSignerRotate(t *testing.T) {
+	dir := fs.NewDir(t, t.Name(), fs.WithFile("pubkey.pem", string(pubKeyFixture), fs.WithMode(notary.PrivNoExecPerms)))
+	defer dir.Remove()
+	defer config.SetDir(config.Dir())
+	config.SetDir(dir.Path())
+
+	var changes []string
+	cli := test.NewFakeCli(&fakeClient{})
+	cli.SetNotaryClient(newRotateNotaryRepository(&changes))
+	cmd := newSignerRotateCommand(cli)
+	cmd.SetArgs([]string{"--key", dir.Join("pubkey.pem"), "alice", "signed-repo", "empty-repo"})
+	cmd.SetOutput(ioutil.Discard)
+	assert.NilError(t, cmd.Execute())
+
+	expectedChanges := []string{
+		"add keys to targets/alice",
+		"add keys to targets/releases",
+		"remove keys A from targets/alice",
+		"remove keys A from targets/releases",
+		"sign red",
+		"sign blue",
+		"publish",
+	}
+	assert.Check(t, is.DeepEqual(expectedChanges, changes))
+	assert.Check(t, is.Contains(cli.OutBuffer().String(), "Successfully rotated the key of signer alice in signed-repo\n"))
+	assert.Check(t, is.Contains(cli.OutBuffer().String(), "No signer alice for repository empty-repo, skipping\n"))
+}
+
+func TestTrustSignerRotateWithoutResign(t *testing.T) {
+	dir := fs.NewDir(t, t.Name(), fs.WithFile("pubkey.pem", string(pubKeyFixture), fs.WithMode(notary.PrivNoExecPerms)))
+	defer dir.Remove()
+	defer config.SetDir(config.Dir())
+	config.SetDir(dir.Path())
+
+	var changes []string
+	cli := test.NewFakeCli(&fakeClient{})
+	cli.SetNotaryClient(newRotateNotaryRepository(&changes))
+	cli.SetIn(streams.NewIn(ioutil.NopCloser(strings.NewReader("# repositories\nsigned-repo\n"))))
+	cmd := newSignerRotateCommand(cli)
+	cmd.SetArgs([]string{"--key", dir.Join("pubkey.pem"), "--resign=false", "--from-file", "-", "bob"})
+	cmd.SetOutput(ioutil.Discard)
+	assert.NilError(t, cmd.Execute())
+
+	expectedChanges := []string{
+		"add keys to targets/bob",
+		"add keys to targets/releases",
+		"remove keys B from targets/bob",
+		"remove keys B from targets/releases",
+		"publish",
+	}
+	assert.Check(t, is.DeepEqual(expectedChanges, changes))
+	assert.Check(t, is.Contains(cli.ErrBuffer().String(), "WARNING: The tags of signed-repo signed by bob no longer validate, they must be re-signed with the new key\n"))
+}
+
+func TestTrustSignerRotateResignFailure(t *testing.T) {
+	dir := fs.NewDir(t, t.Name(), fs.WithFile("pubkey.pem", string(pubKeyFixture), fs.WithMode(notary.PrivNoExecPerms)))
+	defer dir.Remove()
+	defer config.SetDir(config.Dir())
+	config.SetDir(dir.Path())
+
+	var changes []string
+	cli := test.NewFakeCli(&fakeClient{})
+	cli.SetNotaryClient(func(imgRefAndAuth trust.ImageRefAndAuth, actions []string) (client.Repository, error) {
+		return rotateNotaryRepository{changes: &changes, publishErr: errors.New("no signing key for targets/alice")}, nil
+	})
+	cmd := newSignerRotateCommand(cli)
+	cmd.SetArgs([]string{"--key", dir.Join("pubkey.pem"), "alice", "signed-repo"})
+	cmd.SetOutput(ioutil.Discard)
+	assert.Error(t, cmd.Execute(), "Failed to rotate the key of signer in: signed-repo")
+
+	// the removal of the old keys is discarded with the re-signed tags
+	expectedChanges := []string{
+		"add keys to targets/alice",
+		"add keys to targets/releases",
+		"remove keys A from targets/alice",
+		"remove keys A from targets/releases",
+		"sign red",
+		"sign blue",
+		"clear",
+	}
+	assert.Check(t, is.DeepEqual(expectedChanges, changes))
+	assert.Check(t, is.Contains(cli.ErrBuffer().String(), "no signing key for targets/alice"))
+}
+
+func TestTrustSignerRotateFailures(t *testing.T) {
+	dir := fs.NewDir(t, t.Name(), fs.WithFile("pubkey.pem", string(pubKeyFixture)))
+	defer dir.Remove()
+
+	cli := test.NewFakeCli(&fakeClient{})
+	cli.SetNotaryClient(notaryfake.GetOfflineNotaryRepository)
+	cmd := newSignerRotateCommand(cli)
+	cmd.SetArgs([]string{"--key", dir.Join("pubkey.pem"), "alice", "signed-repo", "other-repo"})
+	cmd.SetOutput(ioutil.Discard)
+	assert.Error(t, cmd.Execute(), "Failed to rotate the key of signer in: signed-repo, other-repo")
+}
+
+func TestOldSignerKeyIDs(t *testing.T) {
+	key, err := tufutils.ParsePEMPublicKey(pubKeyFixture)
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual([]string{"A"}, oldSignerKeyIDs([]string{"A", key.ID()}, []data.PublicKey{key})))
+	assert.Check(t, is.Len(oldSignerKeyIDs([]string{key.ID()}, []data.PublicKey{key}), 0))
+}
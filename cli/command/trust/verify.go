@@ -0,0 +1,167 @@
+package trust
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	"github.com/docker/cli/cli/trust"
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/docker/distribution/reference"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/theupdateframework/notary/trustpinning"
+	tufutils "github.com/theupdateframework/notary/tuf/utils"
+)
+
+type verifyOptions struct {
+	image        string
+	bundle       string
+	rootCert     string
+	rootKeys     []string
+	allowExpired bool
+}
+
+func newVerifyCommand(dockerCli command.Cli) *cobra.Command {
+	var options verifyOptions
+	cmd := &cobra.Command{
+		Use:   "verify [OPTIONS] IMAGE[:TAG]",
+		Short: "Verify a local image against exported trust data",
+		Args:  cli.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			options.image = args[0]
+			return runVerify(dockerCli, options)
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&options.bundle, "bundle", "", "Path of the trust data exported by \"docker trust export\"")
+	flags.StringVar(&options.rootCert, "root-cert", "", "Certificate of the root key of the repository")
+	flags.StringSliceVar(&options.rootKeys, "root-key", nil, "ID of a root key of the repository")
+	flags.BoolVar(&options.allowExpired, "allow-expired", false, "Accept expired trust data")
+	return cmd
+}
+
+func runVerify(dockerCli command.Cli, options verifyOptions) error {
+	if options.bundle == "" {
+		return errors.New("the path of the trust data must be provided using the `--bundle` flag")
+	}
+	if options.rootCert != "" && len(options.rootKeys) > 0 {
+		return errors.New("conflicting options: --root-cert and --root-key can't be used together")
+	}
+	ref, err := reference.ParseNormalizedNamed(options.image)
+	if err != nil {
+		return err
+	}
+	if _, isCanonical := ref.(reference.Canonical); isCanonical {
+		return errors.Errorf("invalid image %s: only tagged images can be verified", options.image)
+	}
+	tagged, ok := reference.TagNameOnly(ref).(reference.NamedTagged)
+	if !ok {
+		return errors.Errorf("invalid image %s: only tagged images can be verified", options.image)
+	}
+
+	bundle, err := loadBundle(options.bundle)
+	if err != nil {
+		return err
+	}
+	if bundle.GUN != tagged.Name() {
+		return errors.Errorf("trust data in %s is for %s, not %s", options.bundle, bundle.GUN, tagged.Name())
+	}
+	trustedRoot, trustPin, err := getTrustAnchor(bundle.GUN, options)
+	if err != nil {
+		return err
+	}
+	repo, err := bundle.Verify(trustedRoot, trustPin, options.allowExpired)
+	if err != nil {
+		return errors.Wrapf(err, "failed to verify the trust data in %s", options.bundle)
+	}
+	signedDigest, err := trust.SignedDigest(repo, tagged.Tag())
+	if err != nil {
+		return trust.NotaryError(tagged.Name(), err)
+	}
+
+	image, _, err := dockerCli.Client().ImageInspectWithRaw(context.Background(), reference.FamiliarString(tagged))
+	if err != nil {
+		return err
+	}
+	var verification string
+	for _, repoDigest := range image.RepoDigests {
+		if canonical, err := reference.ParseNormalizedNamed(repoDigest); err == nil {
+			if c, ok := canonical.(reference.Canonical); ok && c.Digest() == signedDigest {
+				verification = "repository digest " + repoDigest
+				break
+			}
+		}
+	}
+	if verification == "" {
+		configDigest, err := signedConfigDigest(bundle, tagged.Tag(), signedDigest)
+		if err != nil {
+			return errors.Wrapf(err, "failed to verify %s", reference.FamiliarString(tagged))
+		}
+		if image.ID != configDigest.String() {
+			return errors.Errorf("image %s doesn't match the signed digest %s", reference.FamiliarString(tagged), signedDigest)
+		}
+		verification = "image ID " + image.ID
+	}
+	fmt.Fprintf(dockerCli.Out(), "Verified %s: %s matches the signed digest %s\n", reference.FamiliarString(tagged), verification, signedDigest)
+	return nil
+}
+
+// getTrustAnchor returns what the root of the trust data of the repository is
+// verified against: the root certificate or keys pinned with the flags, or
+// else the root of the repository cached by the notary client when the
+// trust data of the repository was last fetched.
+func getTrustAnchor(gun string, options verifyOptions) ([]byte, trustpinning.TrustPinConfig, error) {
+	switch {
+	case options.rootCert != "":
+		cert, err := tufutils.LoadCertFromFile(options.rootCert)
+		if err != nil {
+			return nil, trustpinning.TrustPinConfig{}, errors.Wrapf(err, "invalid root certificate %s", options.rootCert)
+		}
+		return nil, trustpinning.TrustPinConfig{Certs: map[string][]string{gun: {tufutils.CertToKey(cert).ID()}}}, nil
+	case len(options.rootKeys) > 0:
+		return nil, trustpinning.TrustPinConfig{Certs: map[string][]string{gun: options.rootKeys}}, nil
+	}
+	trustedRoot, err := trust.GetCachedRoot(gun)
+	if err != nil {
+		return nil, trustpinning.TrustPinConfig{}, errors.Wrapf(err, "failed to read the trusted root of %s", gun)
+	}
+	if trustedRoot == nil {
+		return nil, trustpinning.TrustPinConfig{}, errors.Errorf("no trusted root for %s: specify it with --root-cert or --root-key, or fetch the trust data of the repository, e.g. with \"docker trust inspect\", to trust its root", gun)
+	}
+	return trustedRoot, trustpinning.TrustPinConfig{}, nil
+}
+
+func loadBundle(filename string) (*trust.Bundle, error) {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var bundle trust.Bundle
+	if err := json.Unmarshal(content, &bundle); err != nil {
+		return nil, errors.Wrapf(err, "invalid trust data in %s", filename)
+	}
+	return &bundle, nil
+}
+
+// signedConfigDigest returns the digest of the image configuration of the
+// exported manifest of the tag, which is the ID of the image, if the digest
+// of the manifest is the signed digest.
+func signedConfigDigest(bundle *trust.Bundle, tag string, signedDigest digest.Digest) (digest.Digest, error) {
+	payload, ok := bundle.Manifests[tag]
+	if !ok {
+		return "", errors.Errorf("the image has no repository digest and the manifest of tag %s was not exported", tag)
+	}
+	if digest.FromBytes(payload) != signedDigest {
+		return "", errors.Errorf("the exported manifest of tag %s doesn't match the signed digest %s", tag, signedDigest)
+	}
+	var manifest schema2.Manifest
+	if err := json.Unmarshal(payload, &manifest); err != nil {
+		return "", errors.Wrapf(err, "invalid manifest for tag %s", tag)
+	}
+	return manifest.Config.Digest, nil
+}
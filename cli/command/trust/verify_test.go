@@ -0,0 +1,358 @@
+package trust
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/docker/cli/cli/config"
+	"github.com/docker/cli/cli/trust"
+	"github.com/docker/cli/internal/test"
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/docker/docker/api/types"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/theupdateframework/notary/cryptoservice"
+	"github.com/theupdateframework/notary/passphrase"
+	"github.com/theupdateframework/notary/trustmanager"
+	"github.com/theupdateframework/notary/tuf"
+	"github.com/theupdateframework/notary/tuf/data"
+	tufutils "github.com/theupdateframework/notary/tuf/utils"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+	"gotest.tools/fs"
+)
+
+const verifyGUN = "docker.io/library/signed-repo"
+
+type verifyClient struct {
+	fakeClient
+	image types.ImageInspect
+}
+
+func (c *verifyClient) ImageInspectWithRaw(ctx context.Context, imageID string) (types.ImageInspect, []byte, error) {
+	return c.image, nil, nil
+}
+
+func TestTrustVerify(t *testing.T) {
+	dir := fs.NewDir(t, t.Name())
+	defer dir.Remove()
+	defer config.SetDir(config.Dir())
+	config.SetDir(dir.Path())
+
+	manifest := newTestManifest(t)
+	_, payload, err := manifest.Payload()
+	assert.NilError(t, err)
+	signedDigest := digest.FromBytes(payload)
+	bundle := trust.Bundle{
+		GUN:       verifyGUN,
+		Metadata:  newSignedMetadata(t, verifyGUN, map[string]digest.Digest{"latest": signedDigest}, time.Now().Add(time.Hour)),
+		Manifests: map[string][]byte{"latest": payload},
+	}
+	bundlePath := writeBundle(t, dir, "bundle.json", bundle)
+	writeCachedMetadataFiles(t, verifyGUN, map[data.RoleName][]byte{data.CanonicalRootRole: bundle.Metadata[data.CanonicalRootRole]})
+	imageID := manifest.Config.Digest.String()
+
+	testCases := []struct {
+		name          string
+		args          []string
+		image         types.ImageInspect
+		expectedOut   string
+		expectedError string
+	}{
+		{
+			name:        "repository-digest",
+			args:        []string{"--bundle", bundlePath, "signed-repo"},
+			image:       types.ImageInspect{ID: "sha256:other", RepoDigests: []string{"mirror.example.com/signed-repo@" + signedDigest.String()}},
+			expectedOut: "Verified signed-repo:latest: repository digest mirror.example.com/signed-repo@" + signedDigest.String() + " matches the signed digest " + signedDigest.String() + "\n",
+		},
+		{
+			name:        "image-id",
+			args:        []string{"--bundle", bundlePath, "signed-repo:latest"},
+			image:       types.ImageInspect{ID: imageID},
+			expectedOut: "Verified signed-repo:latest: image ID " + imageID + " matches the signed digest " + signedDigest.String() + "\n",
+		},
+		{
+			name:          "mismatch",
+			args:          []string{"--bundle", bundlePath, "signed-repo"},
+			image:         types.ImageInspect{ID: "sha256:other"},
+			expectedError: "image signed-repo:latest doesn't match the signed digest " + signedDigest.String(),
+		},
+		{
+			name:          "unsigned-tag",
+			args:          []string{"--bundle", bundlePath, "signed-repo:unsigned"},
+			expectedError: "no signed digest for tag unsigned",
+		},
+		{
+			name:          "other-repository",
+			args:          []string{"--bundle", bundlePath, "other-repo"},
+			expectedError: "trust data in " + bundlePath + " is for docker.io/library/signed-repo, not docker.io/library/other-repo",
+		},
+		{
+			name:          "no-bundle",
+			args:          []string{"signed-repo"},
+			expectedError: "the path of the trust data must be provided using the `--bundle` flag",
+		},
+		{
+			name:          "conflicting-anchors",
+			args:          []string{"--bundle", bundlePath, "--root-cert", "root.crt", "--root-key", "abc", "signed-repo"},
+			expectedError: "conflicting options: --root-cert and --root-key can't be used together",
+		},
+		{
+			name:          "digest-reference",
+			args:          []string{"--bundle", bundlePath, "signed-repo@" + signedDigest.String()},
+			expectedError: "only tagged images can be verified",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cli := test.NewFakeCli(&verifyClient{image: tc.image})
+			cmd := newVerifyCommand(cli)
+			cmd.SetArgs(tc.args)
+			cmd.SetOutput(ioutil.Discard)
+			err := cmd.Execute()
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Check(t, is.Equal(tc.expectedOut, cli.OutBuffer().String()))
+		})
+	}
+}
+
+func TestTrustVerifyInvalidBundle(t *testing.T) {
+	dir := fs.NewDir(t, t.Name())
+	defer dir.Remove()
+	defer config.SetDir(config.Dir())
+	config.SetDir(dir.Path())
+
+	manifest := newTestManifest(t)
+	_, payload, err := manifest.Payload()
+	assert.NilError(t, err)
+	signedDigest := digest.FromBytes(payload)
+	image := types.ImageInspect{ID: manifest.Config.Digest.String()}
+
+	expired := trust.Bundle{
+		GUN:       verifyGUN,
+		Metadata:  newSignedMetadata(t, verifyGUN, map[string]digest.Digest{"latest": signedDigest}, time.Now().Add(-time.Hour)),
+		Manifests: map[string][]byte{"latest": payload},
+	}
+	expiredPath := writeBundle(t, dir, "expired.json", expired)
+	writeCachedMetadataFiles(t, verifyGUN, map[data.RoleName][]byte{data.CanonicalRootRole: expired.Metadata[data.CanonicalRootRole]})
+
+	cli := test.NewFakeCli(&verifyClient{image: image})
+	cmd := newVerifyCommand(cli)
+	cmd.SetArgs([]string{"--bundle", expiredPath, "signed-repo"})
+	cmd.SetOutput(ioutil.Discard)
+	assert.ErrorContains(t, cmd.Execute(), "failed to verify the trust data in "+expiredPath)
+
+	cli = test.NewFakeCli(&verifyClient{image: image})
+	cmd = newVerifyCommand(cli)
+	cmd.SetArgs([]string{"--bundle", expiredPath, "--allow-expired", "signed-repo"})
+	cmd.SetOutput(ioutil.Discard)
+	assert.NilError(t, cmd.Execute())
+
+	// the manifest of another image can't be substituted
+	tampered := expired
+	tampered.Manifests = map[string][]byte{"latest": []byte(`{"config": {"digest": "sha256:other"}}`)}
+	tamperedPath := writeBundle(t, dir, "tampered.json", tampered)
+	cli = test.NewFakeCli(&verifyClient{image: types.ImageInspect{ID: "sha256:other"}})
+	cmd = newVerifyCommand(cli)
+	cmd.SetArgs([]string{"--bundle", tamperedPath, "--allow-expired", "signed-repo"})
+	cmd.SetOutput(ioutil.Discard)
+	assert.ErrorContains(t, cmd.Execute(), "the exported manifest of tag latest doesn't match the signed digest")
+
+	// the metadata must be signed with the keys of the root
+	forged := expired
+	forged.Metadata = newSignedMetadata(t, verifyGUN, map[string]digest.Digest{"latest": signedDigest}, time.Now().Add(time.Hour))
+	forged.Metadata[data.CanonicalRootRole] = expired.Metadata[data.CanonicalRootRole]
+	forgedPath := writeBundle(t, dir, "forged.json", forged)
+	cli = test.NewFakeCli(&verifyClient{image: image})
+	cmd = newVerifyCommand(cli)
+	cmd.SetArgs([]string{"--bundle", forgedPath, "--allow-expired", "signed-repo"})
+	cmd.SetOutput(ioutil.Discard)
+	assert.ErrorContains(t, cmd.Execute(), "failed to verify the trust data in "+forgedPath)
+}
+
+func TestTrustVerifyTrustedRoot(t *testing.T) {
+	dir := fs.NewDir(t, t.Name())
+	defer dir.Remove()
+	defer config.SetDir(config.Dir())
+	config.SetDir(dir.Path())
+
+	manifest := newTestManifest(t)
+	_, payload, err := manifest.Payload()
+	assert.NilError(t, err)
+	signedDigest := digest.FromBytes(payload)
+	image := types.ImageInspect{ID: manifest.Config.Digest.String()}
+	bundle := trust.Bundle{
+		GUN:       verifyGUN,
+		Metadata:  newSignedMetadata(t, verifyGUN, map[string]digest.Digest{"latest": signedDigest}, time.Now().Add(time.Hour)),
+		Manifests: map[string][]byte{"latest": payload},
+	}
+	bundlePath := writeBundle(t, dir, "bundle.json", bundle)
+
+	var root data.SignedRoot
+	assert.NilError(t, json.Unmarshal(bundle.Metadata[data.CanonicalRootRole], &root))
+	rootKeyIDs := root.Signed.Roles[data.CanonicalRootRole].KeyIDs
+	assert.Assert(t, is.Len(rootKeyIDs, 1))
+	rootCert := dir.Join("root.crt")
+	assert.NilError(t, ioutil.WriteFile(rootCert, root.Signed.Keys[rootKeyIDs[0]].Public(), 0644))
+
+	otherRoot := newSignedMetadata(t, verifyGUN, nil, time.Now().Add(time.Hour))[data.CanonicalRootRole]
+	var other data.SignedRoot
+	assert.NilError(t, json.Unmarshal(otherRoot, &other))
+	otherKeyID := other.Signed.Roles[data.CanonicalRootRole].KeyIDs[0]
+
+	testCases := []struct {
+		name          string
+		args          []string
+		cachedRoot    []byte
+		expectedError string
+	}{
+		{
+			name:          "no-trusted-root",
+			args:          []string{"--bundle", bundlePath, "signed-repo"},
+			expectedError: "no trusted root for docker.io/library/signed-repo",
+		},
+		{
+			name:       "cached-root",
+			args:       []string{"--bundle", bundlePath, "signed-repo"},
+			cachedRoot: bundle.Metadata[data.CanonicalRootRole],
+		},
+		{
+			name:          "other-cached-root",
+			args:          []string{"--bundle", bundlePath, "signed-repo"},
+			cachedRoot:    otherRoot,
+			expectedError: "failed to verify the trust data in " + bundlePath,
+		},
+		{
+			name: "root-key",
+			args: []string{"--bundle", bundlePath, "--root-key", rootKeyIDs[0], "signed-repo"},
+		},
+		{
+			name:          "other-root-key",
+			args:          []string{"--bundle", bundlePath, "--root-key", otherKeyID, "signed-repo"},
+			expectedError: "failed to verify the trust data in " + bundlePath,
+		},
+		{
+			// the flags take precedence over the cached root
+			name:       "root-cert",
+			args:       []string{"--bundle", bundlePath, "--root-cert", rootCert, "signed-repo"},
+			cachedRoot: otherRoot,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cacheDir := filepath.Join(trust.GetTrustDirectory(), "tuf")
+			assert.NilError(t, os.RemoveAll(cacheDir))
+			if tc.cachedRoot != nil {
+				writeCachedMetadataFiles(t, verifyGUN, map[data.RoleName][]byte{data.CanonicalRootRole: tc.cachedRoot})
+			}
+			cli := test.NewFakeCli(&verifyClient{image: image})
+			cmd := newVerifyCommand(cli)
+			cmd.SetArgs(tc.args)
+			cmd.SetOutput(ioutil.Discard)
+			err := cmd.Execute()
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			assert.NilError(t, err)
+		})
+	}
+}
+
+func newTestManifest(t *testing.T) *schema2.DeserializedManifest {
+	t.Helper()
+	return newTestManifestWithConfig(t, `{"architecture": "amd64", "os": "linux"}`)
+}
+
+func newTestManifestWithConfig(t *testing.T, imageConfig string) *schema2.DeserializedManifest {
+	t.Helper()
+	config := []byte(imageConfig)
+	manifest, err := schema2.FromStruct(schema2.Manifest{
+		Versioned: schema2.SchemaVersion,
+		Config: distribution.Descriptor{
+			MediaType: schema2.MediaTypeImageConfig,
+			Size:      int64(len(config)),
+			Digest:    digest.FromBytes(config),
+		},
+	})
+	assert.NilError(t, err)
+	return manifest
+}
+
+func writeBundle(t *testing.T, dir *fs.Dir, name string, bundle trust.Bundle) string {
+	t.Helper()
+	content, err := json.Marshal(bundle)
+	assert.NilError(t, err)
+	assert.NilError(t, ioutil.WriteFile(dir.Join(name), content, 0644))
+	return dir.Join(name)
+}
+
+// newSignedMetadata returns the TUF metadata of a repository whose tags are
+// signed in the releases role, expiring at the given time.
+func newSignedMetadata(t *testing.T, gun string, tags map[string]digest.Digest, expires time.Time) map[data.RoleName][]byte {
+	t.Helper()
+	cs := cryptoservice.NewCryptoService(trustmanager.NewKeyMemoryStore(passphrase.ConstantRetriever("password")))
+	keys := make(map[data.RoleName]data.PublicKey)
+	for _, role := range []data.RoleName{data.CanonicalRootRole, data.CanonicalTargetsRole, data.CanonicalSnapshotRole, data.CanonicalTimestampRole, trust.ReleasesRole} {
+		key, err := cs.Create(role, data.GUN(gun), data.ECDSAKey)
+		assert.NilError(t, err)
+		keys[role] = key
+	}
+	rootPrivKey, _, err := cs.GetPrivateKey(keys[data.CanonicalRootRole].ID())
+	assert.NilError(t, err)
+	cert, err := cryptoservice.GenerateCertificate(rootPrivKey, data.GUN(gun), time.Now().Add(-time.Hour), time.Now().Add(24*time.Hour))
+	assert.NilError(t, err)
+	keys[data.CanonicalRootRole] = tufutils.CertToKey(cert)
+
+	repo := tuf.NewRepo(cs)
+	assert.NilError(t, repo.InitRoot(
+		data.NewBaseRole(data.CanonicalRootRole, 1, keys[data.CanonicalRootRole]),
+		data.NewBaseRole(data.CanonicalTimestampRole, 1, keys[data.CanonicalTimestampRole]),
+		data.NewBaseRole(data.CanonicalSnapshotRole, 1, keys[data.CanonicalSnapshotRole]),
+		data.NewBaseRole(data.CanonicalTargetsRole, 1, keys[data.CanonicalTargetsRole]),
+		false,
+	))
+	_, err = repo.InitTargets(data.CanonicalTargetsRole)
+	assert.NilError(t, err)
+	assert.NilError(t, repo.UpdateDelegationKeys(trust.ReleasesRole, data.KeyList{keys[trust.ReleasesRole]}, nil, 1))
+	assert.NilError(t, repo.UpdateDelegationPaths(trust.ReleasesRole, []string{""}, nil, false))
+	files := data.Files{}
+	for tag, dgst := range tags {
+		h, err := hex.DecodeString(dgst.Hex())
+		assert.NilError(t, err)
+		files[tag] = data.FileMeta{Length: 1, Hashes: data.Hashes{"sha256": h}}
+	}
+	_, err = repo.AddTargets(trust.ReleasesRole, files)
+	assert.NilError(t, err)
+	assert.NilError(t, repo.InitSnapshot())
+	assert.NilError(t, repo.InitTimestamp())
+
+	metadata := make(map[data.RoleName][]byte)
+	addSigned := func(role data.RoleName, signed *data.Signed, err error) {
+		assert.NilError(t, err)
+		content, err := json.Marshal(signed)
+		assert.NilError(t, err)
+		metadata[role] = content
+	}
+	signed, err := repo.SignRoot(expires, nil)
+	addSigned(data.CanonicalRootRole, signed, err)
+	signed, err = repo.SignTargets(trust.ReleasesRole, expires)
+	addSigned(trust.ReleasesRole, signed, err)
+	signed, err = repo.SignTargets(data.CanonicalTargetsRole, expires)
+	addSigned(data.CanonicalTargetsRole, signed, err)
+	signed, err = repo.SignSnapshot(expires)
+	addSigned(data.CanonicalSnapshotRole, signed, err)
+	signed, err = repo.SignTimestamp(expires)
+	addSigned(data.CanonicalTimestampRole, signed, err)
+	return metadata
+}
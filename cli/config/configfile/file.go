@@ -48,8 +48,14 @@ type ConfigFile struct {
 	StackOrchestrator    string                       `json:"stackOrchestrator,omitempty"`
 	Kubernetes           *KubernetesConfig            `json:"kubernetes,omitempty"`
 	CurrentContext       string                       `json:"currentContext,omitempty"`
+	ContentTrustPolicy   string                       `json:"contentTrustPolicy,omitempty"`
 	CLIPluginsExtraDirs  []string                     `json:"cliPluginsExtraDirs,omitempty"`
+	CLIPluginsHooks      *bool                        `json:"cliPluginsHooks,omitempty"`
+	CLIPluginsPolicy     string                       `json:"cliPluginsPolicy,omitempty"`
+	CLIPluginsDigests    map[string][]string          `json:"cliPluginsDigests,omitempty"`
 	Plugins              map[string]map[string]string `json:"plugins,omitempty"`
+	ConnectionHelpers    map[string]string            `json:"connectionHelpers,omitempty"`
+	ProjectContexts      map[string]string            `json:"projectContexts,omitempty"`
 }
 
 // ProxyConfig contains proxy configuration settings
@@ -5,10 +5,18 @@ import (
 	"context"
 	"net"
 	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
 
+	"github.com/docker/cli/cli/config"
 	"github.com/docker/cli/cli/connhelper/commandconn"
 	"github.com/docker/cli/cli/connhelper/ssh"
+	"github.com/google/shlex"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 )
 
 // ConnectionHelper allows to connect to a remote host with custom stream provider binary.
@@ -22,25 +30,109 @@ type ConnectionHelper struct {
 //
 // ssh://<user>@<host> URL requires Docker 18.09 or later on the remote host.
 func GetConnectionHelper(daemonURL string) (*ConnectionHelper, error) {
+	return GetConnectionHelperWithOptions(daemonURL, Options{})
+}
+
+// Options are the options of the connection helpers
+type Options struct {
+	// SSH are additional options of the connections to ssh:// URLs
+	SSH ssh.Options
+	// Helpers maps URL schemes, or names, to the command lines of their
+	// connection helpers. They override the built-in helpers.
+	Helpers map[string]string
+	// Helper is the name in Helpers of the connection helper of the URL,
+	// whatever its scheme. It overrides the helper of the scheme.
+	Helper string
+}
+
+// helperNamePattern matches the names of the connection helpers, which have
+// the syntax of URL schemes
+var helperNamePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*$`)
+
+// ValidateHelperName checks the name of a connection helper is valid
+func ValidateHelperName(name string) error {
+	if !helperNamePattern.MatchString(name) {
+		return errors.Errorf("invalid connection helper name %q: it must start with a letter, followed by letters, digits, \"+\", \".\" or \"-\"", name)
+	}
+	return nil
+}
+
+// GetConnectionHelperWithOptions returns Docker-specific connection helper for
+// the given URL, with the given options. It returns nil without error when no
+// helper is registered for the scheme.
+//
+// A connection helper command is run with the URL as last argument, and
+// proxies its standard input and output to the API of the daemon, as
+// "docker system dial-stdio" does.
+//
+// The ssh connections made by the built-in helper share a master connection
+// to the host for the duration of the CLI invocation, unless the options
+// configure connection sharing. With the builtin ssh transport, they are made
+// without running an ssh binary, see ssh.Dialer.
+func GetConnectionHelperWithOptions(daemonURL string, opts Options) (*ConnectionHelper, error) {
 	u, err := url.Parse(daemonURL)
 	if err != nil {
 		return nil, err
 	}
+	helper := opts.Helpers[u.Scheme]
+	if opts.Helper != "" {
+		if err := ValidateHelperName(opts.Helper); err != nil {
+			return nil, err
+		}
+		if helper = opts.Helpers[opts.Helper]; helper == "" {
+			return nil, errors.Errorf("connection helper %q is not configured in the connectionHelpers of the configuration file", opts.Helper)
+		}
+	}
+	if helper != "" {
+		args, err := shlex.Split(helper)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid connection helper %q", helper)
+		}
+		if len(args) == 0 {
+			return nil, errors.Errorf("invalid connection helper %q", helper)
+		}
+		return GetCommandConnectionHelper(args[0], append(args[1:], daemonURL)...)
+	}
 	switch scheme := u.Scheme; scheme {
 	case "ssh":
 		sp, err := ssh.ParseURL(daemonURL)
 		if err != nil {
 			return nil, errors.Wrap(err, "ssh host connection is not valid")
 		}
+		sshOpts := opts.SSH
+		if err := sshOpts.Validate(); err != nil {
+			return nil, errors.Wrap(err, "ssh host connection is not valid")
+		}
+		sp.Merge(sshOpts)
+		if sp.Transport == ssh.TransportBuiltin {
+			dialer, err := ssh.NewDialer(*sp)
+			if err != nil {
+				return nil, errors.Wrap(err, "ssh host connection is not valid")
+			}
+			return &ConnectionHelper{
+				Dialer: dialer.DialContext,
+				Host:   "http://docker",
+			}, nil
+		}
+		controlDir := sshControlDir()
+		if controlDir != "" {
+			// The socket is private to the process, so that the master
+			// connection isn't reused by other invocations of the CLI. %C is
+			// a hash of the local host, the remote host, port and user.
+			sp.ControlPath = filepath.Join(controlDir, strconv.Itoa(os.Getpid())+"-%C")
+		}
 		return &ConnectionHelper{
 			Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				if controlDir != "" {
+					if err := os.MkdirAll(controlDir, 0700); err != nil {
+						logrus.Debugf("ssh connection sharing disabled: %v", err)
+					}
+				}
 				return commandconn.New(ctx, "ssh", append(sp.Args(), []string{"--", "docker", "system", "dial-stdio"}...)...)
 			},
 			Host: "http://docker",
 		}, nil
 	}
-	// Future version may support plugins via ~/.docker/config.json. e.g. "dind"
-	// See docker/cli#889 for the previous discussion.
 	return nil, err
 }
 
@@ -53,3 +145,13 @@ func GetCommandConnectionHelper(cmd string, flags ...string) (*ConnectionHelper,
 		Host: "http://docker",
 	}, nil
 }
+
+// sshControlDir returns the directory of the sockets of the master ssh
+// connections, or an empty string where ssh doesn't support connection
+// sharing.
+func sshControlDir() string {
+	if runtime.GOOS == "windows" {
+		return ""
+	}
+	return filepath.Join(config.Dir(), "ssh")
+}
@@ -0,0 +1,60 @@
+// +build !windows
+
+package connhelper
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+func TestGetConnectionHelperWithOptions(t *testing.T) {
+	// the helpers print the URL they are run with
+	opts := Options{
+		Helpers: map[string]string{
+			"dind":    `sh -c 'printf "dind %s" "$0"'`,
+			"ssh":     `sh -c 'printf "custom ssh %s" "$0"'`,
+			"context": `sh -c 'printf "context %s" "$0"'`,
+		},
+	}
+	testCases := []struct {
+		url      string
+		helper   string
+		expected string
+	}{
+		{url: "dind://builder", expected: "dind dind://builder"},
+		{url: "ssh://me@example.com", expected: "custom ssh ssh://me@example.com"},
+		{url: "dind://builder", helper: "context", expected: "context dind://builder"},
+		{url: "tcp://example.com:2376", helper: "context", expected: "context tcp://example.com:2376"},
+	}
+	for _, tc := range testCases {
+		opts.Helper = tc.helper
+		helper, err := GetConnectionHelperWithOptions(tc.url, opts)
+		assert.NilError(t, err)
+		assert.Assert(t, helper != nil)
+		assert.Check(t, is.Equal("http://docker", helper.Host))
+		conn, err := helper.Dialer(context.Background(), "tcp", "docker:80")
+		assert.NilError(t, err)
+		output, err := ioutil.ReadAll(conn)
+		assert.NilError(t, err)
+		assert.Check(t, is.Equal(tc.expected, string(output)))
+		conn.Close()
+	}
+
+	opts.Helper = ""
+	helper, err := GetConnectionHelperWithOptions("tcp://example.com:2376", opts)
+	assert.NilError(t, err)
+	assert.Check(t, is.Nil(helper))
+
+	_, err = GetConnectionHelperWithOptions("dind://builder", Options{Helpers: map[string]string{"dind": `sh -c 'unterminated`}})
+	assert.Check(t, is.ErrorContains(err, "invalid connection helper"))
+
+	_, err = GetConnectionHelperWithOptions("dind://builder", Options{Helper: "missing"})
+	assert.Check(t, is.Error(err, `connection helper "missing" is not configured in the connectionHelpers of the configuration file`))
+
+	_, err = GetConnectionHelperWithOptions("dind://builder", Options{Helper: "sh -c evil"})
+	assert.Check(t, is.ErrorContains(err, `invalid connection helper name "sh -c evil"`))
+}
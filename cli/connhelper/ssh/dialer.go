@@ -0,0 +1,407 @@
+package ssh
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/pkg/homedir"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	gossh "golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// socketPath is the path of the socket of the Docker daemon on the host,
+// which the builtin transport forwards connections to
+const socketPath = "/var/run/docker.sock"
+
+// globalKnownHostsFile is the known_hosts file of all the users
+const globalKnownHostsFile = "/etc/ssh/ssh_known_hosts"
+
+// Dialer connects to the Docker daemon of a host with the ssh client built in
+// the CLI, authenticating with the keys of ssh-agent or the identity file, and
+// checking the host keys with the known_hosts files. The connections share a
+// single ssh connection to the host, and are forwarded to the socket of the
+// daemon, or to "docker system dial-stdio" on the host if it doesn't allow
+// forwarding to the socket.
+//
+// Of the ssh options, only ConnectTimeout, StrictHostKeyChecking and
+// UserKnownHostsFile are supported. With StrictHostKeyChecking=accept-new,
+// the keys of unknown hosts are added to the first UserKnownHostsFile.
+type Dialer struct {
+	spec            Spec
+	user            string
+	connectTimeout  time.Duration
+	hostKeyChecking string
+	knownHostsFiles []string
+	// knownHostsFile is the known_hosts file the keys of new hosts are
+	// recorded in, with StrictHostKeyChecking=accept-new
+	knownHostsFile string
+
+	mu     sync.Mutex
+	client *gossh.Client
+	// dialStdio is set once forwarding to the socket of the daemon failed
+	dialStdio bool
+}
+
+// NewDialer returns a Dialer for the ssh connection
+func NewDialer(sp Spec) (*Dialer, error) {
+	d := &Dialer{
+		spec:            sp,
+		user:            sp.User,
+		hostKeyChecking: hostKeyCheckingStrict,
+		knownHostsFiles: []string{filepath.Join(homedir.Get(), ".ssh", "known_hosts")},
+	}
+	for _, opt := range sp.Config {
+		kv := strings.SplitN(opt, "=", 2)
+		if len(kv) != 2 {
+			return nil, errors.Errorf("invalid ssh option %q, expected KEY=VALUE", opt)
+		}
+		key, value := strings.ToLower(strings.TrimSpace(kv[0])), strings.TrimSpace(kv[1])
+		switch key {
+		case "connecttimeout":
+			seconds, err := strconv.Atoi(value)
+			if err != nil || seconds < 0 {
+				return nil, errors.Errorf("invalid ssh option %q, expected a number of seconds", opt)
+			}
+			d.connectTimeout = time.Duration(seconds) * time.Second
+		case "stricthostkeychecking":
+			switch strings.ToLower(value) {
+			case "yes", "ask":
+				d.hostKeyChecking = hostKeyCheckingStrict
+			case "accept-new":
+				d.hostKeyChecking = hostKeyCheckingAcceptNew
+			case "no", "off":
+				d.hostKeyChecking = hostKeyCheckingNo
+			default:
+				return nil, errors.Errorf("invalid ssh option %q, expected yes, accept-new or no", opt)
+			}
+		case "userknownhostsfile":
+			d.knownHostsFiles = nil
+			for _, file := range strings.Fields(value) {
+				d.knownHostsFiles = append(d.knownHostsFiles, expandHome(file))
+			}
+		default:
+			return nil, errors.Errorf("ssh option %q is not supported by the %s ssh transport", opt, TransportBuiltin)
+		}
+	}
+	if len(d.knownHostsFiles) > 0 {
+		d.knownHostsFile = d.knownHostsFiles[0]
+	}
+	d.knownHostsFiles = append(d.knownHostsFiles, globalKnownHostsFile)
+	if d.user == "" {
+		if u, err := user.Current(); err == nil {
+			d.user = u.Username
+		} else if d.user = os.Getenv("USER"); d.user == "" {
+			return nil, errors.Wrap(err, "no ssh user specified")
+		}
+	}
+	return d, nil
+}
+
+// DialContext connects to the Docker daemon, the network and address are
+// ignored
+func (d *Dialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	client, err := d.sshClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	d.mu.Lock()
+	dialStdio := d.dialStdio
+	d.mu.Unlock()
+	if !dialStdio {
+		conn, err := client.Dial("unix", socketPath)
+		if err == nil {
+			return conn, nil
+		}
+		logrus.Debugf("forwarding to %s failed, falling back to docker system dial-stdio: %v", socketPath, err)
+		d.mu.Lock()
+		d.dialStdio = true
+		d.mu.Unlock()
+	}
+	return newStdioConn(client)
+}
+
+// sshClient returns the ssh connection to the host, connecting if it isn't
+// connected yet or anymore
+func (d *Dialer) sshClient(ctx context.Context) (*gossh.Client, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.client != nil {
+		return d.client, nil
+	}
+	client, err := d.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	d.client = client
+	go func() {
+		client.Wait()
+		d.mu.Lock()
+		if d.client == client {
+			d.client = nil
+		}
+		d.mu.Unlock()
+	}()
+	return client, nil
+}
+
+// connect connects to the host, through the jump hosts if any
+func (d *Dialer) connect(ctx context.Context) (*gossh.Client, error) {
+	hops := make([]*Spec, 0, len(d.spec.JumpHosts)+1)
+	for _, jump := range d.spec.JumpHosts {
+		if !strings.Contains(jump, "://") {
+			jump = "ssh://" + jump
+		}
+		hop, err := ParseURL(jump)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid jump host %q", jump)
+		}
+		hops = append(hops, hop)
+	}
+	hops = append(hops, &d.spec)
+
+	knownHosts, err := readKnownHosts(d.knownHostsFiles)
+	if err != nil {
+		return nil, err
+	}
+	signers, closeAgent, err := d.signers()
+	if err != nil {
+		return nil, err
+	}
+	defer closeAgent()
+	auth := gossh.PublicKeysCallback(func() ([]gossh.Signer, error) {
+		return signers, nil
+	})
+
+	var client *gossh.Client
+	for _, hop := range hops {
+		port := hop.Port
+		if port == "" {
+			port = "22"
+		}
+		address := net.JoinHostPort(hop.Host, port)
+		user := hop.User
+		if user == "" {
+			user = d.user
+		}
+		config := &gossh.ClientConfig{
+			User:              user,
+			Auth:              []gossh.AuthMethod{auth},
+			HostKeyCallback:   knownHosts.hostKeyCallback(d.hostKeyChecking, d.knownHostsFile),
+			HostKeyAlgorithms: knownHosts.keyTypes(address),
+		}
+		var conn net.Conn
+		if client == nil {
+			dialer := net.Dialer{Timeout: d.connectTimeout}
+			conn, err = dialer.DialContext(ctx, "tcp", address)
+		} else {
+			conn, err = client.Dial("tcp", address)
+		}
+		if err == nil {
+			var (
+				c     gossh.Conn
+				chans <-chan gossh.NewChannel
+				reqs  <-chan *gossh.Request
+			)
+			if c, chans, reqs, err = gossh.NewClientConn(conn, address, config); err == nil {
+				next := gossh.NewClient(c, chans, reqs)
+				if client != nil {
+					// the connection to the jump host is closed with the
+					// connection through it
+					jump := client
+					go func() {
+						next.Wait()
+						jump.Close()
+					}()
+				}
+				client = next
+				continue
+			}
+			conn.Close()
+		}
+		if client != nil {
+			client.Close()
+		}
+		return nil, errors.Wrapf(err, "ssh connection to %s failed", address)
+	}
+	return client, nil
+}
+
+// signers returns the keys of ssh-agent, if running, and of the identity file,
+// or of the default identity files of the user if there is none. The returned
+// function closes the connection to ssh-agent once authenticated.
+func (d *Dialer) signers() ([]gossh.Signer, func(), error) {
+	var signers []gossh.Signer
+	closeAgent := func() {}
+	if socket := os.Getenv("SSH_AUTH_SOCK"); socket != "" {
+		conn, err := net.Dial("unix", socket)
+		if err != nil {
+			logrus.Debugf("ssh-agent is unavailable: %v", err)
+		} else {
+			closeAgent = func() { conn.Close() }
+			agentSigners, err := agent.NewClient(conn).Signers()
+			if err != nil {
+				logrus.Debugf("failed to list the keys of ssh-agent: %v", err)
+			}
+			signers = append(signers, agentSigners...)
+		}
+	}
+	if d.spec.IdentityFile != "" {
+		signer, err := readIdentity(expandHome(d.spec.IdentityFile))
+		if err != nil {
+			closeAgent()
+			return nil, nil, err
+		}
+		signers = append(signers, signer)
+	} else {
+		for _, name := range []string{"id_rsa", "id_ecdsa", "id_ed25519"} {
+			signer, err := readIdentity(filepath.Join(homedir.Get(), ".ssh", name))
+			if err != nil {
+				if !os.IsNotExist(errors.Cause(err)) {
+					logrus.Debugf("ignoring identity: %v", err)
+				}
+				continue
+			}
+			signers = append(signers, signer)
+		}
+	}
+	if len(signers) == 0 {
+		closeAgent()
+		return nil, nil, errors.New("no ssh key available, add one to ssh-agent or specify an identity file")
+	}
+	return signers, closeAgent, nil
+}
+
+// readIdentity reads an unencrypted private key, encrypted keys have to be
+// added to ssh-agent
+func readIdentity(path string) (gossh.Signer, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := gossh.ParsePrivateKey(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: invalid private key", path)
+	}
+	return signer, nil
+}
+
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		return filepath.Join(homedir.Get(), path[1:])
+	}
+	return path
+}
+
+// stdioConn is a connection to "docker system dial-stdio" run on the host
+type stdioConn struct {
+	session *gossh.Session
+	stdin   io.WriteCloser
+	stdout  io.Reader
+	stderr  lockedBuffer
+}
+
+func newStdioConn(client *gossh.Client) (net.Conn, error) {
+	session, err := client.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	c := &stdioConn{session: session}
+	session.Stderr = &c.stderr
+	if c.stdin, err = session.StdinPipe(); err == nil {
+		if c.stdout, err = session.StdoutPipe(); err == nil {
+			err = session.Start("docker system dial-stdio")
+		}
+	}
+	if err != nil {
+		session.Close()
+		return nil, errors.Wrap(err, "failed to run docker system dial-stdio")
+	}
+	return c, nil
+}
+
+func (c *stdioConn) Read(p []byte) (int, error) {
+	n, err := c.stdout.Read(p)
+	if err == io.EOF {
+		if stderr := c.stderr.String(); stderr != "" {
+			return n, errors.Errorf("docker system dial-stdio failed: %s", strings.TrimSpace(stderr))
+		}
+	}
+	return n, err
+}
+
+func (c *stdioConn) Write(p []byte) (int, error) {
+	return c.stdin.Write(p)
+}
+
+// CloseWrite closes the standard input of docker system dial-stdio
+func (c *stdioConn) CloseWrite() error {
+	return c.stdin.Close()
+}
+
+func (c *stdioConn) Close() error {
+	c.stdin.Close()
+	return c.session.Close()
+}
+
+func (c *stdioConn) LocalAddr() net.Addr {
+	return stdioAddr{}
+}
+
+func (c *stdioConn) RemoteAddr() net.Addr {
+	return stdioAddr{}
+}
+
+// SetDeadline is not supported, and does nothing
+func (c *stdioConn) SetDeadline(t time.Time) error {
+	return nil
+}
+
+// SetReadDeadline is not supported, and does nothing
+func (c *stdioConn) SetReadDeadline(t time.Time) error {
+	return nil
+}
+
+// SetWriteDeadline is not supported, and does nothing
+func (c *stdioConn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
+
+type stdioAddr struct{}
+
+func (stdioAddr) Network() string {
+	return "ssh"
+}
+
+func (stdioAddr) String() string {
+	return "docker system dial-stdio"
+}
+
+// lockedBuffer is a buffer safe for concurrent use
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
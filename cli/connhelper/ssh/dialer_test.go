@@ -0,0 +1,397 @@
+package ssh
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/pkg/errors"
+	gossh "golang.org/x/crypto/ssh"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+	"gotest.tools/env"
+	"gotest.tools/fs"
+)
+
+// testServer is an ssh server accepting the user "me" authenticated with its
+// client key. It forwards connections to the socket of the daemon, and runs
+// commands, echoing their input after a line describing them. It also
+// forwards TCP connections, to be used as a jump host.
+type testServer struct {
+	listener    net.Listener
+	hostKey     gossh.PublicKey
+	connections int32
+}
+
+func newTestServer(t *testing.T, clientKey gossh.PublicKey, forward bool) *testServer {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+	hostSigner, err := gossh.NewSignerFromKey(key)
+	assert.NilError(t, err)
+	config := &gossh.ServerConfig{
+		PublicKeyCallback: func(conn gossh.ConnMetadata, key gossh.PublicKey) (*gossh.Permissions, error) {
+			if conn.User() == "me" && bytes.Equal(key.Marshal(), clientKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, errors.New("unauthorized")
+		},
+	}
+	config.AddHostKey(hostSigner)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	s := &testServer{listener: listener, hostKey: hostSigner.PublicKey()}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			atomic.AddInt32(&s.connections, 1)
+			go serveTestConn(conn, config, forward)
+		}
+	}()
+	return s
+}
+
+func (s *testServer) port() string {
+	return strconv.Itoa(s.listener.Addr().(*net.TCPAddr).Port)
+}
+
+func (s *testServer) knownHost() string {
+	return "[127.0.0.1]:" + s.port() + " " + string(gossh.MarshalAuthorizedKey(s.hostKey))
+}
+
+func serveTestConn(conn net.Conn, config *gossh.ServerConfig, forward bool) {
+	_, chans, reqs, err := gossh.NewServerConn(conn, config)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	go gossh.DiscardRequests(reqs)
+	for newChan := range chans {
+		switch newChan.ChannelType() {
+		case "direct-streamlocal@openssh.com":
+			if !forward {
+				newChan.Reject(gossh.Prohibited, "forwarding disabled")
+				continue
+			}
+			var msg struct {
+				SocketPath string
+				Reserved0  string
+				Reserved1  uint32
+			}
+			if err := gossh.Unmarshal(newChan.ExtraData(), &msg); err != nil {
+				newChan.Reject(gossh.ConnectionFailed, err.Error())
+				continue
+			}
+			ch, chReqs, err := newChan.Accept()
+			if err != nil {
+				continue
+			}
+			go gossh.DiscardRequests(chReqs)
+			go echo(ch, "socket "+msg.SocketPath)
+		case "direct-tcpip":
+			var msg struct {
+				Host       string
+				Port       uint32
+				OriginHost string
+				OriginPort uint32
+			}
+			if err := gossh.Unmarshal(newChan.ExtraData(), &msg); err != nil {
+				newChan.Reject(gossh.ConnectionFailed, err.Error())
+				continue
+			}
+			target, err := net.Dial("tcp", net.JoinHostPort(msg.Host, strconv.Itoa(int(msg.Port))))
+			if err != nil {
+				newChan.Reject(gossh.ConnectionFailed, err.Error())
+				continue
+			}
+			ch, chReqs, err := newChan.Accept()
+			if err != nil {
+				target.Close()
+				continue
+			}
+			go gossh.DiscardRequests(chReqs)
+			go func() {
+				io.Copy(ch, target)
+				ch.Close()
+			}()
+			go func() {
+				io.Copy(target, ch)
+				target.Close()
+			}()
+		case "session":
+			ch, chReqs, err := newChan.Accept()
+			if err != nil {
+				continue
+			}
+			go func() {
+				for req := range chReqs {
+					var exec struct{ Command string }
+					if req.Type != "exec" || gossh.Unmarshal(req.Payload, &exec) != nil {
+						req.Reply(false, nil)
+						continue
+					}
+					req.Reply(true, nil)
+					go echo(ch, "exec "+exec.Command)
+				}
+			}()
+		default:
+			newChan.Reject(gossh.UnknownChannelType, newChan.ChannelType())
+		}
+	}
+}
+
+func echo(ch gossh.Channel, description string) {
+	defer ch.Close()
+	fmt.Fprintln(ch, description)
+	io.Copy(ch, ch)
+}
+
+// writeTestClientKey writes a private key in the directory, returning its path
+// and its public key
+func writeTestClientKey(t *testing.T, dir *fs.Dir) (string, gossh.PublicKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+	der, err := x509.MarshalECPrivateKey(key)
+	assert.NilError(t, err)
+	path := dir.Join("id_ecdsa")
+	assert.NilError(t, ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0600))
+	publicKey, err := gossh.NewPublicKey(key.Public())
+	assert.NilError(t, err)
+	return path, publicKey
+}
+
+// checkEcho checks the connection is echoed after the description line
+func checkEcho(t *testing.T, conn net.Conn, description string) {
+	t.Helper()
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadString('\n')
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal(description+"\n", line))
+	_, err = fmt.Fprintln(conn, "ping")
+	assert.NilError(t, err)
+	line, err = reader.ReadString('\n')
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal("ping\n", line))
+}
+
+func TestDialer(t *testing.T) {
+	defer env.Patch(t, "SSH_AUTH_SOCK", "")()
+	dir := fs.NewDir(t, t.Name())
+	defer dir.Remove()
+	identity, clientKey := writeTestClientKey(t, dir)
+	server := newTestServer(t, clientKey, true)
+	defer server.listener.Close()
+	knownHosts := dir.Join("known_hosts")
+	assert.NilError(t, ioutil.WriteFile(knownHosts, []byte(server.knownHost()), 0600))
+
+	sp, err := ParseURL("ssh://me@127.0.0.1:" + server.port() + "?transport=builtin&identity=" + identity + "&option=UserKnownHostsFile=" + knownHosts)
+	assert.NilError(t, err)
+	dialer, err := NewDialer(*sp)
+	assert.NilError(t, err)
+	for i := 0; i < 2; i++ {
+		conn, err := dialer.DialContext(context.Background(), "tcp", "docker:2375")
+		assert.NilError(t, err)
+		checkEcho(t, conn, "socket "+socketPath)
+	}
+	// the connections share the ssh connection
+	assert.Check(t, is.Equal(int32(1), atomic.LoadInt32(&server.connections)))
+}
+
+func TestDialerDialStdio(t *testing.T) {
+	defer env.Patch(t, "SSH_AUTH_SOCK", "")()
+	dir := fs.NewDir(t, t.Name())
+	defer dir.Remove()
+	identity, clientKey := writeTestClientKey(t, dir)
+	server := newTestServer(t, clientKey, false)
+	defer server.listener.Close()
+	knownHosts := dir.Join("known_hosts")
+	assert.NilError(t, ioutil.WriteFile(knownHosts, []byte(server.knownHost()), 0600))
+
+	dialer, err := NewDialer(Spec{
+		User: "me",
+		Host: "127.0.0.1",
+		Port: server.port(),
+		Options: Options{
+			IdentityFile: identity,
+			Config:       []string{"UserKnownHostsFile=" + knownHosts},
+		},
+	})
+	assert.NilError(t, err)
+	for i := 0; i < 2; i++ {
+		conn, err := dialer.DialContext(context.Background(), "tcp", "docker:2375")
+		assert.NilError(t, err)
+		checkEcho(t, conn, "exec docker system dial-stdio")
+	}
+}
+
+func TestDialerJumpHost(t *testing.T) {
+	defer env.Patch(t, "SSH_AUTH_SOCK", "")()
+	dir := fs.NewDir(t, t.Name())
+	defer dir.Remove()
+	identity, clientKey := writeTestClientKey(t, dir)
+	jump := newTestServer(t, clientKey, false)
+	defer jump.listener.Close()
+	server := newTestServer(t, clientKey, true)
+	defer server.listener.Close()
+	knownHosts := dir.Join("known_hosts")
+	assert.NilError(t, ioutil.WriteFile(knownHosts, []byte(jump.knownHost()+server.knownHost()), 0600))
+
+	dialer, err := NewDialer(Spec{
+		User: "me",
+		Host: "127.0.0.1",
+		Port: server.port(),
+		Options: Options{
+			IdentityFile: identity,
+			JumpHosts:    []string{"me@127.0.0.1:" + jump.port()},
+			Config:       []string{"UserKnownHostsFile=" + knownHosts},
+		},
+	})
+	assert.NilError(t, err)
+	conn, err := dialer.DialContext(context.Background(), "tcp", "docker:2375")
+	assert.NilError(t, err)
+	checkEcho(t, conn, "socket "+socketPath)
+}
+
+func TestDialerHostKeyVerification(t *testing.T) {
+	defer env.Patch(t, "SSH_AUTH_SOCK", "")()
+	dir := fs.NewDir(t, t.Name())
+	defer dir.Remove()
+	identity, clientKey := writeTestClientKey(t, dir)
+	server := newTestServer(t, clientKey, true)
+	defer server.listener.Close()
+	other := newTestServer(t, clientKey, true)
+	defer other.listener.Close()
+	knownHosts := dir.Join("known_hosts")
+	// the key of the other server, for the address of the server
+	changed := "[127.0.0.1]:" + server.port() + " " + string(gossh.MarshalAuthorizedKey(other.hostKey))
+
+	testCases := []struct {
+		doc                string
+		knownHosts         string
+		options            []string
+		expectedError      string
+		expectedKnownHosts string
+	}{
+		{
+			doc:           "unknown host",
+			expectedError: "host key verification failed: no ecdsa-sha2-nistp256 host key is known for [127.0.0.1]:" + server.port(),
+		},
+		{
+			doc:     "unknown host accepted",
+			options: []string{"StrictHostKeyChecking=no"},
+		},
+		{
+			doc:                "unknown host recorded",
+			options:            []string{"StrictHostKeyChecking=accept-new"},
+			expectedKnownHosts: "[127.0.0.1]:" + server.port() + " " + string(gossh.MarshalAuthorizedKey(server.hostKey)),
+		},
+		{
+			doc:           "changed key",
+			knownHosts:    changed,
+			options:       []string{"StrictHostKeyChecking=no"},
+			expectedError: "host key verification failed: the ecdsa-sha2-nistp256 host key of [127.0.0.1]:" + server.port() + " has changed",
+		},
+		{
+			doc:           "revoked key",
+			knownHosts:    server.knownHost() + "@revoked * " + string(gossh.MarshalAuthorizedKey(server.hostKey)),
+			expectedError: "is revoked",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.doc, func(t *testing.T) {
+			assert.NilError(t, ioutil.WriteFile(knownHosts, []byte(tc.knownHosts), 0600))
+			dialer, err := NewDialer(Spec{
+				User: "me",
+				Host: "127.0.0.1",
+				Port: server.port(),
+				Options: Options{
+					IdentityFile: identity,
+					Config:       append([]string{"UserKnownHostsFile=" + knownHosts}, tc.options...),
+				},
+			})
+			assert.NilError(t, err)
+			conn, err := dialer.DialContext(context.Background(), "tcp", "docker:2375")
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			assert.NilError(t, err)
+			conn.Close()
+			if tc.expectedKnownHosts != "" {
+				recorded, err := ioutil.ReadFile(knownHosts)
+				assert.NilError(t, err)
+				assert.Check(t, is.Equal(tc.expectedKnownHosts, string(recorded)))
+			}
+		})
+	}
+}
+
+func TestNewDialerInvalidOptions(t *testing.T) {
+	testCases := []struct {
+		option        string
+		expectedError string
+	}{
+		{
+			option:        "ConnectTimeout=soon",
+			expectedError: `invalid ssh option "ConnectTimeout=soon", expected a number of seconds`,
+		},
+		{
+			option:        "StrictHostKeyChecking=maybe",
+			expectedError: `invalid ssh option "StrictHostKeyChecking=maybe", expected yes, accept-new or no`,
+		},
+		{
+			option:        "ProxyCommand=nc %h %p",
+			expectedError: `ssh option "ProxyCommand=nc %h %p" is not supported by the builtin ssh transport`,
+		},
+	}
+	for _, tc := range testCases {
+		_, err := NewDialer(Spec{User: "me", Host: "foo", Options: Options{Config: []string{tc.option}}})
+		assert.Check(t, is.Error(err, tc.expectedError))
+	}
+}
+
+func TestReadKnownHosts(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+	publicKey, err := gossh.NewPublicKey(key.Public())
+	assert.NilError(t, err)
+	authorizedKey := string(gossh.MarshalAuthorizedKey(publicKey))
+	dir := fs.NewDir(t, t.Name(), fs.WithFile("known_hosts", strings.Join([]string{
+		"# comment",
+		"",
+		"foo " + authorizedKey,
+		"[bar]:2222 " + authorizedKey,
+		"@cert-authority *.example.com " + authorizedKey,
+	}, "\n")))
+	defer dir.Remove()
+
+	hosts, err := readKnownHosts([]string{dir.Join("known_hosts"), filepath.Join(dir.Path(), "missing")})
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual([]string{publicKey.Type()}, hosts.keyTypes("foo:22")))
+	assert.Check(t, is.DeepEqual([]string{publicKey.Type()}, hosts.keyTypes("bar:2222")))
+	assert.Check(t, is.Len(hosts.keyTypes("bar:22"), 0))
+	assert.Check(t, is.Len(hosts.keyTypes("baz:22"), 0))
+
+	assert.NilError(t, ioutil.WriteFile(dir.Join("invalid"), []byte("bar ssh-unknown AAAA\n"), 0600))
+	_, err = readKnownHosts([]string{dir.Join("invalid")})
+	assert.Check(t, is.ErrorContains(err, "failed to read the known hosts"))
+}
@@ -0,0 +1,132 @@
+package ssh
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	gossh "golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const (
+	// hostKeyCheckingStrict rejects unknown hosts
+	hostKeyCheckingStrict = "yes"
+	// hostKeyCheckingAcceptNew accepts unknown hosts and records their keys
+	hostKeyCheckingAcceptNew = "accept-new"
+	// hostKeyCheckingNo accepts unknown hosts without recording their keys
+	hostKeyCheckingNo = "no"
+)
+
+// knownHosts checks the host keys with known_hosts files, see sshd(8)
+type knownHosts struct {
+	check gossh.HostKeyCallback
+}
+
+// readKnownHosts reads the known_hosts files, missing files are ignored
+func readKnownHosts(files []string) (*knownHosts, error) {
+	var existing []string
+	for _, file := range files {
+		if _, err := os.Stat(file); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		existing = append(existing, file)
+	}
+	check, err := knownhosts.New(existing...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the known hosts")
+	}
+	return &knownHosts{check: check}, nil
+}
+
+// probeKey is a public key which matches no known host key, to list the known
+// keys of a host
+type probeKey struct{}
+
+func (probeKey) Type() string {
+	return "probe"
+}
+
+func (probeKey) Marshal() []byte {
+	return []byte("probe")
+}
+
+func (probeKey) Verify(data []byte, sig *gossh.Signature) error {
+	return errors.New("probe key")
+}
+
+// probeAddr is the remote address passed with the probe key, the host name
+// is checked instead of it
+var probeAddr = &net.TCPAddr{IP: net.IPv4zero}
+
+// keyTypes returns the types of the known keys of the host, the ssh client
+// prefers them so that the host presents a key it can verify
+func (hosts *knownHosts) keyTypes(address string) []string {
+	var types []string
+	if keyErr, ok := hosts.check(address, probeAddr, probeKey{}).(*knownhosts.KeyError); ok {
+		for _, known := range keyErr.Want {
+			types = append(types, known.Key.Type())
+		}
+	}
+	return types
+}
+
+// hostKeyCallback checks the host keys are known. Unknown hosts are rejected
+// if checking is hostKeyCheckingStrict, and their keys are recorded in
+// knownHostsFile if it is hostKeyCheckingAcceptNew. Changed or revoked host
+// keys are never accepted.
+func (hosts *knownHosts) hostKeyCallback(checking, knownHostsFile string) gossh.HostKeyCallback {
+	return func(hostname string, remote net.Addr, key gossh.PublicKey) error {
+		address := knownhosts.Normalize(hostname)
+		err := hosts.check(hostname, remote, key)
+		switch err := err.(type) {
+		case nil:
+			return nil
+		case *knownhosts.RevokedError:
+			return errors.Errorf("host key verification failed: the %s host key of %s is revoked", key.Type(), address)
+		case *knownhosts.KeyError:
+			if len(err.Want) > 0 {
+				return errors.Errorf("host key verification failed: the %s host key of %s has changed, someone could be eavesdropping on the connection", key.Type(), address)
+			}
+		default:
+			return errors.Wrap(err, "host key verification failed")
+		}
+		switch checking {
+		case hostKeyCheckingAcceptNew:
+			if err := addKnownHost(knownHostsFile, hostname, key); err != nil {
+				return errors.Wrapf(err, "host key verification failed: failed to record the %s host key of %s", key.Type(), address)
+			}
+			logrus.Debugf("added the %s host key of %s to %s", key.Type(), address, knownHostsFile)
+			return nil
+		case hostKeyCheckingNo:
+			logrus.Debugf("accepting unknown %s host key of %s", key.Type(), address)
+			return nil
+		default:
+			return errors.Errorf("host key verification failed: no %s host key is known for %s, connect to it with ssh first to add its key to known_hosts", key.Type(), address)
+		}
+	}
+}
+
+// addKnownHost appends the key of the host to the known_hosts file
+func addKnownHost(file, hostname string, key gossh.PublicKey) error {
+	if file == "" {
+		return errors.New("no known_hosts file")
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = f.WriteString(knownhosts.Line([]string{hostname}, key) + "\n")
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
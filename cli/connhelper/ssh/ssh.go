@@ -3,11 +3,31 @@ package ssh
 
 import (
 	"net/url"
+	"strings"
 
 	"github.com/pkg/errors"
 )
 
+// ControlPersist is how long the master connection shared by the ssh
+// connections of a CLI invocation stays open after the last one is closed. It
+// runs in the background so that closing the connection which started it
+// doesn't close the others.
+const ControlPersist = "1s"
+
+const (
+	// TransportSystem connects to ssh:// hosts running the ssh binary of the
+	// system
+	TransportSystem = "system"
+	// TransportBuiltin connects to ssh:// hosts with the ssh client built in
+	// the CLI, see Dialer
+	TransportBuiltin = "builtin"
+)
+
 // ParseURL parses URL
+//
+// The URL query can set options of the ssh connection: identity=FILE,
+// jump=HOST (repeatable) and option=KEY=VALUE (repeatable), equivalent to the
+// -i, -J and -o options of ssh, and transport=system|builtin.
 func ParseURL(daemonURL string) (*Spec, error) {
 	u, err := url.Parse(daemonURL)
 	if err != nil {
@@ -29,12 +49,21 @@ func ParseURL(daemonURL string) (*Spec, error) {
 	if sp.Host == "" {
 		return nil, errors.Errorf("no host specified")
 	}
+	if strings.HasPrefix(sp.Host, "-") {
+		return nil, errors.Errorf("invalid host %q", sp.Host)
+	}
 	sp.Port = u.Port()
 	if u.Path != "" {
 		return nil, errors.Errorf("extra path after the host: %q", u.Path)
 	}
 	if u.RawQuery != "" {
-		return nil, errors.Errorf("extra query after the host: %q", u.RawQuery)
+		query, err := url.ParseQuery(u.RawQuery)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid query %q", u.RawQuery)
+		}
+		if sp.Options, err = optionsFromQuery(query); err != nil {
+			return nil, err
+		}
 	}
 	if u.Fragment != "" {
 		return nil, errors.Errorf("extra fragment after the host: %q", u.Fragment)
@@ -42,11 +71,139 @@ func ParseURL(daemonURL string) (*Spec, error) {
 	return &sp, err
 }
 
+func optionsFromQuery(query url.Values) (Options, error) {
+	var opts Options
+	for key, values := range query {
+		switch key {
+		case "identity":
+			if len(values) > 1 {
+				return Options{}, errors.New("only one identity can be specified")
+			}
+			opts.IdentityFile = values[0]
+		case "jump":
+			opts.JumpHosts = append(opts.JumpHosts, values...)
+		case "option":
+			opts.Config = append(opts.Config, values...)
+		case "transport":
+			if len(values) > 1 {
+				return Options{}, errors.New("only one transport can be specified")
+			}
+			opts.Transport = values[0]
+		default:
+			return Options{}, errors.Errorf("unknown query parameter %q", key)
+		}
+	}
+	return opts, opts.Validate()
+}
+
+// Options are the options of an ssh connection, besides its destination
+type Options struct {
+	// IdentityFile is the file of the private key used to authenticate
+	IdentityFile string `json:",omitempty"`
+	// JumpHosts are the hosts the connection goes through, in order
+	JumpHosts []string `json:",omitempty"`
+	// Config are options in the KEY=VALUE format of the ssh configuration
+	// file
+	Config []string `json:",omitempty"`
+	// Transport is how to connect, TransportSystem (the default) or
+	// TransportBuiltin
+	Transport string `json:",omitempty"`
+}
+
+// Validate checks the options are valid
+func (o Options) Validate() error {
+	for _, opt := range o.Config {
+		if !strings.Contains(opt, "=") {
+			return errors.Errorf("invalid ssh option %q, expected KEY=VALUE", opt)
+		}
+	}
+	for _, host := range o.JumpHosts {
+		if host == "" {
+			return errors.New("empty jump host")
+		}
+		if strings.HasPrefix(host, "-") {
+			return errors.Errorf("invalid jump host %q", host)
+		}
+	}
+	switch o.Transport {
+	case "", TransportSystem, TransportBuiltin:
+	default:
+		return errors.Errorf("invalid ssh transport %q, expected %q or %q", o.Transport, TransportSystem, TransportBuiltin)
+	}
+	return nil
+}
+
+// safeOptions are the ssh options, in lower case, which can't make ssh run
+// local commands, load local code or write local files
+var safeOptions = map[string]struct{}{
+	"addressfamily":            {},
+	"batchmode":                {},
+	"ciphers":                  {},
+	"compression":              {},
+	"connectionattempts":       {},
+	"connecttimeout":           {},
+	"hostkeyalgorithms":        {},
+	"identitiesonly":           {},
+	"kexalgorithms":            {},
+	"loglevel":                 {},
+	"macs":                     {},
+	"port":                     {},
+	"preferredauthentications": {},
+	"pubkeyacceptedkeytypes":   {},
+	"serveralivecountmax":      {},
+	"serveraliveinterval":      {},
+	"stricthostkeychecking":    {},
+	"tcpkeepalive":             {},
+	"user":                     {},
+}
+
+// UnsafeOptions returns the keys of the options which aren't known to be
+// harmless, as options such as ProxyCommand or LocalCommand make ssh run local
+// commands
+func (o Options) UnsafeOptions() []string {
+	var unsafe []string
+	for _, opt := range o.Config {
+		key := optionKey(opt)
+		if _, ok := safeOptions[strings.ToLower(key)]; !ok {
+			unsafe = append(unsafe, key)
+		}
+	}
+	return unsafe
+}
+
+// optionKey returns the key of an option, which ssh separates from the value
+// with "=" or whitespace
+func optionKey(opt string) string {
+	opt = strings.TrimSpace(opt)
+	if i := strings.IndexAny(opt, "= \t"); i >= 0 {
+		opt = opt[:i]
+	}
+	return opt
+}
+
+// Merge adds the given options, the identity file and the transport of the
+// options are kept if set
+func (o *Options) Merge(other Options) {
+	if o.IdentityFile == "" {
+		o.IdentityFile = other.IdentityFile
+	}
+	if o.Transport == "" {
+		o.Transport = other.Transport
+	}
+	o.JumpHosts = append(o.JumpHosts, other.JumpHosts...)
+	o.Config = append(o.Config, other.Config...)
+}
+
 // Spec of SSH URL
 type Spec struct {
 	User string
 	Host string
 	Port string
+	Options
+	// ControlPath, if set, is the path of the socket of a master connection
+	// shared by the ssh connections to the host, unless the options already
+	// configure connection sharing
+	ControlPath string
 }
 
 // Args returns args except "ssh" itself and "-- ..."
@@ -58,6 +215,32 @@ func (sp *Spec) Args() []string {
 	if sp.Port != "" {
 		args = append(args, "-p", sp.Port)
 	}
+	if sp.IdentityFile != "" {
+		args = append(args, "-i", sp.IdentityFile)
+	}
+	if len(sp.JumpHosts) > 0 {
+		args = append(args, "-J", strings.Join(sp.JumpHosts, ","))
+	}
+	for _, opt := range sp.Config {
+		args = append(args, "-o", opt)
+	}
+	if sp.ControlPath != "" && !sp.configuresControl() {
+		args = append(args,
+			"-o", "ControlMaster=auto",
+			"-o", "ControlPath="+sp.ControlPath,
+			"-o", "ControlPersist="+ControlPersist,
+		)
+	}
 	args = append(args, sp.Host)
 	return args
 }
+
+// configuresControl checks if the options configure connection sharing
+func (sp *Spec) configuresControl() bool {
+	for _, opt := range sp.Config {
+		if strings.HasPrefix(strings.ToLower(optionKey(opt)), "control") {
+			return true
+		}
+	}
+	return false
+}
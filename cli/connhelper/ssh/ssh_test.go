@@ -35,9 +35,35 @@ func TestParseURL(t *testing.T) {
 			url:           "ssh://foo/bar",
 			expectedError: `extra path after the host: "/bar"`,
 		},
+		{
+			url: "ssh://me@foo?identity=/home/me/.ssh/id_ed25519&jump=bastion1&jump=me@bastion2:2222&option=ConnectTimeout=5",
+			expectedArgs: []string{
+				"-l", "me",
+				"-i", "/home/me/.ssh/id_ed25519",
+				"-J", "bastion1,me@bastion2:2222",
+				"-o", "ConnectTimeout=5",
+				"foo",
+			},
+		},
+		{
+			url:          "ssh://foo?transport=builtin",
+			expectedArgs: []string{"foo"},
+		},
+		{
+			url:           "ssh://foo?transport=telnet",
+			expectedError: `invalid ssh transport "telnet", expected "system" or "builtin"`,
+		},
 		{
 			url:           "ssh://foo?bar",
-			expectedError: `extra query after the host: "bar"`,
+			expectedError: `unknown query parameter "bar"`,
+		},
+		{
+			url:           "ssh://foo?identity=a&identity=b",
+			expectedError: "only one identity can be specified",
+		},
+		{
+			url:           "ssh://foo?option=ConnectTimeout",
+			expectedError: `invalid ssh option "ConnectTimeout", expected KEY=VALUE`,
 		},
 		{
 			url:           "ssh://foo#bar",
@@ -47,6 +73,14 @@ func TestParseURL(t *testing.T) {
 			url:           "ssh://",
 			expectedError: "no host specified",
 		},
+		{
+			url:           "ssh://-oProxyCommand=foo",
+			expectedError: `invalid host "-oProxyCommand=foo"`,
+		},
+		{
+			url:           "ssh://foo?jump=-oProxyCommand=bar",
+			expectedError: `invalid jump host "-oProxyCommand=bar"`,
+		},
 		{
 			url:           "foo://bar",
 			expectedError: `expected scheme ssh, got "foo"`,
@@ -62,3 +96,35 @@ func TestParseURL(t *testing.T) {
 		}
 	}
 }
+
+func TestUnsafeOptions(t *testing.T) {
+	opts := Options{Config: []string{
+		"ConnectTimeout=5",
+		"ProxyCommand=nc %h %p",
+		" localcommand = id",
+		"ServerAliveInterval=30",
+		"ProxyCommand touch /tmp/x=y",
+		"ProxyCommand\ttouch /tmp/x=y",
+		"UserKnownHostsFile=/tmp/x",
+		"user\tme=x",
+	}}
+	assert.Check(t, is.DeepEqual([]string{"ProxyCommand", "localcommand", "ProxyCommand", "ProxyCommand", "UserKnownHostsFile"}, opts.UnsafeOptions()))
+	assert.Check(t, is.Len(Options{}.UnsafeOptions(), 0))
+}
+
+func TestArgsControlPath(t *testing.T) {
+	sp := Spec{Host: "foo", ControlPath: "/home/me/.docker/ssh/42-%C"}
+	assert.Check(t, is.DeepEqual([]string{
+		"-o", "ControlMaster=auto",
+		"-o", "ControlPath=/home/me/.docker/ssh/42-%C",
+		"-o", "ControlPersist=" + ControlPersist,
+		"foo",
+	}, sp.Args()))
+
+	// connection sharing configured by the user
+	for _, opt := range []string{"ControlMaster=no", "controlpath /tmp/%C=x", "ControlPersist\tno=x"} {
+		sp := sp
+		sp.Options = Options{Config: []string{opt}}
+		assert.Check(t, is.DeepEqual([]string{"-o", opt, "foo"}, sp.Args()), opt)
+	}
+}
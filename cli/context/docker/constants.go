@@ -3,4 +3,7 @@ package docker
 const (
 	// DockerEndpoint is the name of the docker endpoint in a stored context
 	DockerEndpoint = "docker"
+	// ConnectionEndpoint is the name of the options of the connection to the
+	// host of the docker endpoint in a stored context
+	ConnectionEndpoint = "docker-connection"
 )
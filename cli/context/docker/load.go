@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/docker/cli/cli/connhelper"
+	"github.com/docker/cli/cli/connhelper/ssh"
 	"github.com/docker/cli/cli/context"
 	"github.com/docker/cli/cli/context/store"
 	"github.com/docker/docker/client"
@@ -22,12 +23,34 @@ import (
 // a Docker Engine endpoint, without its tls config
 type EndpointMeta = context.EndpointMetaBase
 
+// ConnectionMeta are the options of the connection to the host of a docker
+// endpoint, stored beside the endpoint in a context
+type ConnectionMeta struct {
+	// SSH are the options of the connection to ssh:// hosts, in addition to
+	// the ones set in the URL
+	SSH *ssh.Options `json:",omitempty"`
+	// ConnectionHelper is the name, in the connectionHelpers of the CLI
+	// configuration file, of the connection helper of the host, whatever its
+	// scheme
+	ConnectionHelper string `json:",omitempty"`
+}
+
+// IsZero checks if no connection option is set
+func (c ConnectionMeta) IsZero() bool {
+	return c.SSH == nil && c.ConnectionHelper == ""
+}
+
 // Endpoint is a typed wrapper around a context-store generic endpoint describing
 // a Docker Engine endpoint, with its tls data
 type Endpoint struct {
 	EndpointMeta
 	TLSData     *context.TLSData
 	TLSPassword string
+	// Connection are the options of the connection to the host
+	Connection ConnectionMeta
+	// ConnectionHelpers maps URL schemes, or names, to the command lines of
+	// their connection helpers, as configured in the CLI configuration file
+	ConnectionHelpers map[string]string
 }
 
 // WithTLSData loads TLS materials for the endpoint
@@ -94,7 +117,14 @@ func (c *Endpoint) tlsConfig() (*tls.Config, error) {
 func (c *Endpoint) ClientOpts() ([]func(*client.Client) error, error) {
 	var result []func(*client.Client) error
 	if c.Host != "" {
-		helper, err := connhelper.GetConnectionHelper(c.Host)
+		helperOpts := connhelper.Options{
+			Helpers: c.ConnectionHelpers,
+			Helper:  c.Connection.ConnectionHelper,
+		}
+		if c.Connection.SSH != nil {
+			helperOpts.SSH = *c.Connection.SSH
+		}
+		helper, err := connhelper.GetConnectionHelperWithOptions(c.Host, helperOpts)
 		if err != nil {
 			return nil, err
 		}
@@ -164,3 +194,18 @@ func EndpointFromContext(metadata store.ContextMetadata) (EndpointMeta, error) {
 	}
 	return typed, nil
 }
+
+// ConnectionFromContext parses the options of the connection to the host of
+// a context docker endpoint into a typed ConnectionMeta structure. They are
+// empty if the context has none.
+func ConnectionFromContext(metadata store.ContextMetadata) (ConnectionMeta, error) {
+	conn, ok := metadata.Endpoints[ConnectionEndpoint]
+	if !ok {
+		return ConnectionMeta{}, nil
+	}
+	typed, ok := conn.(ConnectionMeta)
+	if !ok {
+		return ConnectionMeta{}, errors.Errorf("endpoint %q is not of type ConnectionMeta", ConnectionEndpoint)
+	}
+	return typed, nil
+}
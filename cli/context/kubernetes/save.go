@@ -10,9 +10,17 @@ import (
 
 // FromKubeConfig creates a Kubernetes endpoint from a Kubeconfig file
 func FromKubeConfig(kubeconfig, kubeContext, namespaceOverride string) (Endpoint, error) {
+	return FromKubeConfigOverrides(kubeconfig, &clientcmd.ConfigOverrides{CurrentContext: kubeContext, Context: clientcmdapi.Context{Namespace: namespaceOverride}})
+}
+
+// FromKubeConfigOverrides creates a Kubernetes endpoint from a Kubeconfig file,
+// with overrides of its context, such as its cluster or its user. The
+// certificate files referenced by the Kubeconfig file are read into the TLS
+// data of the endpoint.
+func FromKubeConfigOverrides(kubeconfig string, overrides *clientcmd.ConfigOverrides) (Endpoint, error) {
 	cfg := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
 		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfig},
-		&clientcmd.ConfigOverrides{CurrentContext: kubeContext, Context: clientcmdapi.Context{Namespace: namespaceOverride}})
+		overrides)
 	ns, _, err := cfg.Namespace()
 	if err != nil {
 		return Endpoint{}, err
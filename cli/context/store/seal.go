@@ -0,0 +1,277 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	// sealedArchiveVersion is the version of the format of sealed archives
+	sealedArchiveVersion = 1
+
+	encryptionPassphrase = "passphrase"
+	encryptionRecipient  = "recipient"
+
+	passphraseIterations    = 100000
+	maxPassphraseIterations = 10 * passphraseIterations
+	saltSize                = 16
+	aesKeySize              = 32
+)
+
+// SealOptions are the options used to seal a context archive. At most one of
+// Passphrase and Recipient can be set.
+type SealOptions struct {
+	// Passphrase encrypts the archive with a key derived from it
+	Passphrase []byte
+	// Recipient encrypts the archive for the owner of the matching private
+	// key. Only RSA keys are supported.
+	Recipient crypto.PublicKey
+	// Signer signs the archive. RSA and ECDSA keys are supported.
+	Signer crypto.Signer
+}
+
+// UnsealOptions are the options used to unseal a context archive
+type UnsealOptions struct {
+	// Passphrase is called to get the passphrase of archives encrypted with
+	// a passphrase
+	Passphrase func() ([]byte, error)
+	// Decrypter decrypts archives encrypted for a recipient
+	Decrypter crypto.Decrypter
+	// Verifier is the public key whose signature is required, if set
+	Verifier crypto.PublicKey
+}
+
+// sealedArchive is an encrypted and/or signed context archive, as written by
+// ExportSealed. Payload is the tar archive written by Export, encrypted with
+// AES-GCM if Encryption is set.
+type sealedArchive struct {
+	Version    int
+	Encryption string `json:",omitempty"`
+	Salt       []byte `json:",omitempty"`
+	Iterations int    `json:",omitempty"`
+	WrappedKey []byte `json:",omitempty"`
+	Nonce      []byte `json:",omitempty"`
+	Payload    []byte
+	Signature  []byte `json:",omitempty"`
+}
+
+// IsSealed checks if the archive read by the given reader is a sealed archive
+// rather than a plain tar archive, without consuming it.
+func IsSealed(reader *bufio.Reader) bool {
+	b, err := reader.Peek(1)
+	return err == nil && b[0] == '{'
+}
+
+// ExportSealed exports an existing context into an encrypted and/or signed
+// archive
+func ExportSealed(name string, s Store, opts SealOptions) ([]byte, error) {
+	reader := Export(name, s)
+	defer reader.Close()
+	payload, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	archive := sealedArchive{
+		Version: sealedArchiveVersion,
+		Payload: payload,
+	}
+	if err := archive.encrypt(opts); err != nil {
+		return nil, err
+	}
+	if opts.Signer != nil {
+		digest, err := archive.digest()
+		if err != nil {
+			return nil, err
+		}
+		if archive.Signature, err = opts.Signer.Sign(rand.Reader, digest, crypto.SHA256); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(&archive)
+}
+
+// Unseal verifies and decrypts a sealed archive, and returns a reader of the
+// context archive it contains, to be imported with Import, and whether the
+// sealed archive is signed.
+func Unseal(reader io.Reader, opts UnsealOptions) (io.Reader, bool, error) {
+	var archive sealedArchive
+	if err := json.NewDecoder(reader).Decode(&archive); err != nil {
+		return nil, false, fmt.Errorf("invalid context archive: %v", err)
+	}
+	if archive.Version != sealedArchiveVersion {
+		return nil, false, fmt.Errorf("unsupported context archive version %d", archive.Version)
+	}
+	signed := archive.Signature != nil
+	if opts.Verifier != nil {
+		if !signed {
+			return nil, false, errors.New("context archive is not signed")
+		}
+		if err := archive.verify(opts.Verifier); err != nil {
+			return nil, signed, err
+		}
+	}
+	payload, err := archive.decrypt(opts)
+	if err != nil {
+		return nil, signed, err
+	}
+	return bytes.NewReader(payload), signed, nil
+}
+
+func (a *sealedArchive) encrypt(opts SealOptions) error {
+	if opts.Passphrase == nil && opts.Recipient == nil {
+		return nil
+	}
+	if opts.Passphrase != nil && opts.Recipient != nil {
+		return errors.New("an archive can't be encrypted with both a passphrase and a recipient key")
+	}
+	var key []byte
+	if opts.Passphrase != nil {
+		a.Encryption = encryptionPassphrase
+		a.Iterations = passphraseIterations
+		a.Salt = make([]byte, saltSize)
+		if _, err := rand.Read(a.Salt); err != nil {
+			return err
+		}
+		key = pbkdf2.Key(opts.Passphrase, a.Salt, a.Iterations, aesKeySize, sha256.New)
+	} else {
+		recipient, ok := opts.Recipient.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("unsupported recipient key type %T, only RSA keys are supported", opts.Recipient)
+		}
+		a.Encryption = encryptionRecipient
+		key = make([]byte, aesKeySize)
+		if _, err := rand.Read(key); err != nil {
+			return err
+		}
+		var err error
+		if a.WrappedKey, err = rsa.EncryptOAEP(sha256.New(), rand.Reader, recipient, key, nil); err != nil {
+			return err
+		}
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	a.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(a.Nonce); err != nil {
+		return err
+	}
+	a.Payload = gcm.Seal(nil, a.Nonce, a.Payload, nil)
+	return nil
+}
+
+func (a *sealedArchive) decrypt(opts UnsealOptions) ([]byte, error) {
+	var key []byte
+	switch a.Encryption {
+	case "":
+		return a.Payload, nil
+	case encryptionPassphrase:
+		if opts.Passphrase == nil {
+			return nil, errors.New("context archive is encrypted with a passphrase")
+		}
+		if a.Iterations <= 0 || a.Iterations > maxPassphraseIterations {
+			return nil, &tamperedArchiveError{reason: "invalid context archive: the archive has been tampered with"}
+		}
+		passphrase, err := opts.Passphrase()
+		if err != nil {
+			return nil, err
+		}
+		key = pbkdf2.Key(passphrase, a.Salt, a.Iterations, aesKeySize, sha256.New)
+	case encryptionRecipient:
+		if opts.Decrypter == nil {
+			return nil, errors.New("context archive is encrypted for a recipient key, a decryption key is required")
+		}
+		var err error
+		if key, err = opts.Decrypter.Decrypt(rand.Reader, a.WrappedKey, &rsa.OAEPOptions{Hash: crypto.SHA256}); err != nil {
+			return nil, &tamperedArchiveError{reason: "unable to decrypt context archive: the decryption key doesn't match, or the archive has been tampered with"}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported context archive encryption %q", a.Encryption)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(a.Nonce) != gcm.NonceSize() {
+		return nil, &tamperedArchiveError{reason: "invalid context archive: the archive has been tampered with"}
+	}
+	payload, err := gcm.Open(nil, a.Nonce, a.Payload, nil)
+	if err != nil {
+		return nil, &tamperedArchiveError{reason: "unable to decrypt context archive: the passphrase or key is incorrect, or the archive has been tampered with"}
+	}
+	return payload, nil
+}
+
+// digest returns the digest of the archive covered by its signature
+func (a *sealedArchive) digest() ([]byte, error) {
+	unsigned := *a
+	unsigned.Signature = nil
+	data, err := json.Marshal(&unsigned)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(data)
+	return digest[:], nil
+}
+
+func (a *sealedArchive) verify(key crypto.PublicKey) error {
+	digest, err := a.digest()
+	if err != nil {
+		return err
+	}
+	verified := false
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		verified = rsa.VerifyPKCS1v15(k, crypto.SHA256, digest, a.Signature) == nil
+	case *ecdsa.PublicKey:
+		var sig struct{ R, S *big.Int }
+		if rest, err := asn1.Unmarshal(a.Signature, &sig); err == nil && len(rest) == 0 {
+			verified = ecdsa.Verify(k, digest, sig.R, sig.S)
+		}
+	default:
+		return fmt.Errorf("unsupported verification key type %T, only RSA and ECDSA keys are supported", key)
+	}
+	if !verified {
+		return &tamperedArchiveError{reason: "context archive signature verification failed: the archive has been tampered with, or was not signed with the expected key"}
+	}
+	return nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+type tamperedArchiveError struct {
+	reason string
+}
+
+func (e *tamperedArchiveError) Error() string {
+	return e.reason
+}
+
+// IsErrArchiveTampered checks if the given error is a "context archive has
+// been tampered with" condition
+func IsErrArchiveTampered(err error) bool {
+	_, ok := err.(*tamperedArchiveError)
+	return ok
+}
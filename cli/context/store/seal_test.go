@@ -0,0 +1,128 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+)
+
+func newSealTestStore(t *testing.T) (Store, func()) {
+	t.Helper()
+	testDir, err := ioutil.TempDir("", t.Name())
+	assert.NilError(t, err)
+	s := New(testDir, testCfg)
+	assert.NilError(t, s.CreateOrUpdateContext(ContextMetadata{
+		Endpoints: map[string]interface{}{
+			"ep1": endpoint{Foo: "bar"},
+		},
+		Metadata: context{Bar: "baz"},
+		Name:     "source",
+	}))
+	assert.NilError(t, s.ResetContextEndpointTLSMaterial("source", "ep1", &EndpointTLSData{
+		Files: map[string][]byte{
+			"key.pem": []byte("private-key"),
+		},
+	}))
+	return s, func() { os.RemoveAll(testDir) }
+}
+
+func importUnsealed(t *testing.T, s Store, sealed []byte, opts UnsealOptions) error {
+	t.Helper()
+	reader := bufio.NewReader(bytes.NewReader(sealed))
+	assert.Assert(t, IsSealed(reader))
+	unsealed, _, err := Unseal(reader, opts)
+	if err != nil {
+		return err
+	}
+	return Import("dest", s, unsealed)
+}
+
+func constantPassphrase(passphrase string) func() ([]byte, error) {
+	return func() ([]byte, error) { return []byte(passphrase), nil }
+}
+
+func TestSealPassphrase(t *testing.T) {
+	s, cleanup := newSealTestStore(t)
+	defer cleanup()
+
+	sealed, err := ExportSealed("source", s, SealOptions{Passphrase: []byte("secret")})
+	assert.NilError(t, err)
+	assert.Check(t, !bytes.Contains(sealed, []byte("private-key")))
+
+	err = importUnsealed(t, s, sealed, UnsealOptions{Passphrase: constantPassphrase("wrong")})
+	assert.Check(t, IsErrArchiveTampered(err))
+	err = importUnsealed(t, s, sealed, UnsealOptions{})
+	assert.Check(t, is.Error(err, "context archive is encrypted with a passphrase"))
+
+	assert.NilError(t, importUnsealed(t, s, sealed, UnsealOptions{Passphrase: constantPassphrase("secret")}))
+	data, err := s.GetContextTLSData("dest", "ep1", "key.pem")
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal("private-key", string(data)))
+}
+
+func TestSealRecipient(t *testing.T) {
+	s, cleanup := newSealTestStore(t)
+	defer cleanup()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NilError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NilError(t, err)
+
+	sealed, err := ExportSealed("source", s, SealOptions{Recipient: key.Public()})
+	assert.NilError(t, err)
+	err = importUnsealed(t, s, sealed, UnsealOptions{})
+	assert.Check(t, is.Error(err, "context archive is encrypted for a recipient key, a decryption key is required"))
+	err = importUnsealed(t, s, sealed, UnsealOptions{Decrypter: otherKey})
+	assert.Check(t, IsErrArchiveTampered(err))
+	assert.NilError(t, importUnsealed(t, s, sealed, UnsealOptions{Decrypter: key}))
+}
+
+func TestSealSignature(t *testing.T) {
+	s, cleanup := newSealTestStore(t)
+	defer cleanup()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NilError(t, err)
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+
+	for _, signer := range []crypto.Signer{rsaKey, ecdsaKey} {
+		sealed, err := ExportSealed("source", s, SealOptions{Signer: signer, Passphrase: []byte("secret")})
+		assert.NilError(t, err)
+		opts := UnsealOptions{Passphrase: constantPassphrase("secret"), Verifier: signer.Public()}
+		assert.NilError(t, importUnsealed(t, s, sealed, opts))
+		assert.NilError(t, s.RemoveContext("dest"))
+
+		var archive sealedArchive
+		assert.NilError(t, json.Unmarshal(sealed, &archive))
+		archive.Payload[0] ^= 0xff
+		tampered, err := json.Marshal(&archive)
+		assert.NilError(t, err)
+		err = importUnsealed(t, s, tampered, opts)
+		assert.Check(t, IsErrArchiveTampered(err))
+		assert.Check(t, is.ErrorContains(err, "signature verification failed"))
+	}
+
+	unsigned, err := ExportSealed("source", s, SealOptions{Passphrase: []byte("secret")})
+	assert.NilError(t, err)
+	err = importUnsealed(t, s, unsigned, UnsealOptions{Passphrase: constantPassphrase("secret"), Verifier: ecdsaKey.Public()})
+	assert.Check(t, is.Error(err, "context archive is not signed"))
+}
+
+func TestIsSealed(t *testing.T) {
+	s, cleanup := newSealTestStore(t)
+	defer cleanup()
+	r := Export("source", s)
+	defer r.Close()
+	assert.Check(t, !IsSealed(bufio.NewReader(r)))
+}
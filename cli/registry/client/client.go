@@ -3,6 +3,7 @@ package client
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/docker/distribution"
 	"github.com/docker/distribution/reference"
 	distributionclient "github.com/docker/distribution/registry/client"
+	"github.com/docker/distribution/registry/client/auth"
 	"github.com/docker/docker/api/types"
 	registrytypes "github.com/docker/docker/api/types/registry"
 	"github.com/opencontainers/go-digest"
@@ -28,6 +30,28 @@ type RegistryClient interface {
 	GetTags(ctx context.Context, ref reference.Named) ([]string, error)
 }
 
+// ContentClient is a RegistryClient which also reads, copies, lists and
+// deletes the content of registries. It is not part of RegistryClient, so
+// that the implementations of RegistryClient outside of this repository don't
+// have to implement it.
+type ContentClient interface {
+	RegistryClient
+	GetRawManifest(ctx context.Context, ref reference.Named) (distribution.Manifest, error)
+	CopyBlob(ctx context.Context, source reference.Canonical, target reference.Named) error
+	GetCatalog(ctx context.Context, domain string) ([]string, error)
+	GetBlob(ctx context.Context, ref reference.Canonical) ([]byte, error)
+	DeleteManifest(ctx context.Context, ref reference.Canonical) error
+}
+
+// AsContentClient returns the ContentClient of a registry client, or an error
+// if the client doesn't implement it
+func AsContentClient(c RegistryClient) (ContentClient, error) {
+	if contentClient, ok := c.(ContentClient); ok {
+		return contentClient, nil
+	}
+	return nil, errors.New("the registry client doesn't support reading, copying or deleting the content of registries")
+}
+
 // NewRegistryClient returns a new RegistryClient with a resolver
 func NewRegistryClient(resolver AuthConfigResolver, userAgent string, insecure bool) RegistryClient {
 	return &client{
@@ -46,6 +70,18 @@ type PutManifestOptions struct {
 	Payload   []byte
 }
 
+var (
+	// defaultRepositoryActions are the actions requested on repositories
+	defaultRepositoryActions = []string{"push", "pull"}
+	// deleteRepositoryActions are the actions requested on repositories to
+	// delete their content
+	deleteRepositoryActions = []string{"pull", "delete"}
+)
+
+// catalogPageSize is the number of repositories requested at once when
+// listing the catalog of a registry
+const catalogPageSize = 100
+
 type client struct {
 	authConfigResolver AuthConfigResolver
 	insecureRegistry   bool
@@ -63,6 +99,15 @@ func (err ErrBlobCreated) Error() string {
 		err.From, err.Target)
 }
 
+// ErrDeleteDisabled returned when a registry does not allow to delete manifests
+type ErrDeleteDisabled struct {
+	Registry string
+}
+
+func (err ErrDeleteDisabled) Error() string {
+	return fmt.Sprintf("registry %s does not allow deleting manifests: deletion must be enabled in the registry configuration", err.Registry)
+}
+
 // ErrHTTPProto returned if attempting to use TLS with a non-TLS registry
 type ErrHTTPProto struct {
 	OrigErr string
@@ -76,11 +121,7 @@ var _ RegistryClient = &client{}
 
 // MountBlob into the registry, so it can be referenced by a manifest
 func (c *client) MountBlob(ctx context.Context, sourceRef reference.Canonical, targetRef reference.Named) error {
-	repoEndpoint, err := newDefaultRepositoryEndpoint(targetRef, c.insecureRegistry)
-	if err != nil {
-		return err
-	}
-	repo, err := c.getRepositoryForReference(ctx, targetRef, repoEndpoint)
+	repo, err := c.getDefaultRepository(ctx, targetRef)
 	if err != nil {
 		return err
 	}
@@ -98,14 +139,68 @@ func (c *client) MountBlob(ctx context.Context, sourceRef reference.Canonical, t
 	return ErrBlobCreated{From: sourceRef, Target: targetRef}
 }
 
-// PutManifest sends the manifest to a registry and returns the new digest
-func (c *client) PutManifest(ctx context.Context, ref reference.Named, manifest distribution.Manifest) (digest.Digest, error) {
-	repoEndpoint, err := newDefaultRepositoryEndpoint(ref, c.insecureRegistry)
+// CopyBlob copies a blob from the source repository to the target repository.
+// The blob is mounted when both repositories are hosted on the same registry,
+// and streamed through the client otherwise, or if the registry declined the
+// mount.
+func (c *client) CopyBlob(ctx context.Context, sourceRef reference.Canonical, targetRef reference.Named) error {
+	if reference.Domain(sourceRef) == reference.Domain(targetRef) {
+		err := c.MountBlob(ctx, sourceRef, targetRef)
+		if _, created := err.(ErrBlobCreated); !created {
+			return err
+		}
+	}
+	return c.streamBlob(ctx, sourceRef, targetRef)
+}
+
+func (c *client) streamBlob(ctx context.Context, sourceRef reference.Canonical, targetRef reference.Named) error {
+	sourceRepo, err := c.getDefaultRepository(ctx, sourceRef)
 	if err != nil {
-		return digest.Digest(""), err
+		return err
+	}
+	targetRepo, err := c.getDefaultRepository(ctx, targetRef)
+	if err != nil {
+		return err
+	}
+
+	dgst := sourceRef.Digest()
+	switch _, err := targetRepo.Blobs(ctx).Stat(ctx, dgst); err {
+	case nil:
+		logrus.Debugf("blob %s already exists in %s", dgst, targetRef.Name())
+		return nil
+	case distribution.ErrBlobUnknown:
+	default:
+		return errors.Wrapf(err, "failed to stat blob %s in %s", dgst, targetRef.Name())
+	}
+
+	desc, err := sourceRepo.Blobs(ctx).Stat(ctx, dgst)
+	if err != nil {
+		return errors.Wrapf(err, "failed to stat blob %s", sourceRef)
+	}
+	reader, err := sourceRepo.Blobs(ctx).Open(ctx, dgst)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open blob %s", sourceRef)
 	}
+	defer reader.Close()
 
-	repo, err := c.getRepositoryForReference(ctx, ref, repoEndpoint)
+	writer, err := targetRepo.Blobs(ctx).Create(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create blob upload in %s", targetRef.Name())
+	}
+	if _, err := writer.ReadFrom(reader); err != nil {
+		writer.Cancel(ctx)
+		return errors.Wrapf(err, "failed to copy blob %s to %s", sourceRef, targetRef.Name())
+	}
+	if _, err := writer.Commit(ctx, desc); err != nil {
+		return errors.Wrapf(err, "failed to commit blob %s to %s", dgst, targetRef.Name())
+	}
+	logrus.Debugf("blob %s copied from %s to %s", dgst, sourceRef.Name(), targetRef.Name())
+	return nil
+}
+
+// PutManifest sends the manifest to a registry and returns the new digest
+func (c *client) PutManifest(ctx context.Context, ref reference.Named, manifest distribution.Manifest) (digest.Digest, error) {
+	repo, err := c.getDefaultRepository(ctx, ref)
 	if err != nil {
 		return digest.Digest(""), err
 	}
@@ -125,20 +220,84 @@ func (c *client) PutManifest(ctx context.Context, ref reference.Named, manifest
 }
 
 func (c *client) GetTags(ctx context.Context, ref reference.Named) ([]string, error) {
-	repoEndpoint, err := newDefaultRepositoryEndpoint(ref, c.insecureRegistry)
+	repo, err := c.getDefaultRepository(ctx, ref)
 	if err != nil {
 		return nil, err
 	}
+	return repo.Tags(ctx).All(ctx)
+}
 
-	repo, err := c.getRepositoryForReference(ctx, ref, repoEndpoint)
+// GetCatalog returns the names of all the repositories hosted on a registry
+// which supports the catalog API
+func (c *client) GetCatalog(ctx context.Context, domain string) ([]string, error) {
+	index, endpoint, err := newDefaultRegistryEndpoint(domain, c.insecureRegistry)
 	if err != nil {
 		return nil, err
 	}
-	return repo.Tags(ctx).All(ctx)
+	scope := auth.RegistryScope{Name: "catalog", Actions: []string{"*"}}
+	httpTransport, err := getHTTPTransport(c.authConfigResolver(ctx, index), endpoint, scope, c.userAgent)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to configure transport")
+	}
+	reg, err := distributionclient.NewRegistry(endpoint.URL.String(), httpTransport)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		repositories []string
+		last         string
+	)
+	for {
+		entries := make([]string, catalogPageSize)
+		n, err := reg.Repositories(ctx, entries, last)
+		repositories = append(repositories, entries[:n]...)
+		switch {
+		case err == io.EOF:
+			return repositories, nil
+		case err != nil:
+			return nil, errors.Wrapf(err, "failed to list repositories of %s", domain)
+		case n == 0:
+			return repositories, nil
+		}
+		last = entries[n-1]
+	}
 }
 
-func (c *client) getRepositoryForReference(ctx context.Context, ref reference.Named, repoEndpoint repositoryEndpoint) (distribution.Repository, error) {
-	httpTransport, err := c.getHTTPTransportForRepoEndpoint(ctx, repoEndpoint)
+// DeleteManifest deletes a manifest, and thereby all the tags referencing it,
+// from the registry
+func (c *client) DeleteManifest(ctx context.Context, ref reference.Canonical) error {
+	repo, err := c.getDefaultRepository(ctx, ref, deleteRepositoryActions...)
+	if err != nil {
+		return err
+	}
+	manifestService, err := repo.Manifests(ctx)
+	if err != nil {
+		return err
+	}
+	err = manifestService.Delete(ctx, ref.Digest())
+	if isUnsupportedOperation(err) {
+		return ErrDeleteDisabled{Registry: reference.Domain(ref)}
+	}
+	return errors.Wrapf(err, "failed to delete manifest %s", ref)
+}
+
+// getDefaultRepository returns the repository for the reference on the
+// default (push) endpoint of its registry. Push and pull access is requested
+// unless other actions are specified.
+func (c *client) getDefaultRepository(ctx context.Context, ref reference.Named, actions ...string) (distribution.Repository, error) {
+	repoEndpoint, err := newDefaultRepositoryEndpoint(ref, c.insecureRegistry)
+	if err != nil {
+		return nil, err
+	}
+	if len(actions) == 0 {
+		actions = defaultRepositoryActions
+	}
+	return c.getRepositoryForReference(ctx, ref, repoEndpoint, actions)
+}
+
+func (c *client) getRepositoryForReference(ctx context.Context, ref reference.Named, repoEndpoint repositoryEndpoint, actions []string) (distribution.Repository, error) {
+	httpTransport, err := c.getHTTPTransportForRepoEndpoint(ctx, repoEndpoint, actions)
 	if err != nil {
 		if strings.Contains(err.Error(), "server gave HTTP response to HTTPS client") {
 			return nil, ErrHTTPProto{OrigErr: err.Error()}
@@ -151,11 +310,15 @@ func (c *client) getRepositoryForReference(ctx context.Context, ref reference.Na
 	return distributionclient.NewRepository(repoName, repoEndpoint.BaseURL(), httpTransport)
 }
 
-func (c *client) getHTTPTransportForRepoEndpoint(ctx context.Context, repoEndpoint repositoryEndpoint) (http.RoundTripper, error) {
+func (c *client) getHTTPTransportForRepoEndpoint(ctx context.Context, repoEndpoint repositoryEndpoint, actions []string) (http.RoundTripper, error) {
+	scope := auth.RepositoryScope{
+		Repository: repoEndpoint.Name(),
+		Actions:    actions,
+	}
 	httpTransport, err := getHTTPTransport(
 		c.authConfigResolver(ctx, repoEndpoint.info.Index),
 		repoEndpoint.endpoint,
-		repoEndpoint.Name(),
+		scope,
 		c.userAgent)
 	return httpTransport, errors.Wrap(err, "failed to configure transport")
 }
@@ -173,6 +336,34 @@ func (c *client) GetManifest(ctx context.Context, ref reference.Named) (manifest
 	return result, err
 }
 
+// GetRawManifest returns the manifest or manifest list for the reference as
+// it is stored in the registry, without resolving the referenced manifests
+func (c *client) GetRawManifest(ctx context.Context, ref reference.Named) (distribution.Manifest, error) {
+	var result distribution.Manifest
+	fetch := func(ctx context.Context, repo distribution.Repository, ref reference.Named) (bool, error) {
+		var err error
+		result, err = fetchRawManifest(ctx, repo, ref)
+		return result != nil, err
+	}
+
+	err := c.iterateEndpoints(ctx, ref, fetch)
+	return result, err
+}
+
+// GetBlob returns the content of a blob, such as an image config, after
+// verifying its digest. The whole blob is loaded in memory.
+func (c *client) GetBlob(ctx context.Context, ref reference.Canonical) ([]byte, error) {
+	var result []byte
+	fetch := func(ctx context.Context, repo distribution.Repository, _ reference.Named) (bool, error) {
+		var err error
+		result, err = pullManifestSchemaV2ImageConfig(ctx, ref.Digest(), repo)
+		return result != nil, err
+	}
+
+	err := c.iterateEndpoints(ctx, ref, fetch)
+	return result, err
+}
+
 // GetManifestList returns a list of ImageManifest for the reference
 func (c *client) GetManifestList(ctx context.Context, ref reference.Named) ([]manifesttypes.ImageManifest, error) {
 	result := []manifesttypes.ImageManifest{}
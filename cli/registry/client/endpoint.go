@@ -10,6 +10,7 @@ import (
 	"github.com/docker/distribution/registry/client/auth"
 	"github.com/docker/distribution/registry/client/transport"
 	authtypes "github.com/docker/docker/api/types"
+	registrytypes "github.com/docker/docker/api/types/registry"
 	"github.com/docker/docker/registry"
 	"github.com/pkg/errors"
 )
@@ -39,7 +40,7 @@ func newDefaultRepositoryEndpoint(ref reference.Named, insecure bool) (repositor
 	if err != nil {
 		return repositoryEndpoint{}, err
 	}
-	endpoint, err := getDefaultEndpointFromRepoInfo(repoInfo)
+	endpoint, err := getDefaultEndpoint(reference.Domain(repoInfo.Name), repoInfo.Index.Secure)
 	if err != nil {
 		return repositoryEndpoint{}, err
 	}
@@ -49,7 +50,26 @@ func newDefaultRepositoryEndpoint(ref reference.Named, insecure bool) (repositor
 	return repositoryEndpoint{info: repoInfo, endpoint: endpoint}, nil
 }
 
-func getDefaultEndpointFromRepoInfo(repoInfo *registry.RepositoryInfo) (registry.APIEndpoint, error) {
+// newDefaultRegistryEndpoint returns the index and the default endpoint of a
+// registry, for operations which are not scoped to a repository
+func newDefaultRegistryEndpoint(domain string, insecure bool) (*registrytypes.IndexInfo, registry.APIEndpoint, error) {
+	// a trailing slash makes sure the domain is not mistaken for an official
+	// repository name
+	index, err := registry.ParseSearchIndexInfo(domain + "/")
+	if err != nil {
+		return nil, registry.APIEndpoint{}, err
+	}
+	endpoint, err := getDefaultEndpoint(index.Name, index.Secure)
+	if err != nil {
+		return nil, registry.APIEndpoint{}, err
+	}
+	if insecure {
+		endpoint.TLSConfig.InsecureSkipVerify = true
+	}
+	return index, endpoint, nil
+}
+
+func getDefaultEndpoint(domain string, secure bool) (registry.APIEndpoint, error) {
 	var err error
 
 	options := registry.ServiceOptions{}
@@ -57,13 +77,13 @@ func getDefaultEndpointFromRepoInfo(repoInfo *registry.RepositoryInfo) (registry
 	if err != nil {
 		return registry.APIEndpoint{}, err
 	}
-	endpoints, err := registryService.LookupPushEndpoints(reference.Domain(repoInfo.Name))
+	endpoints, err := registryService.LookupPushEndpoints(domain)
 	if err != nil {
 		return registry.APIEndpoint{}, err
 	}
 	// Default to the highest priority endpoint to return
 	endpoint := endpoints[0]
-	if !repoInfo.Index.Secure {
+	if !secure {
 		for _, ep := range endpoints {
 			if ep.URL.Scheme == "http" {
 				endpoint = ep
@@ -74,7 +94,7 @@ func getDefaultEndpointFromRepoInfo(repoInfo *registry.RepositoryInfo) (registry
 }
 
 // getHTTPTransport builds a transport for use in communicating with a registry
-func getHTTPTransport(authConfig authtypes.AuthConfig, endpoint registry.APIEndpoint, repoName string, userAgent string) (http.RoundTripper, error) {
+func getHTTPTransport(authConfig authtypes.AuthConfig, endpoint registry.APIEndpoint, scope auth.Scope, userAgent string) (http.RoundTripper, error) {
 	// get the http transport, this will be used in a client to upload manifest
 	base := &http.Transport{
 		Proxy: http.ProxyFromEnvironment,
@@ -102,7 +122,11 @@ func getHTTPTransport(authConfig authtypes.AuthConfig, endpoint registry.APIEndp
 		modifiers = append(modifiers, auth.NewAuthorizer(challengeManager, passThruTokenHandler))
 	} else {
 		creds := registry.NewStaticCredentialStore(&authConfig)
-		tokenHandler := auth.NewTokenHandler(authTransport, creds, repoName, "push", "pull")
+		tokenHandler := auth.NewTokenHandlerWithOptions(auth.TokenHandlerOptions{
+			Transport:   authTransport,
+			Credentials: creds,
+			Scopes:      []auth.Scope{scope},
+		})
 		basicHandler := auth.NewBasicHandler(creds)
 		modifiers = append(modifiers, auth.NewAuthorizer(challengeManager, tokenHandler, basicHandler))
 	}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 
 	"github.com/docker/cli/cli/manifest/types"
 	"github.com/docker/distribution"
@@ -60,6 +61,25 @@ func fetchList(ctx context.Context, repo distribution.Repository, ref reference.
 	}
 }
 
+// fetchRawManifest pulls a manifest or manifest list from a registry and
+// returns it untouched, after verifying its digest.
+func fetchRawManifest(ctx context.Context, repo distribution.Repository, ref reference.Named) (distribution.Manifest, error) {
+	manifest, err := getManifest(ctx, repo, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	switch manifest.(type) {
+	case *schema2.DeserializedManifest, *manifestlist.DeserializedManifestList:
+	default:
+		return nil, errors.Errorf("unsupported manifest format: %T", manifest)
+	}
+	if _, err := validateManifestDigest(ref, manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
 func getManifest(ctx context.Context, repo distribution.Repository, ref reference.Named) (distribution.Manifest, error) {
 	manSvc, err := repo.Manifests(ctx)
 	if err != nil {
@@ -199,6 +219,20 @@ func continueOnError(err error) bool {
 	return false
 }
 
+// isUnsupportedOperation returns true if the registry refused an operation
+// because it is disabled, such as deletion
+func isUnsupportedOperation(err error) bool {
+	switch v := err.(type) {
+	case errcode.Errors:
+		return len(v) > 0 && isUnsupportedOperation(v[0])
+	case errcode.Error:
+		return v.Code == errcode.ErrorCodeUnsupported
+	case *distclient.UnexpectedHTTPResponseError:
+		return v.StatusCode == http.StatusMethodNotAllowed
+	}
+	return false
+}
+
 func (c *client) iterateEndpoints(ctx context.Context, namedRef reference.Named, each func(context.Context, distribution.Repository, reference.Named) (bool, error)) error {
 	endpoints, err := allEndpoints(namedRef, c.insecureRegistry)
 	if err != nil {
@@ -229,7 +263,7 @@ func (c *client) iterateEndpoints(ctx context.Context, namedRef reference.Named,
 			endpoint.TLSConfig.InsecureSkipVerify = true
 		}
 		repoEndpoint := repositoryEndpoint{endpoint: endpoint, info: repoInfo}
-		repo, err := c.getRepositoryForReference(ctx, namedRef, repoEndpoint)
+		repo, err := c.getRepositoryForReference(ctx, namedRef, repoEndpoint, defaultRepositoryActions)
 		if err != nil {
 			logrus.Debugf("error %s with repo endpoint %+v", err, repoEndpoint)
 			if _, ok := err.(ErrHTTPProto); ok {
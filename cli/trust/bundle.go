@@ -0,0 +1,162 @@
+package trust
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	digest "github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/theupdateframework/notary/trustpinning"
+	"github.com/theupdateframework/notary/tuf"
+	"github.com/theupdateframework/notary/tuf/data"
+)
+
+// Bundle holds the trust data of a repository, to verify its signed tags
+// without access to the trust server.
+type Bundle struct {
+	// GUN is the globally unique name of the repository.
+	GUN string
+	// Metadata is the TUF metadata of the repository, indexed by role.
+	Metadata map[data.RoleName][]byte
+	// Manifests are the image manifests of the signed tags, indexed by tag.
+	// Their digests are verified against the TUF metadata, so that they can
+	// be trusted to match the ID of local images to their signed tags.
+	Manifests map[string][]byte `json:",omitempty"`
+}
+
+// cachedMetadataDirectory returns the directory where the notary client
+// caches the TUF metadata of the repository.
+func cachedMetadataDirectory(gun string) string {
+	return filepath.Join(GetTrustDirectory(), "tuf", filepath.FromSlash(gun), "metadata")
+}
+
+// GetCachedBundle returns a bundle of the TUF metadata of the repository
+// cached in the trust directory by the notary client.
+func GetCachedBundle(gun string) (*Bundle, error) {
+	dir := cachedMetadataDirectory(gun)
+	bundle := &Bundle{GUN: gun, Metadata: make(map[data.RoleName][]byte)}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || filepath.Ext(path) != ".json" {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		role := data.RoleName(strings.TrimSuffix(filepath.ToSlash(rel), ".json"))
+		if !data.ValidRole(role) {
+			return nil
+		}
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		bundle.Metadata[role] = content
+		return nil
+	})
+	if os.IsNotExist(err) || (err == nil && len(bundle.Metadata) == 0) {
+		return nil, errors.Errorf("no cached trust data for %s", gun)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return bundle, nil
+}
+
+// GetCachedRoot returns the root metadata of the repository cached in the
+// trust directory by the notary client, or nil if there is none.
+func GetCachedRoot(gun string) ([]byte, error) {
+	content, err := ioutil.ReadFile(filepath.Join(cachedMetadataDirectory(gun), data.CanonicalRootRole.String()+".json"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return content, err
+}
+
+// Verify verifies the signatures of the TUF metadata of the bundle, starting
+// from its root, and returns the verified repository. Expired metadata is
+// rejected unless allowExpired is set.
+//
+// The root of the bundle must be trusted: either trustedRoot is set, and the
+// root of the bundle is the same root or a rotation of it, or the root keys
+// must match the certificates or the CA pinned by trustPin. The root of the
+// bundle is never trusted on first use.
+func (b *Bundle) Verify(trustedRoot []byte, trustPin trustpinning.TrustPinConfig, allowExpired bool) (*tuf.Repo, error) {
+	gun := data.GUN(b.GUN)
+	trustPin.DisableTOFU = true
+	var builder tuf.RepoBuilder
+	rootVersion := 1
+	switch {
+	case trustedRoot != nil:
+		// the trusted root can have expired since it was cached
+		trustedBuilder := tuf.NewRepoBuilder(gun, nil, trustpinning.TrustPinConfig{})
+		if err := trustedBuilder.Load(data.CanonicalRootRole, trustedRoot, 1, true); err != nil {
+			return nil, errors.Wrapf(err, "invalid trusted root metadata for %s", b.GUN)
+		}
+		// the root of the bundle can't be older than the trusted root
+		rootVersion = trustedBuilder.GetLoadedVersion(data.CanonicalRootRole)
+		builder = trustedBuilder.BootstrapNewBuilderWithNewTrustpin(trustPin)
+	case len(trustPin.Certs) > 0 || len(trustPin.CA) > 0:
+		builder = tuf.NewRepoBuilder(gun, nil, trustPin)
+	default:
+		return nil, errors.Errorf("no trusted root for %s", b.GUN)
+	}
+	for _, role := range []data.RoleName{data.CanonicalRootRole, data.CanonicalTimestampRole, data.CanonicalSnapshotRole, data.CanonicalTargetsRole} {
+		content, ok := b.Metadata[role]
+		if !ok {
+			return nil, errors.Errorf("trust data for %s has no %s metadata", b.GUN, role)
+		}
+		minVersion := 1
+		if role == data.CanonicalRootRole {
+			minVersion = rootVersion
+		}
+		if err := builder.Load(role, content, minVersion, allowExpired); err != nil {
+			return nil, errors.Wrapf(err, "invalid %s metadata for %s", role, b.GUN)
+		}
+	}
+
+	// delegations are loaded after their parent, and skipped if invalid
+	var delegations []data.RoleName
+	for role := range b.Metadata {
+		if data.IsDelegation(role) {
+			delegations = append(delegations, role)
+		}
+	}
+	sort.Slice(delegations, func(i, j int) bool {
+		di, dj := strings.Count(delegations[i].String(), "/"), strings.Count(delegations[j].String(), "/")
+		if di != dj {
+			return di < dj
+		}
+		return delegations[i] < delegations[j]
+	})
+	for _, role := range delegations {
+		if err := builder.Load(role, b.Metadata[role], 1, allowExpired); err != nil {
+			logrus.Warnf("Skipping invalid %s metadata for %s: %v", role, b.GUN, err)
+		}
+	}
+
+	repo, _, err := builder.Finish()
+	return repo, err
+}
+
+// SignedDigest returns the digest of the given tag in the verified repository,
+// as signed in the releases role or in the targets role.
+func SignedDigest(repo *tuf.Repo, tag string) (digest.Digest, error) {
+	for _, role := range []data.RoleName{ReleasesRole, data.CanonicalTargetsRole} {
+		meta := repo.TargetMeta(role, tag)
+		if meta == nil {
+			continue
+		}
+		h, ok := meta.Hashes["sha256"]
+		if !ok {
+			return "", errors.New("no valid hash, expecting sha256")
+		}
+		return digest.NewDigestFromHex("sha256", hex.EncodeToString(h)), nil
+	}
+	return "", errors.Errorf("no signed digest for tag %s", tag)
+}
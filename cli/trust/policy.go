@@ -0,0 +1,90 @@
+package trust
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Policy is a content trust policy, mapping repositories to the content trust
+// requirements enforced on them.
+type Policy struct {
+	// Rules are the rules of the policy. The first rule matching a
+	// repository applies to it.
+	Rules []PolicyRule
+}
+
+// PolicyRule is the content trust requirement enforced on the repositories
+// matching a pattern.
+type PolicyRule struct {
+	// Repository is the pattern of the normalized names of the repositories
+	// the rule applies to, like "registry.example.com/production/*". "*"
+	// matches any sequence of characters except "/", and "**" matches any
+	// sequence of characters.
+	Repository string
+	// Signers are the signers who must have signed the tags of the
+	// repositories. If empty, the tags must be signed by any signer.
+	Signers []string `json:",omitempty"`
+	// Insecure allows unsigned content to be used from the repositories.
+	Insecure bool `json:",omitempty"`
+}
+
+// LoadPolicy reads the content trust policy in the named file.
+func LoadPolicy(filename string) (*Policy, error) {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read content trust policy")
+	}
+	var policy Policy
+	if err := json.Unmarshal(content, &policy); err != nil {
+		return nil, errors.Wrapf(err, "invalid content trust policy %s", filename)
+	}
+	for _, rule := range policy.Rules {
+		if rule.Repository == "" {
+			return nil, errors.Errorf("invalid content trust policy %s: rule without repository", filename)
+		}
+		if rule.Insecure && len(rule.Signers) > 0 {
+			return nil, errors.Errorf("invalid content trust policy %s: rule for %s can't both allow insecure content and require signers", filename, rule.Repository)
+		}
+	}
+	return &policy, nil
+}
+
+// Rule returns the first rule of the policy matching the normalized name of
+// a repository, if any.
+func (p *Policy) Rule(name string) (PolicyRule, bool) {
+	if p == nil {
+		return PolicyRule{}, false
+	}
+	for _, rule := range p.Rules {
+		if matchRepositoryPattern(rule.Repository, name) {
+			return rule, true
+		}
+	}
+	return PolicyRule{}, false
+}
+
+// matchRepositoryPattern returns whether name matches pattern, where "*"
+// matches any sequence of characters except "/", and "**" matches any
+// sequence of characters.
+func matchRepositoryPattern(pattern, name string) bool {
+	var expr strings.Builder
+	expr.WriteString("^")
+	for i, part := range strings.Split(pattern, "**") {
+		if i > 0 {
+			expr.WriteString(".*")
+		}
+		for j, subpart := range strings.Split(part, "*") {
+			if j > 0 {
+				expr.WriteString("[^/]*")
+			}
+			expr.WriteString(regexp.QuoteMeta(subpart))
+		}
+	}
+	expr.WriteString("$")
+	matched, err := regexp.MatchString(expr.String(), name)
+	return err == nil && matched
+}
@@ -0,0 +1,62 @@
+package trust
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+	"gotest.tools/fs"
+)
+
+func TestPolicyRule(t *testing.T) {
+	policy := &Policy{
+		Rules: []PolicyRule{
+			{Repository: "registry.example.com/production/*", Signers: []string{"alice"}},
+			{Repository: "registry.example.com/**", Insecure: true},
+			{Repository: "docker.io/library/*"},
+		},
+	}
+	testCases := []struct {
+		name     string
+		expected string
+	}{
+		{name: "registry.example.com/production/web", expected: "registry.example.com/production/*"},
+		{name: "registry.example.com/production/web/frontend", expected: "registry.example.com/**"},
+		{name: "registry.example.com/staging/web", expected: "registry.example.com/**"},
+		{name: "docker.io/library/alpine", expected: "docker.io/library/*"},
+		{name: "docker.io/example/alpine"},
+		{name: "registry.example.com.evil/production/web"},
+	}
+	for _, tc := range testCases {
+		rule, ok := policy.Rule(tc.name)
+		assert.Check(t, is.Equal(tc.expected != "", ok), tc.name)
+		assert.Check(t, is.Equal(tc.expected, rule.Repository), tc.name)
+	}
+
+	var nilPolicy *Policy
+	_, ok := nilPolicy.Rule("docker.io/library/alpine")
+	assert.Check(t, !ok)
+}
+
+func TestLoadPolicy(t *testing.T) {
+	dir := fs.NewDir(t, t.Name(),
+		fs.WithFile("valid.json", `{"Rules": [{"Repository": "docker.io/library/*", "Signers": ["alice"]}]}`),
+		fs.WithFile("invalid.json", `{"Rules": `),
+		fs.WithFile("no-repository.json", `{"Rules": [{"Insecure": true}]}`),
+		fs.WithFile("insecure-signers.json", `{"Rules": [{"Repository": "**", "Insecure": true, "Signers": ["alice"]}]}`),
+	)
+	defer dir.Remove()
+
+	policy, err := LoadPolicy(dir.Join("valid.json"))
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(&Policy{Rules: []PolicyRule{{Repository: "docker.io/library/*", Signers: []string{"alice"}}}}, policy))
+
+	_, err = LoadPolicy(dir.Join("missing.json"))
+	assert.Check(t, is.ErrorContains(err, "failed to read content trust policy"))
+	_, err = LoadPolicy(dir.Join("invalid.json"))
+	assert.Check(t, is.ErrorContains(err, "invalid content trust policy"))
+	_, err = LoadPolicy(dir.Join("no-repository.json"))
+	assert.Check(t, is.ErrorContains(err, "rule without repository"))
+	_, err = LoadPolicy(dir.Join("insecure-signers.json"))
+	assert.Check(t, is.ErrorContains(err, "can't both allow insecure content and require signers"))
+}
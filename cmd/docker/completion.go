@@ -0,0 +1,60 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	pluginmanager "github.com/docker/cli/cli-plugins/manager"
+	"github.com/docker/cli/cli/command"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// newCompletionCommand returns the hidden command called by the shell
+// completion scripts to complete the command line of CLI plugins, which they
+// know nothing about. The first argument is the plugin name, the last one is
+// the word being completed. The plugin names are completed when the plugin
+// name is the word being completed.
+func newCompletionCommand(dockerCli command.Cli) *cobra.Command {
+	return &cobra.Command{
+		Use:    pluginmanager.CompletionSubcommandName + " [PLUGIN [ARG...]] TOCOMPLETE",
+		Hidden: true,
+		// Flags being completed are arguments of this command.
+		DisableFlagParsing: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := completePlugin(dockerCli, cmd.Root(), args)
+			if err != nil {
+				logrus.Debugf("failed to complete %q: %v", args, err)
+				c = pluginmanager.Completion{Directive: pluginmanager.CompletionDirectiveError}
+			}
+			return pluginmanager.WriteCompletion(dockerCli.Out(), c)
+		},
+	}
+}
+
+func completePlugin(dockerCli command.Cli, rootcmd *cobra.Command, args []string) (pluginmanager.Completion, error) {
+	switch len(args) {
+	case 0:
+		return pluginmanager.Completion{Directive: pluginmanager.CompletionDirectiveError}, nil
+	case 1:
+		plugins, err := pluginmanager.ListPlugins(dockerCli, rootcmd)
+		if err != nil {
+			return pluginmanager.Completion{}, err
+		}
+		sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+		c := pluginmanager.Completion{Directive: pluginmanager.CompletionDirectiveNoFileComp}
+		for _, p := range plugins {
+			if p.Err != nil || !strings.HasPrefix(p.Name, args[0]) {
+				continue
+			}
+			value := p.Name
+			if p.ShortDescription != "" {
+				value += "\t" + p.ShortDescription
+			}
+			c.Values = append(c.Values, value)
+		}
+		return c, nil
+	default:
+		return pluginmanager.PluginComplete(dockerCli, args[0], args[1:], rootcmd)
+	}
+}
@@ -21,7 +21,7 @@ import (
 	"github.com/spf13/pflag"
 )
 
-func newDockerCommand(dockerCli *command.DockerCli) *cobra.Command {
+func newDockerCommand(dockerCli *command.DockerCli, args []string) *cobra.Command {
 	var (
 		opts    *cliflags.ClientOptions
 		flags   *pflag.FlagSet
@@ -74,9 +74,12 @@ func newDockerCommand(dockerCli *command.DockerCli) *cobra.Command {
 			return nil
 		},
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-			// flags must be the top-level command flags, not cmd.Flags()
-			opts.Common.SetDefaultOptions(flags)
-			if err := dockerCli.Initialize(opts); err != nil {
+			names, err := multiContextNames(dockerCli, flags, opts)
+			if err != nil || names != nil {
+				// the command is initialized for each context by runOnContexts
+				return err
+			}
+			if err := initializeDockerCli(dockerCli, flags, opts); err != nil {
 				return err
 			}
 			return isSupported(cmd, dockerCli)
@@ -93,10 +96,13 @@ func newDockerCommand(dockerCli *command.DockerCli) *cobra.Command {
 	setHelpFunc(dockerCli, cmd, flags, opts)
 
 	cmd.SetOutput(dockerCli.Out())
+	cmd.SetArgs(args)
 	commands.AddCommands(cmd, dockerCli)
+	cmd.AddCommand(newCompletionCommand(dockerCli))
 
 	cli.DisableFlagsInUseLine(cmd)
 	setValidateArgs(dockerCli, cmd, flags, opts)
+	setRunOnContexts(dockerCli, cmd, flags, opts, args)
 
 	return cmd
 }
@@ -223,6 +229,13 @@ func setValidateArgs(dockerCli *command.DockerCli, cmd *cobra.Command, flags *pf
 
 		cmdArgs := ccmd.Args
 		ccmd.Args = func(cmd *cobra.Command, args []string) error {
+			if names, err := multiContextNames(dockerCli, flags, opts); err != nil || names != nil {
+				// support is checked against each context by runOnContexts
+				if err != nil {
+					return err
+				}
+				return cmdArgs(cmd, args)
+			}
 			if err := initializeDockerCli(dockerCli, flags, opts); err != nil {
 				return err
 			}
@@ -234,6 +247,32 @@ func setValidateArgs(dockerCli *command.DockerCli, cmd *cobra.Command, flags *pf
 	})
 }
 
+func setRunOnContexts(dockerCli *command.DockerCli, cmd *cobra.Command, flags *pflag.FlagSet, opts *cliflags.ClientOptions, args []string) {
+	// When the --context flag selects several contexts, the command is run
+	// with the same arguments against each of them instead.
+	cli.VisitAll(cmd, func(ccmd *cobra.Command) {
+		if ccmd.RunE == nil {
+			return
+		}
+		runE := ccmd.RunE
+		ccmd.RunE = func(cmd *cobra.Command, args []string) error {
+			names, err := multiContextNames(dockerCli, flags, opts)
+			if err != nil {
+				return err
+			}
+			if names != nil {
+				// the root command runs the CLI plugins, which are run with
+				// the arguments of the process and its streams
+				if !cmd.HasParent() && len(args) > 0 {
+					return fmt.Errorf("docker: '%s' is not a builtin command, CLI plugins can't be run against several contexts", args[0])
+				}
+				return runOnContexts(dockerCli, opts, names, args)
+			}
+			return runE(cmd, args)
+		}
+	})
+}
+
 func initializeDockerCli(dockerCli *command.DockerCli, flags *pflag.FlagSet, opts *cliflags.ClientOptions) error {
 	if dockerCli.Client() != nil {
 		return nil
@@ -252,23 +291,43 @@ func main() {
 	}
 	logrus.SetOutput(dockerCli.Err())
 
-	cmd := newDockerCommand(dockerCli)
-
-	if err := cmd.Execute(); err != nil {
+	cmd, ccmd, err := runDocker(dockerCli, os.Args[1:])
+	if err != nil {
 		if sterr, ok := err.(cli.StatusError); ok {
 			if sterr.Status != "" {
 				fmt.Fprintln(dockerCli.Err(), sterr.Status)
 			}
-			// StatusError should only be used for errors, and all errors should
-			// have a non-zero exit status, so never exit with 0
-			if sterr.StatusCode == 0 {
-				os.Exit(1)
-			}
-			os.Exit(sterr.StatusCode)
+		} else {
+			fmt.Fprintln(dockerCli.Err(), err)
 		}
-		fmt.Fprintln(dockerCli.Err(), err)
-		os.Exit(1)
 	}
+	status := exitStatus(err)
+	pluginmanager.RunHooks(dockerCli, cmd, ccmd, status)
+	if status != 0 {
+		os.Exit(status)
+	}
+}
+
+// runDocker runs the docker command with the given arguments, and returns the
+// root command and the command which was executed.
+func runDocker(dockerCli *command.DockerCli, args []string) (*cobra.Command, *cobra.Command, error) {
+	cmd := newDockerCommand(dockerCli, args)
+	ccmd, err := cmd.ExecuteC()
+	return cmd, ccmd, err
+}
+
+// exitStatus returns the exit status of the CLI for the error returned by
+// the command which was executed.
+func exitStatus(err error) int {
+	if err == nil {
+		return 0
+	}
+	// StatusError should only be used for errors, and all errors should
+	// have a non-zero exit status, so never exit with 0
+	if sterr, ok := err.(cli.StatusError); ok && sterr.StatusCode != 0 {
+		return sterr.StatusCode
+	}
+	return 1
 }
 
 type versionDetails interface {
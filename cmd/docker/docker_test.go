@@ -16,7 +16,7 @@ import (
 func TestClientDebugEnabled(t *testing.T) {
 	defer debug.Disable()
 
-	cmd := newDockerCommand(&command.DockerCli{})
+	cmd := newDockerCommand(&command.DockerCli{}, nil)
 	cmd.Flags().Set("debug", "true")
 
 	err := cmd.PersistentPreRunE(cmd, []string{})
@@ -30,8 +30,7 @@ var discard = ioutil.NopCloser(bytes.NewBuffer(nil))
 func TestExitStatusForInvalidSubcommandWithHelpFlag(t *testing.T) {
 	cli, err := command.NewDockerCli(command.WithInputStream(discard), command.WithCombinedStreams(ioutil.Discard))
 	assert.NilError(t, err)
-	cmd := newDockerCommand(cli)
-	cmd.SetArgs([]string{"help", "invalid"})
+	cmd := newDockerCommand(cli, []string{"help", "invalid"})
 	err = cmd.Execute()
 	assert.Error(t, err, "unknown help topic: invalid")
 }
@@ -39,8 +38,7 @@ func TestExitStatusForInvalidSubcommandWithHelpFlag(t *testing.T) {
 func TestExitStatusForInvalidSubcommand(t *testing.T) {
 	cli, err := command.NewDockerCli(command.WithInputStream(discard), command.WithCombinedStreams(ioutil.Discard))
 	assert.NilError(t, err)
-	cmd := newDockerCommand(cli)
-	cmd.SetArgs([]string{"invalid"})
+	cmd := newDockerCommand(cli, []string{"invalid"})
 	err = cmd.Execute()
 	assert.Check(t, is.ErrorContains(err, "docker: 'invalid' is not a docker command."))
 }
@@ -49,8 +47,7 @@ func TestVersion(t *testing.T) {
 	var b bytes.Buffer
 	cli, err := command.NewDockerCli(command.WithInputStream(discard), command.WithCombinedStreams(&b))
 	assert.NilError(t, err)
-	cmd := newDockerCommand(cli)
-	cmd.SetArgs([]string{"--version"})
+	cmd := newDockerCommand(cli, []string{"--version"})
 	err = cmd.Execute()
 	assert.NilError(t, err)
 	assert.Check(t, is.Contains(b.String(), "Docker version"))
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	cliconfig "github.com/docker/cli/cli/config"
+	"github.com/docker/cli/cli/context/store"
+	cliflags "github.com/docker/cli/cli/flags"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// allContexts is the value of the --context flag selecting all the contexts
+const allContexts = "*"
+
+// multiContextNames returns the names of the contexts to run the command
+// against, if the --context flag selects several contexts. It returns nil
+// once the CLI is initialized, as the CLI of each context is.
+func multiContextNames(dockerCli *command.DockerCli, flags *pflag.FlagSet, opts *cliflags.ClientOptions) ([]string, error) {
+	if dockerCli.Client() != nil {
+		return nil, nil
+	}
+	// flags must be the top-level command flags, not cmd.Flags()
+	opts.Common.SetDefaultOptions(flags)
+	return contextNames(opts)
+}
+
+// contextNames returns the names of the contexts to run the command against,
+// if the --context flag or the DOCKER_CONTEXT environment variable selects
+// several contexts, either as a comma-separated list, or as "*" for all the
+// contexts in the store. It returns nil if they select a single context.
+func contextNames(opts *cliflags.ClientOptions) ([]string, error) {
+	value, _, err := command.ContextNameFromOptions(opts.Common)
+	if err != nil {
+		return nil, err
+	}
+	if value != allContexts && !strings.Contains(value, ",") {
+		return nil, nil
+	}
+	if value == allContexts {
+		if opts.ConfigDir != "" {
+			cliconfig.SetDir(opts.ConfigDir)
+		}
+		s := store.New(cliconfig.ContextStoreDir(), store.NewConfig(func() interface{} { return &command.DockerContext{} }))
+		contexts, err := s.ListContexts()
+		if err != nil {
+			return nil, err
+		}
+		if len(contexts) == 0 {
+			return nil, errors.New("no contexts to run the command against")
+		}
+		names := make([]string, 0, len(contexts))
+		for _, c := range contexts {
+			names = append(names, c.Name)
+		}
+		sort.Strings(names)
+		return names, nil
+	}
+	var names []string
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, errors.Errorf("invalid context list %q: context names must not be empty", value)
+		}
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// runOnContexts runs the command with the given arguments concurrently
+// against each context. Each line of output is prefixed with the name of the
+// context it comes from. The returned error carries the highest exit status
+// of the commands, if any failed.
+func runOnContexts(dockerCli command.Cli, opts *cliflags.ClientOptions, names []string, args []string) error {
+	width := 0
+	for _, name := range names {
+		if len(name) > width {
+			width = len(name)
+		}
+	}
+	// the configuration directory is global, so it is set once for all the
+	// contexts
+	if opts.ConfigDir != "" {
+		cliconfig.SetDir(opts.ConfigDir)
+	}
+
+	var mu sync.Mutex
+	runs := make([]*contextRun, len(names))
+	for i, name := range names {
+		// building the commands registers global template functions, so it
+		// isn't done concurrently
+		prefix := fmt.Sprintf("%-*s | ", width, name)
+		runs[i] = newContextRun(opts, name, args, newPrefixWriter(dockerCli.Out(), prefix, &mu), newPrefixWriter(dockerCli.Err(), prefix, &mu))
+	}
+
+	var wg sync.WaitGroup
+	for _, run := range runs {
+		wg.Add(1)
+		go func(run *contextRun) {
+			defer wg.Done()
+			run.run()
+		}(run)
+	}
+	wg.Wait()
+
+	var (
+		failed []string
+		status int
+	)
+	for i, run := range runs {
+		if run.status == 0 {
+			continue
+		}
+		failed = append(failed, names[i])
+		if run.status > status {
+			status = run.status
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return cli.StatusError{
+		Status:     fmt.Sprintf("Failed on %d of %d contexts: %s", len(failed), len(names), strings.Join(failed, ", ")),
+		StatusCode: status,
+	}
+}
+
+// contextRun is the run of the command against a single context.
+type contextRun struct {
+	opts        cliflags.ClientOptions
+	out, errOut *prefixWriter
+	dockerCli   *command.DockerCli
+	cmd         *cobra.Command
+	status      int
+	err         error
+}
+
+func newContextRun(opts *cliflags.ClientOptions, name string, args []string, out, errOut *prefixWriter) *contextRun {
+	run := &contextRun{opts: *opts, out: out, errOut: errOut}
+	common := *opts.Common
+	common.Context = name
+	run.opts.Common = &common
+	run.opts.ConfigDir = ""
+
+	run.dockerCli, run.err = command.NewDockerCli(
+		command.WithInputStream(ioutil.NopCloser(bytes.NewReader(nil))),
+		command.WithOutputStream(out),
+		command.WithErrorStream(errOut),
+	)
+	if run.err == nil {
+		run.cmd = newDockerCommand(run.dockerCli, args)
+	}
+	return run
+}
+
+// run initializes the CLI for the context, and runs the command.
+func (r *contextRun) run() {
+	err := r.err
+	if err == nil {
+		err = r.dockerCli.Initialize(&r.opts)
+	}
+	if err == nil {
+		_, err = r.cmd.ExecuteC()
+	}
+	if err != nil {
+		if sterr, ok := err.(cli.StatusError); ok {
+			if sterr.Status != "" {
+				fmt.Fprintln(r.errOut, sterr.Status)
+			}
+		} else {
+			fmt.Fprintln(r.errOut, err)
+		}
+	}
+	r.status = exitStatus(err)
+	r.out.Flush()
+	r.errOut.Flush()
+}
+
+// prefixWriter writes each complete line to the underlying writer with a
+// prefix. The mutex is shared by the writers of all the contexts, so that
+// their lines are not interleaved.
+type prefixWriter struct {
+	w      io.Writer
+	prefix string
+	mu     *sync.Mutex
+	buf    bytes.Buffer
+}
+
+func newPrefixWriter(w io.Writer, prefix string, mu *sync.Mutex) *prefixWriter {
+	return &prefixWriter{w: w, prefix: prefix, mu: mu}
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.buf.Write(b)
+	for {
+		i := bytes.IndexByte(p.buf.Bytes(), '\n')
+		if i < 0 {
+			return len(b), nil
+		}
+		if err := p.writeLine(p.buf.Next(i + 1)); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// Flush writes the last line, if it doesn't end with a newline.
+func (p *prefixWriter) Flush() error {
+	if p.buf.Len() == 0 {
+		return nil
+	}
+	line := append(p.buf.Next(p.buf.Len()), '\n')
+	return p.writeLine(line)
+}
+
+func (p *prefixWriter) writeLine(line []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err := io.WriteString(p.w, p.prefix+string(line))
+	return err
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/docker/cli/cli"
+	"github.com/docker/cli/cli/command"
+	cliconfig "github.com/docker/cli/cli/config"
+	"github.com/docker/cli/cli/context/docker"
+	"github.com/docker/cli/cli/context/store"
+	cliflags "github.com/docker/cli/cli/flags"
+	"gotest.tools/assert"
+	is "gotest.tools/assert/cmp"
+	"gotest.tools/env"
+	"gotest.tools/fs"
+)
+
+func newTestContexts(t *testing.T, dir *fs.Dir, names ...string) {
+	t.Helper()
+	s := store.New(dir.Join("contexts"), store.NewConfig(
+		func() interface{} { return &command.DockerContext{} },
+		store.EndpointTypeGetter(docker.DockerEndpoint, func() interface{} { return &docker.EndpointMeta{} }),
+	))
+	for _, name := range names {
+		assert.NilError(t, s.CreateOrUpdateContext(store.ContextMetadata{
+			Name:     name,
+			Metadata: command.DockerContext{},
+			Endpoints: map[string]interface{}{
+				docker.DockerEndpoint: docker.EndpointMeta{Host: "unix:///var/run/" + name + ".sock"},
+			},
+		}))
+	}
+}
+
+func TestContextNames(t *testing.T) {
+	dir := fs.NewDir(t, t.Name())
+	defer dir.Remove()
+	newTestContexts(t, dir, "ctx-b", "ctx-a")
+
+	testCases := []struct {
+		name          string
+		context       string
+		hosts         []string
+		env           map[string]string
+		expected      []string
+		expectedError string
+	}{
+		{name: "none"},
+		{name: "single", context: "ctx-a"},
+		{name: "list", context: "ctx-b, ctx-a,ctx-b", expected: []string{"ctx-b", "ctx-a"}},
+		{name: "all", context: "*", expected: []string{"ctx-a", "ctx-b"}},
+		{name: "empty-name", context: "ctx-a,", expectedError: `invalid context list "ctx-a,": context names must not be empty`},
+		{name: "hosts", context: "ctx-a,ctx-b", hosts: []string{"unix:///var/run/docker.sock"}, expectedError: "Conflicting options: either specify --host or --context, not both"},
+		{name: "env-list", env: map[string]string{"DOCKER_CONTEXT": "ctx-a,ctx-b"}, expected: []string{"ctx-a", "ctx-b"}},
+		{name: "env-all", env: map[string]string{"DOCKER_CONTEXT": "*"}, expected: []string{"ctx-a", "ctx-b"}},
+		{name: "flag-over-env", context: "ctx-a", env: map[string]string{"DOCKER_CONTEXT": "ctx-a,ctx-b"}},
+		{name: "host-over-env", env: map[string]string{"DOCKER_HOST": "unix:///var/run/docker.sock", "DOCKER_CONTEXT": "ctx-a,ctx-b"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer env.PatchAll(t, tc.env)()
+			opts := cliflags.NewClientOptions()
+			opts.ConfigDir = dir.Path()
+			opts.Common.Context = tc.context
+			opts.Common.Hosts = tc.hosts
+			names, err := contextNames(opts)
+			if tc.expectedError != "" {
+				assert.Error(t, err, tc.expectedError)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Check(t, is.DeepEqual(tc.expected, names))
+		})
+	}
+}
+
+func TestRunOnContexts(t *testing.T) {
+	dir := fs.NewDir(t, t.Name())
+	defer dir.Remove()
+	defer cliconfig.SetDir(cliconfig.Dir())
+	newTestContexts(t, dir, "ctx-a", "ctx-b", "ctx-long")
+
+	var out, errOut bytes.Buffer
+	dockerCli, err := command.NewDockerCli(command.WithInputStream(discard), command.WithOutputStream(&out), command.WithErrorStream(&errOut))
+	assert.NilError(t, err)
+	opts := cliflags.NewClientOptions()
+	opts.ConfigDir = dir.Path()
+
+	names := []string{"ctx-a", "ctx-long", "ctx-b"}
+	err = runOnContexts(dockerCli, opts, names, []string{"--config", dir.Path(), "--context", "ctx-a,ctx-long,ctx-b", "context", "inspect", "--format", "{{.Name}}"})
+	assert.NilError(t, err)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Check(t, is.Len(lines, 3))
+	assert.Check(t, is.Contains(lines, "ctx-a    | ctx-a"))
+	assert.Check(t, is.Contains(lines, "ctx-long | ctx-long"))
+	assert.Check(t, is.Contains(lines, "ctx-b    | ctx-b"))
+	assert.Check(t, is.Equal("", errOut.String()))
+
+	out.Reset()
+	names = []string{"ctx-a", "missing"}
+	err = runOnContexts(dockerCli, opts, names, []string{"context", "inspect", "--format", "{{.Name}}"})
+	assert.Check(t, is.DeepEqual(cli.StatusError{Status: "Failed on 1 of 2 contexts: missing", StatusCode: 1}, err))
+	assert.Check(t, is.Equal("ctx-a   | ctx-a\n", out.String()))
+	assert.Check(t, is.Contains(errOut.String(), "missing | unable to resolve docker endpoint"))
+}
+
+func TestRunOnContextsPluginCommand(t *testing.T) {
+	dir := fs.NewDir(t, t.Name())
+	defer dir.Remove()
+	defer cliconfig.SetDir(cliconfig.Dir())
+	newTestContexts(t, dir, "ctx-a", "ctx-b")
+
+	dockerCli, err := command.NewDockerCli(command.WithInputStream(discard), command.WithCombinedStreams(ioutil.Discard))
+	assert.NilError(t, err)
+	_, _, err = runDocker(dockerCli, []string{"--config", dir.Path(), "--context", "ctx-a,ctx-b", "helloworld", "--who", "world"})
+	assert.Check(t, is.Error(err, "docker: 'helloworld' is not a builtin command, CLI plugins can't be run against several contexts"))
+}
+
+func TestPrefixWriter(t *testing.T) {
+	var (
+		b  bytes.Buffer
+		mu sync.Mutex
+	)
+	w := newPrefixWriter(&b, "ctx | ", &mu)
+	_, err := w.Write([]byte("first line\nsecond "))
+	assert.NilError(t, err)
+	assert.Check(t, is.Equal("ctx | first line\n", b.String()))
+	_, err = w.Write([]byte("line\nlast line"))
+	assert.NilError(t, err)
+	assert.NilError(t, w.Flush())
+	assert.Check(t, is.Equal("ctx | first line\nctx | second line\nctx | last line\n", b.String()))
+}
@@ -76,6 +76,42 @@ function __fish_print_docker_repositories --description 'Print a list of docker
     docker images --format "{{.Repository}}" | command grep -v '<none>' | command sort | command uniq
 end
 
+function __fish_print_docker_cli_plugins --description 'Print the commands provided by CLI plugins'
+    docker __complete (commandline -ct) 2>/dev/null | string match -v ':*'
+end
+
+function __fish_docker_cli_plugin_position --description 'Print the position of the CLI plugin command on the command line'
+    set -l cmd (commandline -opc)
+    set -l plugins (docker __complete '' 2>/dev/null | string match -v ':*' | string replace -r '\t.*' '')
+    for i in (seq 2 (count $cmd))
+        if contains -- $cmd[$i] $plugins
+            echo $i
+            return 0
+        end
+    end
+    return 1
+end
+
+function __fish_docker_complete_cli_plugin --description 'Delegate the completion of the command line of a CLI plugin to the plugin'
+    set -l cmd (commandline -opc)
+    set -l pos (__fish_docker_cli_plugin_position); or return
+    set -l globals
+    if test $pos -gt 2
+        set globals $cmd[2..(math $pos - 1)]
+    end
+    set -l output (docker $globals __complete $cmd[$pos..-1] (commandline -ct) 2>/dev/null)
+    # The last line holds the directives: 1 for an error, 4 for no file name completion
+    set -l directive (string replace -r '^:' '' -- $output[-1]); or return
+    if test (math "$directive % 2") -eq 1
+        return
+    end
+    if test (count $output) -gt 1
+        printf '%s\n' $output[1..-2]
+    else if test (math "floor($directive / 4) % 2") -eq 0
+        __fish_complete_path (commandline -ct)
+    end
+end
+
 # common options
 complete -c docker -f -n '__fish_docker_no_subcommand' -l api-cors-header -d "Set CORS headers in the Engine API. Default is cors disabled"
 complete -c docker -f -n '__fish_docker_no_subcommand' -s b -l bridge -d 'Attach containers to a pre-existing network bridge'
@@ -562,3 +598,7 @@ complete -c docker -A -f -n '__fish_seen_subcommand_from version' -l help -d 'Pr
 complete -c docker -f -n '__fish_docker_no_subcommand' -a wait -d 'Block until a container stops, then print its exit code'
 complete -c docker -A -f -n '__fish_seen_subcommand_from wait' -l help -d 'Print usage'
 complete -c docker -A -f -n '__fish_seen_subcommand_from wait' -a '(__fish_print_docker_containers running)' -d "Container"
+
+# cli plugins
+complete -c docker -f -n '__fish_docker_no_subcommand' -a '(__fish_print_docker_cli_plugins)'
+complete -c docker -f -n '__fish_docker_cli_plugin_position >/dev/null' -a '(__fish_docker_complete_cli_plugin)'
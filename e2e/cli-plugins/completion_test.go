@@ -0,0 +1,59 @@
+package cliplugins
+
+import (
+	"testing"
+
+	"gotest.tools/icmd"
+)
+
+// TestCompletePluginNames ensures that the plugin names are completed.
+func TestCompletePluginNames(t *testing.T) {
+	run, _, cleanup := prepare(t)
+	defer cleanup()
+
+	res := icmd.RunCmd(run("__complete", "hello"))
+	res.Assert(t, icmd.Expected{
+		ExitCode: 0,
+		Out:      "helloworld\tA basic Hello World plugin for tests\n:4\n",
+	})
+}
+
+// TestCompletePluginSubcommands ensures that the completion is delegated to the plugin.
+func TestCompletePluginSubcommands(t *testing.T) {
+	run, _, cleanup := prepare(t)
+	defer cleanup()
+
+	res := icmd.RunCmd(run("__complete", "helloworld", ""))
+	res.Assert(t, icmd.Expected{
+		ExitCode: 0,
+		Out: `apiversion	Print the API version of the server
+exitstatus2	Exit with status 2
+goodbye	Say Goodbye instead of Hello
+:4
+`,
+	})
+}
+
+// TestCompletePluginFlagValue ensures that the values of the flags of a plugin are completed.
+func TestCompletePluginFlagValue(t *testing.T) {
+	run, _, cleanup := prepare(t)
+	defer cleanup()
+
+	res := icmd.RunCmd(run("__complete", "helloworld", "--who", ""))
+	res.Assert(t, icmd.Expected{
+		ExitCode: 0,
+		Out:      "World\nMoon\n:4\n",
+	})
+}
+
+// TestCompleteNonexisting ensures that completing a nonexistent plugin reports an error to the shell.
+func TestCompleteNonexisting(t *testing.T) {
+	run, _, cleanup := prepare(t)
+	defer cleanup()
+
+	res := icmd.RunCmd(run("__complete", "nonexistent", ""))
+	res.Assert(t, icmd.Expected{
+		ExitCode: 0,
+		Out:      ":1\n",
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/docker/cli/cli-plugins/manager"
 	"gotest.tools/assert"
 	is "gotest.tools/assert/cmp"
+	"gotest.tools/fs"
 	"gotest.tools/icmd"
 )
 
@@ -17,10 +18,14 @@ func TestDialStdio(t *testing.T) {
 	// before the `helloworld` arg, but not the --who=foo which
 	// follows. We observe this from the debug level logging from
 	// the connhelper stuff.
+	// The plugin saves the last --who to its config file, which must not be
+	// written to the source tree.
+	cfg := fs.NewDir(t, "plugin-test")
+	defer cfg.Remove()
 	helloworld := filepath.Join(os.Getenv("DOCKER_CLI_E2E_PLUGINS_EXTRA_DIRS"), "docker-helloworld")
-	cmd := icmd.Command(helloworld, "--config=blah", "--tls", "--log-level", "debug", "helloworld", "--who=foo")
+	cmd := icmd.Command(helloworld, "--config="+cfg.Path(), "--tls", "--log-level", "debug", "helloworld", "--who=foo")
 	res := icmd.RunCmd(cmd, icmd.WithEnv(manager.ReexecEnvvar+"=/bin/true"))
 	res.Assert(t, icmd.Success)
-	assert.Assert(t, is.Contains(res.Stderr(), `msg="commandconn: starting /bin/true with [--config=blah --tls --log-level debug system dial-stdio]"`))
+	assert.Assert(t, is.Contains(res.Stderr(), `msg="commandconn: starting /bin/true with [--config=`+cfg.Path()+` --tls --log-level debug system dial-stdio]"`))
 	assert.Assert(t, is.Equal(res.Stdout(), "Hello foo!\n"))
 }
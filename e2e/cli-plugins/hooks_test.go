@@ -0,0 +1,37 @@
+package cliplugins
+
+import (
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+	"gotest.tools/icmd"
+)
+
+const loginHookOutput = `Hello from the login hook: "login" exited with status 1`
+
+// TestHookAfterBuiltinCommand ensures that a plugin hooking a builtin command is invoked after it.
+func TestHookAfterBuiltinCommand(t *testing.T) {
+	run, _, cleanup := prepare(t)
+	defer cleanup()
+
+	res := icmd.RunCmd(run("login", "--username", "foo", "--password-stdin", "localhost:1"), icmd.WithStdin(nil))
+	res.Assert(t, icmd.Expected{
+		ExitCode: 1,
+		Err:      loginHookOutput,
+	})
+}
+
+// TestHooksDisabled ensures that plugin hooks are not invoked when disabled in the configuration.
+func TestHooksDisabled(t *testing.T) {
+	run, cfg, cleanup := prepare(t)
+	defer cleanup()
+
+	disabled := false
+	cfg.CLIPluginsHooks = &disabled
+	assert.NilError(t, cfg.Save())
+
+	res := icmd.RunCmd(run("login", "--username", "foo", "--password-stdin", "localhost:1"), icmd.WithStdin(nil))
+	res.Assert(t, icmd.Expected{ExitCode: 1})
+	assert.Assert(t, !strings.Contains(res.Stderr(), loginHookOutput))
+}
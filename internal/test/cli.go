@@ -24,6 +24,7 @@ import (
 type NotaryClientFuncType func(imgRefAndAuth trust.ImageRefAndAuth, actions []string) (notaryclient.Repository, error)
 type clientInfoFuncType func() command.ClientInfo
 type containerizedEngineFuncType func(string) (clitypes.ContainerizedClient, error)
+type registryClientFuncType func(bool) registryclient.RegistryClient
 
 // FakeCli emulates the default DockerCli
 type FakeCli struct {
@@ -39,10 +40,12 @@ type FakeCli struct {
 	notaryClientFunc              NotaryClientFuncType
 	manifestStore                 manifeststore.Store
 	registryClient                registryclient.RegistryClient
+	registryClientFunc            registryClientFuncType
 	contentTrust                  bool
 	containerizedEngineClientFunc containerizedEngineFuncType
 	contextStore                  store.Store
 	currentContext                string
+	currentContextSource          string
 	dockerEndpoint                docker.Endpoint
 }
 
@@ -96,6 +99,11 @@ func (c *FakeCli) SetCurrentContext(name string) {
 	c.currentContext = name
 }
 
+// SetCurrentContextSource sets what selected the "fake" current context
+func (c *FakeCli) SetCurrentContextSource(source string) {
+	c.currentContextSource = source
+}
+
 // SetDockerEndpoint sets the "fake" docker endpoint
 func (c *FakeCli) SetDockerEndpoint(ep docker.Endpoint) {
 	c.dockerEndpoint = ep
@@ -136,6 +144,11 @@ func (c *FakeCli) CurrentContext() string {
 	return c.currentContext
 }
 
+// CurrentContextSource returns what selected the cli context
+func (c *FakeCli) CurrentContextSource() string {
+	return c.currentContextSource
+}
+
 // DockerEndpoint returns the current DockerEndpoint
 func (c *FakeCli) DockerEndpoint() docker.Endpoint {
 	return c.dockerEndpoint
@@ -154,6 +167,11 @@ func (c *FakeCli) ClientInfo() command.ClientInfo {
 	return c.DockerCli.ClientInfo()
 }
 
+// SetServerInfo sets the API server information
+func (c *FakeCli) SetServerInfo(serverInfo command.ServerInfo) {
+	c.server = serverInfo
+}
+
 // SetClientInfo sets the internal getter for retrieving a ClientInfo
 func (c *FakeCli) SetClientInfo(clientInfoFunc clientInfoFuncType) {
 	c.clientInfoFunc = clientInfoFunc
@@ -189,6 +207,9 @@ func (c *FakeCli) ManifestStore() manifeststore.Store {
 
 // RegistryClient returns a fake client for testing
 func (c *FakeCli) RegistryClient(insecure bool) registryclient.RegistryClient {
+	if c.registryClientFunc != nil {
+		return c.registryClientFunc(insecure)
+	}
 	return c.registryClient
 }
 
@@ -202,6 +223,12 @@ func (c *FakeCli) SetRegistryClient(client registryclient.RegistryClient) {
 	c.registryClient = client
 }
 
+// SetRegistryClientFunc sets the function returning the registry client of
+// the fake cli, for an insecure registry or not
+func (c *FakeCli) SetRegistryClientFunc(registryClientFunc registryClientFuncType) {
+	c.registryClientFunc = registryClientFunc
+}
+
 // ContentTrustEnabled on the fake cli
 func (c *FakeCli) ContentTrustEnabled() bool {
 	return c.contentTrust
@@ -541,3 +541,31 @@ func (l LoadedWithNoSignersNotaryRepository) GetAllTargetMetadataByName(name str
 func (l LoadedWithNoSignersNotaryRepository) GetDelegationRoles() ([]data.Role, error) {
 	return []data.Role{}, nil
 }
+
+// NewRecordingNotaryRepository returns a RecordingNotaryRepository which
+// records the added targets in targets
+func NewRecordingNotaryRepository(targets *[]client.Target) RecordingNotaryRepository {
+	return RecordingNotaryRepository{
+		targets:       targets,
+		cryptoService: cryptoservice.NewCryptoService(trustmanager.NewKeyMemoryStore(passphrase.ConstantRetriever("password"))),
+	}
+}
+
+// RecordingNotaryRepository is a mock Notary repository that is initialized
+// without any signed targets, and records the targets added to it
+type RecordingNotaryRepository struct {
+	EmptyTargetsNotaryRepository
+	targets       *[]client.Target
+	cryptoService signed.CryptoService
+}
+
+// AddTarget records the target
+func (r RecordingNotaryRepository) AddTarget(target *client.Target, roles ...data.RoleName) error {
+	*r.targets = append(*r.targets, *target)
+	return nil
+}
+
+// GetCryptoService is the getter for the repository's CryptoService
+func (r RecordingNotaryRepository) GetCryptoService() signed.CryptoService {
+	return r.cryptoService
+}